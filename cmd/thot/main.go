@@ -3,10 +3,20 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/integrii/flaggy"
 	"github.com/wrodriguez/thot/internal/command"
+	"github.com/wrodriguez/thot/internal/config"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/keymap"
+	"github.com/wrodriguez/thot/internal/paths"
+	"github.com/wrodriguez/thot/internal/race"
+	"github.com/wrodriguez/thot/internal/theme"
+	"github.com/wrodriguez/thot/internal/web"
+	"gitlab.com/tozd/go/errors"
 )
 
 var Version = "devel"
@@ -25,29 +35,235 @@ var listCommand *flaggy.Subcommand
 var printCommand *flaggy.Subcommand
 var trainCommand *flaggy.Subcommand
 var dbCommand *flaggy.Subcommand
+var dbStatusCommand *flaggy.Subcommand
+var replayCommand *flaggy.Subcommand
+var serveCommand *flaggy.Subcommand
+var raceCommand *flaggy.Subcommand
+var raceHostCommand *flaggy.Subcommand
+var raceJoinCommand *flaggy.Subcommand
+var configCommand *flaggy.Subcommand
+var configGetCommand *flaggy.Subcommand
+var configSetCommand *flaggy.Subcommand
+var configPathCommand *flaggy.Subcommand
+var todayCommand *flaggy.Subcommand
+var achievementsCommand *flaggy.Subcommand
+var profileCommand *flaggy.Subcommand
+var profileListCommand *flaggy.Subcommand
+var profileCreateCommand *flaggy.Subcommand
+var profileDeleteCommand *flaggy.Subcommand
+var layoutCommand *flaggy.Subcommand
+var layoutEditCommand *flaggy.Subcommand
+var layoutExportCommand *flaggy.Subcommand
+var layoutImportCommand *flaggy.Subcommand
+var layoutCheckCommand *flaggy.Subcommand
 
 var layoutName string = "qwerty"
 var layout string = "qwerty"
 var lang string = "spa"
-var rows []string = []string{"row3"}
+var rows []string
+var length int
+var mode string
+var source string
+var configKey string
+var configValue string
+var uiLang string
+var profile string = paths.DefaultProfile
+var profileName string
+var yes bool
+var dataDir string
+var seed int64
+var challengeCode string
+var sessionID string
+var speed float64 = 1
+var pace float64
+var raceAddr string = race.DefaultAddr
+var raceName string
+var serveAddr string = web.DefaultAddr
+var ghost bool
+var layoutAs string
+var format string = "xkb"
+var output string
+var board string
+var diagramFormat string
+var layer string
+var importFile string
+var importFormat string
+var importName string
+var layoutType string
+var checkName string
+var checkFile string
 
 var errStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Bold(true)
 
+func init() {
+	theme.Register("main.err", &errStyle)
+}
+
 func main() {
+	// El idioma se necesita antes de configurar los argumentos para traducir la ayuda
+	uiLang = argValue(os.Args[1:], "ui-lang")
+	i18n.Set(i18n.Detect(uiLang))
+	// El directorio de datos y el perfil determinan de donde se leen los archivos, por eso también se
+	// buscan antes de flaggy
+	if dataDir = argValue(os.Args[1:], "data-dir"); dataDir != "" {
+		paths.SetDataDir(dataDir)
+	}
+	if p := argValue(os.Args[1:], "profile"); p != "" {
+		if err := paths.SetProfile(p); err != nil {
+			exitOnError(err)
+		}
+		if !paths.ProfileExists(p) {
+			exitOnError(errors.New(i18n.T("El perfil %q no existe, se puede crear con `thot profile create %s`", p, p)))
+		}
+		profile = p
+	}
+	// `layout check` informa los problemas de los layouts en lugar de terminar, se cargan antes de la
+	// configuración porque el layout configurado puede ser del usuario
+	layoutsErr := kbd.LoadUserLayouts()
+	// Con un valor no válido solo se puede usar `thot config` para corregirlo
+	cfg, cfgErr := config.Load()
+	if cfgErr != nil && !config.IsInvalid(cfgErr) {
+		exitOnError(cfgErr)
+	}
+	i18n.Set(i18n.Detect(uiLang, cfg.UILang))
+	if err := theme.Apply(cfg.Theme); err != nil {
+		exitOnError(err)
+	}
+	layout, lang, length, mode, source = cfg.Layout, cfg.Lang, cfg.Length, cfg.Mode, cfg.Source
+
 	configArgs()
 	flaggy.Parse()
+	if layoutsErr != nil && !layoutCheckCommand.Used {
+		exitOnError(layoutsErr)
+	}
+	if cfgErr != nil && !configCommand.Used {
+		exitOnError(cfgErr)
+	}
 
 	if listCommand != nil && listCommand.Used {
 		command.ListLayouts()
 	} else if printCommand != nil && printCommand.Used {
-		command.PrintLayout(layoutName)
+		if diagramFormat == "" && output == "" {
+			command.PrintLayout(layoutName, layer)
+		} else if err := command.PrintDiagram(layoutName, diagramFormat, output, layer); err != nil {
+			exitOnError(err)
+		}
 	} else if trainCommand != nil && trainCommand.Used {
-		command.Train(layout, lang, rows)
+		if len(rows) == 0 {
+			rows = cfg.Rows
+		}
+		err := command.Train(command.TrainOptions{
+			Layout:    layout,
+			Lang:      lang,
+			Rows:      rows,
+			Length:    length,
+			Mode:      mode,
+			Source:    source,
+			Seed:      seedFlag(trainCommand),
+			Challenge: challengeCode,
+			Pace:      pace,
+			Ghost:     ghost,
+			Goal:      cfg.Goal,
+		})
+		if err != nil {
+			exitOnError(err)
+		}
 	} else if dbCommand != nil && dbCommand.Used {
-		err := command.CopyDB()
+		var err error
+		if dbStatusCommand.Used {
+			err = command.DBStatus()
+		} else {
+			err = command.CopyDB()
+		}
+		if err != nil {
+			exitOnError(err)
+		}
+	} else if raceCommand != nil && raceCommand.Used {
+		if len(rows) == 0 {
+			rows = cfg.Rows
+		}
+		var err error
+		switch {
+		case raceHostCommand.Used:
+			err = command.RaceHost(command.TrainOptions{
+				Layout:    layout,
+				Lang:      lang,
+				Rows:      rows,
+				Length:    length,
+				Source:    source,
+				Seed:      seedFlag(raceHostCommand),
+				Challenge: challengeCode,
+			}, raceAddr, raceName)
+		case raceJoinCommand.Used:
+			err = command.RaceJoin(raceAddr, raceName)
+		default:
+			flaggy.ShowHelp("")
+		}
+		if err != nil {
+			exitOnError(err)
+		}
+	} else if serveCommand != nil && serveCommand.Used {
+		if err := command.Serve(serveAddr); err != nil {
+			exitOnError(err)
+		}
+	} else if replayCommand != nil && replayCommand.Used {
+		if err := command.Replay(sessionID, speed); err != nil {
+			exitOnError(err)
+		}
+	} else if layoutCommand != nil && layoutCommand.Used {
+		var err error
+		switch {
+		case layoutEditCommand.Used:
+			err = command.LayoutEdit(layoutName, layoutAs, lang, layoutType)
+		case layoutExportCommand.Used:
+			err = command.LayoutExport(layoutName, format, board, output)
+		case layoutImportCommand.Used:
+			err = command.LayoutImport(importFile, importFormat, importName, layoutType)
+		case layoutCheckCommand.Used:
+			err = command.LayoutCheck(checkName, checkFile)
+		default:
+			flaggy.ShowHelp("")
+		}
 		if err != nil {
-			fmt.Println(errStyle.Render(err.Error()))
-			os.Exit(1)
+			exitOnError(err)
+		}
+	} else if todayCommand != nil && todayCommand.Used {
+		if err := command.Today(cfg.Goal); err != nil {
+			exitOnError(err)
+		}
+	} else if achievementsCommand != nil && achievementsCommand.Used {
+		if err := command.Achievements(); err != nil {
+			exitOnError(err)
+		}
+	} else if profileCommand != nil && profileCommand.Used {
+		var err error
+		switch {
+		case profileListCommand.Used:
+			err = command.ProfileList()
+		case profileCreateCommand.Used:
+			err = command.ProfileCreate(profileName)
+		case profileDeleteCommand.Used:
+			err = command.ProfileDelete(profileName, yes)
+		default:
+			flaggy.ShowHelp("")
+		}
+		if err != nil {
+			exitOnError(err)
+		}
+	} else if configCommand != nil && configCommand.Used {
+		var err error
+		switch {
+		case configGetCommand.Used:
+			err = command.ConfigGet(cfg, configKey)
+		case configSetCommand.Used:
+			err = command.ConfigSet(cfg, configKey, configValue)
+		case configPathCommand.Used:
+			err = command.ConfigPath()
+		default:
+			flaggy.ShowHelp("")
+		}
+		if err != nil {
+			exitOnError(err)
 		}
 	} else {
 		flaggy.ShowHelp("")
@@ -55,52 +271,354 @@ func main() {
 
 }
 
+func exitOnError(err error) {
+	fmt.Println(errStyle.Render(err.Error()))
+	os.Exit(1)
+}
+
+// argValue busca el valor de la bandera larga `name` en los argumentos antes de que flaggy los procese.
+func argValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == "--"+name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if v, ok := strings.CutPrefix(arg, "--"+name+"="); ok {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// seedFlag devuelve la semilla de `--seed` solo si se indicó en el subcomando `sc`, cualquier valor
+// (incluso 0) es una semilla válida.
+func seedFlag(sc *flaggy.Subcommand) *int64 { // {{{
+	for _, v := range sc.ParsedValues {
+		if name, _, _ := strings.Cut(v.Key, "="); name == "seed" {
+			return &seed
+		}
+	}
+
+	return nil
+} // }}}
+
 func configArgs() { // {{{
 	flaggy.DefaultParser.AdditionalHelpPrepend = Logo
 	flaggy.SetName("thot")
-	flaggy.SetDescription("Un pequeño entrenador de teclado")
+	flaggy.SetDescription(i18n.T("Un pequeño entrenador de teclado"))
 	flaggy.SetVersion(fmt.Sprintf("%s (%s, %s)", Version, CommitHash, BuildTimestamp))
 
+	flaggy.String(&uiLang, "", "ui-lang", i18n.T("El idioma de la interfaz, acepta solo los valores `es` o `en`"))
+	flaggy.String(&profile, "", "profile", i18n.T("El perfil con el que se guarda el historial, progreso y configuración"))
+	flaggy.String(&dataDir, "", "data-dir", i18n.T("El directorio de los diccionarios, tiene prioridad sobre `THOT_HOME` y `XDG_DATA_HOME`"))
+
 	listCommand = flaggy.NewSubcommand("list")
-	listCommand.Description = "Lista los layouts que pueden ser utilizados por Thot"
+	listCommand.Description = i18n.T("Lista los layouts que pueden ser utilizados por Thot")
 	printCommand = flaggy.NewSubcommand("print")
-	printCommand.Description = "Imprime el layout de teclado seleccionado"
+	printCommand.Description = i18n.T("Imprime el layout de teclado seleccionado")
 	printCommand.AddPositionalValue(
 		&layoutName,
 		"layout-name",
 		1,
 		true,
-		"El nombre del layout, se puede consultar la lista de layouts disponibles a través del comando `thot list`",
+		i18n.T("El nombre del layout, se puede consultar la lista de layouts disponibles a través del comando `thot list`"),
+	)
+	printCommand.String(
+		&diagramFormat,
+		"f",
+		"format",
+		i18n.T(
+			"El formato del diagrama, acepta solo los valores %s. Sin este valor se muestra en la terminal",
+			"`"+strings.Join(kbd.DiagramFormats, "`, `")+"`",
+		),
+	)
+	printCommand.String(
+		&output,
+		"o",
+		"output",
+		i18n.T("El archivo o directorio donde se guarda el diagrama, sin este valor se escribe en la salida estándar"),
+	)
+	printCommand.String(
+		&layer,
+		"l",
+		"layer",
+		i18n.T(
+			"La capa de caracteres que se muestra, acepta solo los valores %s. Sin este valor se muestra `base`",
+			"`"+strings.Join(kbd.Layers, "`, `")+"`",
+		),
 	)
 	trainCommand = flaggy.NewSubcommand("train")
-	trainCommand.Description = "Practica con Thot para mejorar el método de mecanografía"
+	trainCommand.Description = i18n.T("Practica con Thot para mejorar el método de mecanografía")
 	trainCommand.AddPositionalValue(
 		&layout,
 		"layout",
 		1,
-		true,
-		"El nombre del layout, se puede consultar la lista de layouts disponibles a través del comando `thot list`",
+		false,
+		i18n.T("El nombre del layout, se puede consultar la lista de layouts disponibles a través del comando `thot list`"),
 	)
 	trainCommand.AddPositionalValue(
 		&lang,
 		"lang",
 		2,
-		true,
-		"El idioma a mostrar las palabras, acepta solo los valores `spa` o `eng`",
+		false,
+		i18n.T("El idioma a mostrar las palabras, acepta solo los valores `spa` o `eng`"),
 	)
 	trainCommand.StringSlice(
 		&rows,
 		"r",
 		"row",
-		"Las filas a mostrar, acepta solo los valores `row1`, `row2`, `row3`, `row4` o `all` para mostrar todas",
+		i18n.T("Las filas a mostrar, acepta solo los valores `row1`, `row2`, `row3`, `row4`, `thumb` o `all` para mostrar todas"),
+	)
+	trainCommand.Int(
+		&length,
+		"l",
+		"length",
+		i18n.T("La longitud de la sesión, cantidad de palabras en el modo `words` o segundos en el modo `time`"),
+	)
+	trainCommand.String(&mode, "m", "mode", i18n.T("El modo de la sesión, acepta solo los valores `words` o `time`"))
+	trainCommand.String(
+		&source,
+		"s",
+		"source",
+		i18n.T("El origen de las palabras: `auto`, `sqlite`, `embedded`, `user` o la ruta a una lista de palabras"),
+	)
+	trainCommand.Int64(
+		&seed,
+		"",
+		"seed",
+		i18n.T("La semilla para elegir las palabras, la misma semilla con los mismos parámetros produce el mismo texto"),
 	)
+	trainCommand.String(
+		&challengeCode,
+		"c",
+		"challenge",
+		i18n.T("Un código de desafío compartido por otra persona, reemplaza el layout, idioma, filas, modo, longitud, origen y semilla"),
+	)
+	trainCommand.Float64(&pace, "", "pace", i18n.T("Compite contra un fantasma que escribe a esta velocidad en WPM"))
+	trainCommand.Bool(&ghost, "g", "ghost", i18n.T("Compite contra la mejor sesión anterior con el mismo desafío"))
 
 	dbCommand = flaggy.NewSubcommand("db")
-	dbCommand.Description = "Crea la base de datos de palabras de Thot"
+	dbCommand.Description = i18n.T("Instala o actualiza la base de datos de palabras de Thot")
+	dbStatusCommand = flaggy.NewSubcommand("status")
+	dbStatusCommand.Description = i18n.T("Muestra la versión y las tablas de la base de datos de palabras")
+	dbCommand.AttachSubcommand(dbStatusCommand, 1)
+
+	raceCommand = flaggy.NewSubcommand("race")
+	raceCommand.Description = i18n.T("Carreras de mecanografía en la red local")
+	raceHostCommand = flaggy.NewSubcommand("host")
+	raceHostCommand.Description = i18n.T("Inicia una carrera y espera a los demás participantes")
+	raceHostCommand.AddPositionalValue(
+		&layout,
+		"layout",
+		1,
+		false,
+		i18n.T("El nombre del layout, se puede consultar la lista de layouts disponibles a través del comando `thot list`"),
+	)
+	raceHostCommand.AddPositionalValue(
+		&lang,
+		"lang",
+		2,
+		false,
+		i18n.T("El idioma a mostrar las palabras, acepta solo los valores `spa` o `eng`"),
+	)
+	raceHostCommand.String(&raceAddr, "a", "addr", i18n.T("La dirección donde se escucha a los participantes"))
+	raceHostCommand.StringSlice(
+		&rows,
+		"r",
+		"row",
+		i18n.T("Las filas a mostrar, acepta solo los valores `row1`, `row2`, `row3`, `row4`, `thumb` o `all` para mostrar todas"),
+	)
+	raceHostCommand.Int(&length, "l", "length", i18n.T("La cantidad de palabras de la carrera"))
+	raceHostCommand.String(
+		&source,
+		"s",
+		"source",
+		i18n.T("El origen de las palabras: `auto`, `sqlite`, `embedded`, `user` o la ruta a una lista de palabras"),
+	)
+	raceHostCommand.Int64(
+		&seed,
+		"",
+		"seed",
+		i18n.T("La semilla para elegir las palabras, la misma semilla con los mismos parámetros produce el mismo texto"),
+	)
+	raceHostCommand.String(
+		&challengeCode,
+		"c",
+		"challenge",
+		i18n.T("Un código de desafío compartido por otra persona, reemplaza el layout, idioma, filas, modo, longitud, origen y semilla"),
+	)
+	raceHostCommand.String(&raceName, "n", "name", i18n.T("El nombre con el que se participa en la carrera"))
+	raceJoinCommand = flaggy.NewSubcommand("join")
+	raceJoinCommand.Description = i18n.T("Se une a la carrera de otro equipo de la red local")
+	raceJoinCommand.AddPositionalValue(
+		&raceAddr,
+		"addr",
+		1,
+		true,
+		i18n.T("La dirección del anfitrión, p.e. `192.168.1.10:7878`"),
+	)
+	raceJoinCommand.String(&raceName, "n", "name", i18n.T("El nombre con el que se participa en la carrera"))
+	raceCommand.AttachSubcommand(raceHostCommand, 1)
+	raceCommand.AttachSubcommand(raceJoinCommand, 1)
+
+	serveCommand = flaggy.NewSubcommand("serve")
+	serveCommand.Description = i18n.T("Muestra el historial de progreso en un panel web local")
+	serveCommand.String(&serveAddr, "a", "addr", i18n.T("La dirección del panel web"))
+
+	replayCommand = flaggy.NewSubcommand("replay")
+	replayCommand.Description = i18n.T("Repite una sesión de entrenamiento a partir de sus pulsaciones")
+	replayCommand.AddPositionalValue(
+		&sessionID,
+		"session-id",
+		1,
+		true,
+		i18n.T("El identificador de la sesión (p.e. `20240131-183000`) o `last` para la más reciente"),
+	)
+	replayCommand.Float64(&speed, "", "speed", i18n.T("La velocidad de la repetición, `2` es el doble de rápido"))
+
+	typeHelp := i18n.T(
+		"El tipo de teclado físico del layout, acepta solo los valores %s",
+		"`"+strings.Join(kbd.Types, "`, `")+"`",
+	)
+	layoutCommand = flaggy.NewSubcommand("layout")
+	layoutCommand.Description = i18n.T("Crea y modifica layouts del usuario")
+	layoutEditCommand = flaggy.NewSubcommand("edit")
+	layoutEditCommand.Description = i18n.T("Edita un layout de forma interactiva y lo guarda como layout del usuario")
+	layoutEditCommand.AddPositionalValue(
+		&layoutName,
+		"base",
+		1,
+		true,
+		i18n.T("El layout del que se parte, se puede consultar la lista de layouts disponibles a través del comando `thot list`"),
+	)
+	layoutEditCommand.String(&layoutAs, "", "as", i18n.T("El nombre con el que se guarda el layout editado"))
+	layoutEditCommand.String(
+		&lang,
+		"",
+		"lang",
+		i18n.T("El idioma de las palabras con las que se calculan las métricas, acepta solo los valores `spa` o `eng`"),
+	)
+	layoutEditCommand.String(&layoutType, "t", "type", typeHelp)
+	layoutExportCommand = flaggy.NewSubcommand("export")
+	layoutExportCommand.Description = i18n.T("Genera el archivo del layout para instalarlo en el sistema operativo")
+	layoutExportCommand.AddPositionalValue(
+		&layoutName,
+		"name",
+		1,
+		true,
+		i18n.T("El nombre del layout, se puede consultar la lista de layouts disponibles a través del comando `thot list`"),
+	)
+	layoutExportCommand.String(
+		&format,
+		"f",
+		"format",
+		i18n.T("El formato del archivo, acepta solo los valores %s", "`"+strings.Join(keymap.Formats(), "`, `")+"`"),
+	)
+	layoutExportCommand.String(
+		&board,
+		"b",
+		"board",
+		i18n.T("La placa de los formatos de firmware, acepta solo los valores %s", "`"+strings.Join(keymap.Boards(), "`, `")+"`"),
+	)
+	layoutExportCommand.String(
+		&output,
+		"o",
+		"output",
+		i18n.T("El archivo o directorio donde se guarda el layout, sin este valor se escribe en la salida estándar"),
+	)
+	layoutImportCommand = flaggy.NewSubcommand("import")
+	layoutImportCommand.Description = i18n.T("Importa un layout de otra herramienta y lo guarda como layout del usuario")
+	layoutImportCommand.AddPositionalValue(&importFile, "file", 1, true, i18n.T("El archivo del layout a importar"))
+	layoutImportCommand.String(
+		&importFormat,
+		"f",
+		"format",
+		i18n.T(
+			"El formato del archivo, acepta solo los valores %s. Sin este valor se elige según la extensión del archivo",
+			"`"+strings.Join(keymap.Importers(), "`, `")+"`",
+		),
+	)
+	layoutImportCommand.String(&importName, "", "name", i18n.T("El nombre con el que se guarda el layout importado"))
+	layoutImportCommand.String(&layoutType, "t", "type", typeHelp)
+	layoutCheckCommand = flaggy.NewSubcommand("check")
+	layoutCheckCommand.Description = i18n.T("Revisa los layouts y termina con error si alguno tiene problemas")
+	layoutCheckCommand.AddPositionalValue(
+		&checkName,
+		"name",
+		1,
+		false,
+		i18n.T("El nombre del layout a revisar, sin este valor se revisan todos"),
+	)
+	layoutCheckCommand.String(
+		&checkFile,
+		"",
+		"file",
+		i18n.T("El archivo de layouts a revisar, con el formato de `layout.json`. Sin este valor se revisan los layouts incluidos en Thot y los del usuario"),
+	)
+	layoutCommand.AttachSubcommand(layoutEditCommand, 1)
+	layoutCommand.AttachSubcommand(layoutExportCommand, 1)
+	layoutCommand.AttachSubcommand(layoutImportCommand, 1)
+	layoutCommand.AttachSubcommand(layoutCheckCommand, 1)
+
+	todayCommand = flaggy.NewSubcommand("today")
+	todayCommand.Description = i18n.T("Muestra el avance de la meta diaria y la racha de práctica")
+
+	achievementsCommand = flaggy.NewSubcommand("achievements")
+	achievementsCommand.Description = i18n.T("Lista los logros desbloqueados")
+
+	profileCommand = flaggy.NewSubcommand("profile")
+	profileCommand.Description = i18n.T("Administra los perfiles de usuario")
+	profileListCommand = flaggy.NewSubcommand("list")
+	profileListCommand.Description = i18n.T("Lista los perfiles existentes")
+	profileCreateCommand = flaggy.NewSubcommand("create")
+	profileCreateCommand.Description = i18n.T("Crea un perfil nuevo")
+	profileCreateCommand.AddPositionalValue(&profileName, "name", 1, true, i18n.T("El nombre del perfil"))
+	profileDeleteCommand = flaggy.NewSubcommand("delete")
+	profileDeleteCommand.Description = i18n.T("Elimina un perfil y todos sus archivos")
+	profileDeleteCommand.AddPositionalValue(&profileName, "name", 1, true, i18n.T("El nombre del perfil"))
+	profileDeleteCommand.Bool(&yes, "y", "yes", i18n.T("No pedir confirmación"))
+	profileCommand.AttachSubcommand(profileListCommand, 1)
+	profileCommand.AttachSubcommand(profileCreateCommand, 1)
+	profileCommand.AttachSubcommand(profileDeleteCommand, 1)
+
+	configCommand = flaggy.NewSubcommand("config")
+	configCommand.Description = i18n.T("Consulta o modifica la configuración de Thot")
+	configGetCommand = flaggy.NewSubcommand("get")
+	configGetCommand.Description = i18n.T("Muestra el valor de una clave de la configuración")
+	configGetCommand.AddPositionalValue(
+		&configKey,
+		"key",
+		1,
+		true,
+		i18n.T("La clave a consultar: `layout`, `lang`, `rows`, `length`, `mode`, `source`, `ui_lang`, `goal.<objetivo>` o `theme.<estilo>.<atributo>`"),
+	)
+	configSetCommand = flaggy.NewSubcommand("set")
+	configSetCommand.Description = i18n.T("Modifica el valor de una clave de la configuración")
+	configSetCommand.AddPositionalValue(
+		&configKey,
+		"key",
+		1,
+		true,
+		i18n.T("La clave a modificar: `layout`, `lang`, `rows`, `length`, `mode`, `source`, `ui_lang`, `goal.<objetivo>` o `theme.<estilo>.<atributo>`"),
+	)
+	configSetCommand.AddPositionalValue(&configValue, "value", 2, true, i18n.T("El nuevo valor de la clave"))
+	configPathCommand = flaggy.NewSubcommand("path")
+	configPathCommand.Description = i18n.T("Muestra la ruta del archivo de configuración")
+	configCommand.AttachSubcommand(configGetCommand, 1)
+	configCommand.AttachSubcommand(configSetCommand, 1)
+	configCommand.AttachSubcommand(configPathCommand, 1)
 
 	flaggy.AttachSubcommand(listCommand, 1)
 	flaggy.AttachSubcommand(printCommand, 1)
 	flaggy.AttachSubcommand(trainCommand, 1)
 	flaggy.AttachSubcommand(dbCommand, 1)
+	flaggy.AttachSubcommand(raceCommand, 1)
+	flaggy.AttachSubcommand(replayCommand, 1)
+	flaggy.AttachSubcommand(serveCommand, 1)
+	flaggy.AttachSubcommand(layoutCommand, 1)
+	flaggy.AttachSubcommand(todayCommand, 1)
+	flaggy.AttachSubcommand(achievementsCommand, 1)
+	flaggy.AttachSubcommand(profileCommand, 1)
+	flaggy.AttachSubcommand(configCommand, 1)
 
 } // }}}
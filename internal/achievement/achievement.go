@@ -0,0 +1,174 @@
+package achievement
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/paths"
+	"gitlab.com/tozd/go/errors"
+)
+
+const FileName = "achievements.json"
+
+// Identificadores de los logros, los logros por layout se guardan junto con el nombre del layout.
+const (
+	WPM40   = "wpm40"
+	WPM60   = "wpm60"
+	WPM80   = "wpm80"
+	Perfect = "perfect"
+	Streak7 = "streak7"
+	AllKeys = "allkeys"
+)
+
+// StreakTo es la cantidad de días de racha necesarios para el logro `streak7`.
+const StreakTo = 7
+
+// Achievement es un logro desbloqueado por el usuario.
+type Achievement struct {
+	ID     string    `json:"id"`
+	Layout string    `json:"layout,omitempty"`
+	Date   time.Time `json:"date"`
+}
+
+var wpmMilestones = []struct {
+	id  string
+	wpm float64
+}{
+	{WPM40, 40},
+	{WPM60, 60},
+	{WPM80, 80},
+}
+
+// Title devuelve la descripción del logro en el idioma actual.
+func (a Achievement) Title() string { // {{{
+	switch a.ID {
+	case WPM40:
+		return i18n.T("Primera sesión a 40 WPM en %s", a.Layout)
+	case WPM60:
+		return i18n.T("Primera sesión a 60 WPM en %s", a.Layout)
+	case WPM80:
+		return i18n.T("Primera sesión a 80 WPM en %s", a.Layout)
+	case Perfect:
+		return i18n.T("Sesión con 100% de precisión")
+	case Streak7:
+		return i18n.T("Racha de 7 días")
+	case AllKeys:
+		return i18n.T("Todas las teclas de %s desbloqueadas", a.Layout)
+	}
+
+	return a.ID
+} // }}}
+
+// Path devuelve la ruta del archivo con los logros desbloqueados.
+func Path() (string, errors.E) { // {{{
+	base, err := paths.Profile(paths.State)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, FileName), nil
+} // }}}
+
+// Load lee los logros desbloqueados.
+func Load() ([]Achievement, errors.E) { // {{{
+	var list []Achievement = make([]Achievement, 0)
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, e := os.ReadFile(path)
+	if os.IsNotExist(e) {
+		return list, nil
+	}
+	if e == nil {
+		e = json.Unmarshal(data, &list)
+	}
+	if e != nil {
+		return nil, errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo leer el archivo de logros")),
+			"path",
+			path,
+		)
+	}
+
+	return list, nil
+} // }}}
+
+// Save guarda la lista completa de logros desbloqueados.
+func Save(list []Achievement) errors.E { // {{{
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	data, e := json.MarshalIndent(list, "", "  ")
+	if e != nil {
+		return errors.WithMessage(e, i18n.T("No se pudo guardar el archivo de logros"))
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if e := os.WriteFile(path, data, 0644); e != nil {
+		return errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo guardar el archivo de logros")),
+			"path",
+			path,
+		)
+	}
+
+	return nil
+} // }}}
+
+// Evaluate devuelve los logros que desbloquea la sesión `current`. El historial `sessions` ya debe incluir
+// la sesión actual, `unlocked` contiene los logros obtenidos anteriormente y `layoutRows` son las filas del
+// layout de la sesión que se deben practicar para desbloquear todas sus teclas.
+func Evaluate(
+	sessions []history.Session,
+	current history.Session,
+	goal history.Goal,
+	unlocked []Achievement,
+	layoutRows []string,
+) []Achievement { // {{{
+	var found []Achievement
+	has := func(id, layout string) bool {
+		// Sin `append` para no escribir en el arreglo de `unlocked` si tiene capacidad de sobra
+		same := func(a Achievement) bool { return a.ID == id && a.Layout == layout }
+		return slices.ContainsFunc(unlocked, same) || slices.ContainsFunc(found, same)
+	}
+	add := func(id, layout string) {
+		if !has(id, layout) {
+			found = append(found, Achievement{ID: id, Layout: layout, Date: current.Start})
+		}
+	}
+
+	for _, m := range wpmMilestones {
+		if current.WPM >= m.wpm {
+			add(m.id, current.Layout)
+		}
+	}
+	if current.Chars > 0 && current.Mistakes == 0 {
+		add(Perfect, "")
+	}
+	if history.Streak(sessions, goal, current.Start) >= StreakTo {
+		add(Streak7, "")
+	}
+
+	rows := make(map[string]bool)
+	for _, s := range sessions {
+		if s.Layout != current.Layout {
+			continue
+		}
+		for _, row := range s.Rows {
+			rows[row] = true
+		}
+	}
+	if len(layoutRows) > 0 && !slices.ContainsFunc(layoutRows, func(row string) bool { return !rows[row] }) {
+		add(AllKeys, current.Layout)
+	}
+
+	return found
+} // }}}
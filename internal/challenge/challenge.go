@@ -0,0 +1,102 @@
+package challenge
+
+import (
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// version es la versión del formato del código, se incluye al inicio para poder cambiarlo sin romper los
+// códigos existentes.
+const version = "1"
+
+const separator = "|"
+
+// portable son los orígenes de palabras que producen el mismo texto en otra instalación, los diccionarios
+// del usuario y las rutas a listas de palabras solo existen en este equipo.
+var portable = []string{"sqlite", "embedded"}
+
+// Challenge contiene todo lo necesario para repetir exactamente el mismo texto de una sesión.
+type Challenge struct {
+	Layout string
+	Lang   string
+	Rows   []string
+	Mode   string
+	Length int
+	Seed   int64
+	// Source es el origen de las palabras, para obtener el mismo texto ambas personas deben tener el mismo
+	// origen (`embedded` es idéntico en todas las instalaciones de la misma versión), ver `Portable`
+	Source string
+}
+
+// Portable indica si el origen de palabras `source` se puede incluir en un código de desafío.
+func Portable(source string) bool { // {{{
+	return slices.Contains(portable, source)
+} // }}}
+
+// Code devuelve el código del desafío, texto en base64 apto para URLs que se puede copiar y compartir. Los
+// orígenes que no cumplen `Portable` no tienen código, otra persona no obtendría el mismo texto.
+func (c Challenge) Code() (string, errors.E) { // {{{
+	if !Portable(c.Source) {
+		return "", errors.WithDetails(
+			errors.New(i18n.T("El origen de palabras %q no se puede compartir en un código de desafío", c.Source)),
+			"source",
+			c.Source,
+		)
+	}
+	fields := []string{
+		version,
+		c.Layout,
+		c.Lang,
+		strings.Join(c.Rows, ","),
+		c.Mode,
+		strconv.Itoa(c.Length),
+		strconv.FormatInt(c.Seed, 36),
+		c.Source,
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(fields, separator))), nil
+} // }}}
+
+// Parse decodifica un código generado por `Challenge.Code`.
+func Parse(code string) (Challenge, errors.E) { // {{{
+	invalid := func(err error) errors.E {
+		if err == nil {
+			return errors.WithDetails(errors.New(i18n.T("El código de desafío no es valido")), "code", code)
+		}
+		return errors.WithDetails(errors.WithMessage(err, i18n.T("El código de desafío no es valido")), "code", code)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(code))
+	if err != nil {
+		return Challenge{}, invalid(err)
+	}
+	fields := strings.Split(string(data), separator)
+	// Los códigos anteriores podían incluir una ruta, que se abriría en el equipo de quien lo usa
+	if len(fields) != 8 || fields[0] != version || !Portable(fields[7]) {
+		return Challenge{}, invalid(nil)
+	}
+
+	length, err := strconv.Atoi(fields[5])
+	if err != nil {
+		return Challenge{}, invalid(err)
+	}
+	seed, err := strconv.ParseInt(fields[6], 36, 64)
+	if err != nil {
+		return Challenge{}, invalid(err)
+	}
+
+	return Challenge{
+		Layout: fields[1],
+		Lang:   fields[2],
+		Rows:   strings.Split(fields[3], ","),
+		Mode:   fields[4],
+		Length: length,
+		Seed:   seed,
+		Source: fields[7],
+	}, nil
+} // }}}
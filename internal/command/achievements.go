@@ -0,0 +1,60 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/wrodriguez/thot/internal/achievement"
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Achievements lista los logros desbloqueados.
+func Achievements() errors.E { // {{{
+	list, err := achievement.Load()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(tStyle.Render(i18n.T("󰸗  Logros")))
+	if len(list) == 0 {
+		fmt.Println(iStyle.Render(i18n.T("Aún no tienes logros, ¡a practicar!")))
+		return nil
+	}
+	for _, a := range list {
+		fmt.Println(wStyle.Render("  "+a.Title()), iStyle.Render(a.Date.Format("2006-01-02")))
+	}
+
+	return nil
+} // }}}
+
+// recordSession guarda la sesión en el historial, evalúa los logros con las filas `layoutRows` del layout
+// de la sesión y devuelve las líneas que se muestran en la pantalla de resultados.
+func recordSession(session history.Session, goal history.Goal, layoutRows []string) ([]string, errors.E) { // {{{
+	if err := history.Append(session); err != nil {
+		return nil, err
+	}
+	sessions, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := achievement.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	found := achievement.Evaluate(sessions, session, goal, unlocked, layoutRows)
+	if len(found) == 0 {
+		return nil, nil
+	}
+	if err := achievement.Save(append(unlocked, found...)); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(found))
+	for _, a := range found {
+		lines = append(lines, i18n.T("󰸗  ¡Nuevo logro! %s", a.Title()))
+	}
+
+	return lines, nil
+} // }}}
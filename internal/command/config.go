@@ -0,0 +1,47 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/wrodriguez/thot/internal/config"
+	"gitlab.com/tozd/go/errors"
+)
+
+// ConfigPath muestra la ruta del archivo de configuración.
+func ConfigPath() errors.E { // {{{
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+
+	return nil
+} // }}}
+
+// ConfigGet muestra el valor de una clave de la configuración.
+func ConfigGet(cfg *config.Config, key string) errors.E { // {{{
+	value, err := cfg.Get(key)
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+
+	return nil
+} // }}}
+
+// ConfigSet modifica el valor de una clave y guarda el archivo de configuración, si la configuración no
+// queda válida no se guarda.
+func ConfigSet(cfg *config.Config, key, value string) errors.E { // {{{
+	if err := cfg.Set(key, value); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+	fmt.Println(defStyle.Render(fmt.Sprintf("%s = %s", key, value)))
+
+	return nil
+} // }}}
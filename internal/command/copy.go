@@ -3,41 +3,76 @@ package command
 import (
 	"embed"
 	"fmt"
-	"os"
-	"path/filepath"
 
+	"github.com/wrodriguez/thot/internal/db"
+	"github.com/wrodriguez/thot/internal/i18n"
 	"gitlab.com/tozd/go/errors"
 )
 
 //go:embed data/*.db
 var fs embed.FS
 
-func CopyDB() errors.E {
-	fmt.Println(defStyle.Render("Copiando Base de Datos..."))
+// CopyDB instala la base de datos de palabras incluida en Thot. Si ya existe una base de datos solo se
+// actualizan los diccionarios incluidos, las tablas del usuario se conservan y antes de cualquier cambio
+// se crea una copia de seguridad.
+func CopyDB() errors.E { // {{{
+	fmt.Println(defStyle.Render(i18n.T("Copiando Base de Datos...")))
 	dbBytes, err := fs.ReadFile("data/words.db")
-
 	if err != nil {
-		return errors.WithMessage(err, "No se pudo crear la Base de Datos")
+		return errors.WithMessage(err, i18n.T("No se pudo crear la Base de Datos"))
 	}
 
-	h, err := os.UserHomeDir()
-	if err != nil {
-		h = os.Getenv("HOME")
-		if h == "" {
-			return errors.WithMessage(err, "No se pudo obtener el directorio HOME")
-		}
+	backup, e := db.Install(dbBytes)
+	if backup != "" {
+		fmt.Println(defStyle.Render(i18n.T("Copia de seguridad creada en ")) + backup)
+	}
+	if e != nil {
+		return e
 	}
 
-	baseDir := filepath.Join(h, ".config", "thot")
-	_ = os.MkdirAll(baseDir, 0755)
-	dbPath := filepath.Join(baseDir, "words.db")
+	dbPath, e := db.Path()
+	if e != nil {
+		return e
+	}
+	if backup != "" {
+		fmt.Println(defStyle.Render(i18n.T("Base de datos actualizada en ") + dbPath))
+	} else {
+		fmt.Println(defStyle.Render(i18n.T("Base de datos copiada en ") + dbPath))
+	}
+
+	return nil
+} // }}}
 
-	err = os.WriteFile(dbPath, dbBytes, 0644)
+// DBStatus muestra la versión de la base de datos instalada, sus tablas y las copias de seguridad.
+func DBStatus() errors.E { // {{{
+	status, err := db.Stat()
 	if err != nil {
-		return errors.WithMessage(err, "No se pudo copiar la Base de Datos")
+		return err
 	}
 
-	fmt.Println(defStyle.Render("Base de datos copiada en " + dbPath))
+	fmt.Println(defStyle.Render(i18n.T("  Ruta:")), status.Path)
+	if !status.Installed {
+		fmt.Println(wStyle.Render(i18n.T("La base de datos no está instalada, se puede instalar con `thot db`")))
+		return nil
+	}
+	fmt.Println(defStyle.Render(i18n.T("  Versión:")), fmt.Sprintf("%d / %d", status.Version, status.Latest))
+	if status.Version < status.Latest {
+		fmt.Println(wStyle.Render(i18n.T("Hay migraciones pendientes, se aplican con `thot db`")))
+	}
+
+	fmt.Println(defStyle.Render(i18n.T("  Tablas:")))
+	for _, table := range status.Tables {
+		kind := i18n.T("usuario")
+		if table.Bundled {
+			kind = i18n.T("incluida")
+		}
+		fmt.Printf("    %-20s %8d  %s\n", table.Name, table.Words, kind)
+	}
+
+	fmt.Println(defStyle.Render(i18n.T("󰁯  Copias de seguridad:")), len(status.Backups))
+	for _, backup := range status.Backups {
+		fmt.Println("    " + backup)
+	}
 
 	return nil
-}
+} // }}}
@@ -0,0 +1,226 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wrodriguez/thot/internal/editor"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/keymap"
+	"github.com/wrodriguez/thot/internal/words"
+	"gitlab.com/tozd/go/errors"
+)
+
+// LayoutEdit abre el editor interactivo sobre el layout `base` y guarda el resultado como el layout del
+// usuario `name`, las métricas se calculan con las palabras del idioma `lang`. Si `name` está vacío solo se
+// puede editar un layout del usuario, que se guarda con su mismo nombre. Si `typ` no está vacío el layout se
+// edita para ese tipo de teclado.
+func LayoutEdit(base, name, lang, typ string) errors.E { // {{{
+	k := kbd.FindLayout(base)
+	if k == nil {
+		return errors.New(i18n.T("Layout %q no encontrado", base))
+	}
+	if typ != "" {
+		var err errors.E
+		if k, err = k.Convert(typ); err != nil {
+			return err
+		}
+	}
+	if name == "" {
+		if !kbd.IsUserLayout(base) {
+			return errors.New(i18n.T("Se debe indicar el nombre del nuevo layout con `--as`"))
+		}
+		name = base
+	}
+	if err := kbd.CheckUserName(name); err != nil {
+		return err
+	}
+
+	model := editor.New(name, k, words.Embedded().All(lang))
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo abrir el editor de layouts"))
+	}
+	if model.Dirty() {
+		fmt.Println(wStyle.Render(i18n.T("Se descartaron los cambios sin guardar")))
+	}
+	if !model.Saved() {
+		return nil
+	}
+	path, _ := kbd.UserPath()
+	fmt.Println(defStyle.Render(i18n.T("󰌌  Layout:")), name)
+	fmt.Println(defStyle.Render(i18n.T("󰉋  Archivo:")), path)
+
+	return nil
+} // }}}
+
+// LayoutExport genera el archivo del layout `name` en el formato `format`, si `output` está vacío lo
+// escribe en la salida estándar y si es un directorio usa el nombre de archivo recomendado por el formato.
+// `board` es la placa de los formatos de firmware, los avisos se escriben en la salida de errores.
+func LayoutExport(name, format, board, output string) errors.E { // {{{
+	k := kbd.FindLayout(name)
+	if k == nil {
+		return errors.New(i18n.T("Layout %q no encontrado", name))
+	}
+	f, err := keymap.Find(format)
+	if err != nil {
+		return err
+	}
+	data, warnings, err := keymap.Export(f, name, k, keymap.Options{Board: board})
+	if err != nil {
+		return errors.WithDetails(err, "layout", name, "format", format)
+	}
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, wStyle.Render("  "+w))
+	}
+	if output == "" {
+		_, _ = os.Stdout.Write(data)
+		return nil
+	}
+
+	if info, e := os.Stat(output); e == nil && info.IsDir() {
+		output = filepath.Join(output, f.File(name))
+	}
+	if e := os.WriteFile(output, data, 0644); e != nil {
+		return errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo guardar el archivo exportado")),
+			"path",
+			output,
+		)
+	}
+	fmt.Println(defStyle.Render(i18n.T("󰌌  Layout:")), name)
+	fmt.Println(defStyle.Render(i18n.T("󰉋  Archivo:")), output)
+	fmt.Println()
+	fmt.Println(f.Install(name, output))
+
+	return nil
+} // }}}
+
+// LayoutImport lee el layout del archivo `path` en el formato `format` y lo guarda como el layout del usuario
+// `name`, si `format` está vacío se elige según la extensión del archivo y si `typ` no está vacío se guarda
+// para ese tipo de teclado. Muestra el layout importado y las teclas que no se pudieron asignar.
+func LayoutImport(path, format, name, typ string) errors.E { // {{{
+	if name == "" {
+		return errors.New(i18n.T("Se debe indicar el nombre del nuevo layout con `--name`"))
+	}
+	if err := kbd.CheckUserName(name); err != nil {
+		return err
+	}
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return errors.WithDetails(errors.WithMessage(e, i18n.T("No se pudo leer el archivo del layout")), "path", path)
+	}
+	k, unknown, err := keymap.Import(format, path, data)
+	if err != nil {
+		return errors.WithDetails(err, "path", path)
+	}
+	if typ != "" {
+		if k, err = k.Convert(typ); err != nil {
+			return err
+		}
+	}
+	if err := kbd.SaveUserLayout(name, k); err != nil {
+		return err
+	}
+
+	PrintLayout(name, "")
+	file, _ := kbd.UserPath()
+	fmt.Println(defStyle.Render(i18n.T("󰌌  Layout:")), name)
+	fmt.Println(defStyle.Render(i18n.T("󰉋  Archivo:")), file)
+	if len(unknown) > 0 {
+		fmt.Println()
+		fmt.Println(wStyle.Render(i18n.T("Teclas que no se pudieron asignar:")))
+		for _, key := range unknown {
+			fmt.Println(wStyle.Render("  " + key))
+		}
+	}
+
+	return nil
+} // }}}
+
+// LayoutCheck revisa los layouts incluidos en Thot y los del usuario, o los del archivo `file` si no está
+// vacío, y muestra los problemas de cada uno. Si `name` no está vacío solo revisa ese layout. Devuelve un
+// error si algún layout tiene problemas para que se pueda usar en integración continua.
+func LayoutCheck(name, file string) errors.E { // {{{
+	type source struct {
+		path string
+		data []byte
+	}
+	var sources []source
+	if file != "" {
+		data, e := os.ReadFile(file)
+		if e != nil {
+			return errors.WithDetails(errors.WithMessage(e, i18n.T("No se pudo leer el archivo de layouts")), "path", file)
+		}
+		sources = append(sources, source{file, data})
+	} else {
+		sources = append(sources, source{"layout.json", kbd.Bundled()})
+		path, err := kbd.UserPath()
+		if err != nil {
+			return err
+		}
+		data, e := os.ReadFile(path)
+		switch {
+		case e == nil:
+			sources = append(sources, source{path, data})
+		case !os.IsNotExist(e):
+			return errors.WithDetails(errors.WithMessage(e, i18n.T("No se pudo leer el archivo de layouts")), "path", path)
+		}
+	}
+
+	bundled := make(map[string]bool)
+	checked, failed, problems := 0, 0, 0
+	for i, src := range sources {
+		// El archivo se muestra antes del primer layout revisado, al revisar uno solo se omiten los demás
+		header := func() {
+			if src.path != "" {
+				fmt.Println(defStyle.Render(i18n.T("󰉋  Archivo:")), src.path)
+				src.path = ""
+			}
+		}
+		reports, err := kbd.CheckData(src.data)
+		if err != nil {
+			header()
+			fmt.Println(errStyle.Render("  ✗ " + err.Error()))
+			failed++
+			problems++
+			continue
+		}
+		for _, r := range reports {
+			switch {
+			case file == "" && i == 0:
+				bundled[r.Name] = true
+			case bundled[r.Name]:
+				// Un layout del usuario con el nombre de uno incluido no se carga, ver `kbd.LoadUserLayouts`
+				r.Problems = append(r.Problems, i18n.T("El layout %q viene incluido en Thot, se debe guardar con otro nombre", r.Name))
+			}
+			if name != "" && r.Name != name {
+				continue
+			}
+			header()
+			checked++
+			if len(r.Problems) == 0 {
+				fmt.Println(defStyle.Render("  ✓ ") + r.Name)
+				continue
+			}
+			failed++
+			problems += len(r.Problems)
+			fmt.Println(errStyle.Render("  ✗ " + r.Name))
+			for _, p := range r.Problems {
+				fmt.Println(wStyle.Render("      " + p))
+			}
+		}
+	}
+	fmt.Println()
+	if name != "" && checked == 0 {
+		return errors.New(i18n.T("Layout %q no encontrado", name))
+	}
+	if failed > 0 {
+		return errors.New(i18n.T("Se encontraron %d problemas en %d layouts", problems, failed))
+	}
+	fmt.Println(defStyle.Render(i18n.T("Los %d layouts no tienen problemas", checked)))
+
+	return nil
+} // }}}
@@ -4,24 +4,34 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/wrodriguez/thot/internal/i18n"
 	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/theme"
 	"github.com/wrodriguez/thot/internal/util"
 )
 
-const banner = `LAYOUTS SOPORTADOS POR THOT
-===========================`
-
 var wStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
 var tStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("38"))
 var iStyle = lipgloss.NewStyle().Italic(true)
+var cellStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("194")).Padding(0, 1)
+
+func init() {
+	theme.Register("command.warn", &wStyle)
+	theme.Register("command.title", &tStyle)
+	theme.Register("command.italic", &iStyle)
+	theme.Register("command.cell", &cellStyle)
+}
 
 func ListLayouts() {
 	var width, _ = util.GetConsoleSize()
 	cols := width / 30
-	fmt.Println(tStyle.Render(banner))
+	banner := i18n.T("LAYOUTS SOPORTADOS POR THOT")
+	fmt.Println(tStyle.Render(banner + "\n" + strings.Repeat("=", utf8.RuneCountInString(banner))))
 	layouts := kbd.ListLayouts()
 	sort.Strings(layouts)
 	tbl := sliceToMatrix(layouts, cols)
@@ -31,7 +41,7 @@ func ListLayouts() {
 		BorderColumn(true).
 		Rows(tbl...).
 		StyleFunc(func(row, col int) lipgloss.Style {
-			return lipgloss.NewStyle().Foreground(lipgloss.Color("194")).Padding(0, 1)
+			return cellStyle
 		})
 	fmt.Println(t.Render())
 }
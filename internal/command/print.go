@@ -2,28 +2,103 @@ package command
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/wrodriguez/thot/internal/i18n"
 	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/theme"
 	"github.com/wrodriguez/thot/internal/util"
+	"gitlab.com/tozd/go/errors"
 )
 
 var errStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
 
-func PrintLayout(layoutName string) {
+func init() {
+	theme.Register("command.err", &errStyle)
+}
+
+// PrintLayout muestra el diagrama del layout en el tamaño que cabe en la consola, si no se conoce el ancho de
+// la consola usa el tamaño completo.
+func PrintLayout(layoutName, layer string) {
 	if layout := kbd.FindLayout(layoutName); layout != nil {
+		layout, ok := layout.Layer(layer)
+		if !ok {
+			fmt.Println(errStyle.Render(layerError(layer).Error()))
+			return
+		}
 		var width, _ = util.GetConsoleSize()
-		if width < 105 {
+		if width <= 0 {
+			width = kbd.Full.Width() + 4
+		}
+		if minWidth := kbd.Mini.Width() + 4; width < minWidth {
 			fmt.Println(
 				wStyle.Render(
-					"El ancho de la consola es demasiado corto para mostrar el diagrama. Se recomienda al menos 105 caracteres de ancho.",
+					i18n.T("El ancho de la consola es demasiado corto para mostrar el diagrama. Se recomienda al menos %d caracteres de ancho.", minWidth),
 				),
 			)
-			kbd.PrintMiniKeyboard(layoutName, layout)
-			return
 		}
-		kbd.PrintKeyboard(layoutName, layout)
+		kbd.PrintKeyboard(layoutName, layout, width)
 	} else {
-		fmt.Println(errStyle.Render(fmt.Sprintf("Layout %q no encontrado", layoutName)))
+		fmt.Println(errStyle.Render(i18n.T("Layout %q no encontrado", layoutName)))
 	}
 }
+
+// PrintDiagram genera el diagrama de la capa `layer` del layout `layoutName` en el formato `format` (`svg`,
+// `html` o `txt`), si `output` está vacío lo escribe en la salida estándar y si es un directorio usa el nombre
+// del layout. Sin formato se elige según la extensión de `output` y, si no es un formato conocido, se usa
+// `txt`.
+func PrintDiagram(layoutName, format, output, layer string) errors.E { // {{{
+	layout := kbd.FindLayout(layoutName)
+	if layout == nil {
+		return errors.New(i18n.T("Layout %q no encontrado", layoutName))
+	}
+	layout, ok := layout.Layer(layer)
+	if !ok {
+		return layerError(layer)
+	}
+	if format == "" {
+		format = "txt"
+		if ext := strings.TrimPrefix(filepath.Ext(output), "."); slices.Contains(kbd.DiagramFormats, ext) {
+			format = ext
+		}
+	}
+	data, ok := kbd.Diagram(layoutName, layout, format)
+	if !ok {
+		return errors.New(i18n.T(
+			"El formato %q no es válido, acepta solo los valores %s",
+			format,
+			"`"+strings.Join(kbd.DiagramFormats, "`, `")+"`",
+		))
+	}
+	if output == "" {
+		fmt.Print(data)
+		return nil
+	}
+
+	if info, e := os.Stat(output); e == nil && info.IsDir() {
+		output = filepath.Join(output, layoutName+"."+format)
+	}
+	if e := os.WriteFile(output, []byte(data), 0644); e != nil {
+		return errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo guardar el diagrama")),
+			"path",
+			output,
+		)
+	}
+	fmt.Println(defStyle.Render(i18n.T("󰌌  Layout:")), layoutName)
+	fmt.Println(defStyle.Render(i18n.T("󰉋  Archivo:")), output)
+
+	return nil
+} // }}}
+
+func layerError(layer string) errors.E { // {{{
+	return errors.New(i18n.T(
+		"La capa %q no es válida, acepta solo los valores %s",
+		layer,
+		"`"+strings.Join(kbd.Layers, "`, `")+"`",
+	))
+} // }}}
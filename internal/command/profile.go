@@ -0,0 +1,88 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/paths"
+	"gitlab.com/tozd/go/errors"
+)
+
+// ProfileList lista los perfiles existentes marcando el perfil activo.
+func ProfileList() errors.E { // {{{
+	names, err := paths.Profiles()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if name == paths.CurrentProfile() {
+			fmt.Println(wStyle.Render("* " + name))
+		} else {
+			fmt.Println("  " + name)
+		}
+	}
+
+	return nil
+} // }}}
+
+// ProfileCreate crea un perfil nuevo con su propio historial, progreso y configuración.
+func ProfileCreate(name string) errors.E { // {{{
+	if !paths.ValidProfile(name) {
+		return errors.New(i18n.T("El nombre de perfil %q no es valido, solo se aceptan letras, números, `-` y `_`", name))
+	}
+	if paths.ProfileExists(name) {
+		return errors.New(i18n.T("El perfil %q ya existe", name))
+	}
+
+	dirs, err := paths.ProfileDirs(name)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errors.WithDetails(errors.WithMessage(err, i18n.T("No se pudo crear el perfil")), "path", dir)
+		}
+	}
+	fmt.Println(defStyle.Render(i18n.T("Perfil %q creado en %s", name, strings.Join(dirs, ", "))))
+
+	return nil
+} // }}}
+
+// ProfileDelete elimina un perfil y todos sus archivos, pide confirmación salvo que `yes` sea verdadero.
+func ProfileDelete(name string, yes bool) errors.E { // {{{
+	if name == paths.DefaultProfile {
+		return errors.New(i18n.T("El perfil %q no se puede eliminar", name))
+	}
+	if !paths.ValidProfile(name) || !paths.ProfileExists(name) {
+		return errors.New(i18n.T("El perfil %q no existe", name))
+	}
+
+	dirs, err := paths.ProfileDirs(name)
+	if err != nil {
+		return err
+	}
+	if !yes && !confirm(i18n.T("Se eliminará el perfil %q y todo su historial. ¿Seguro? [s/N] ", name)) {
+		return nil
+	}
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			return errors.WithDetails(errors.WithMessage(err, i18n.T("No se pudo eliminar el perfil")), "path", dir)
+		}
+	}
+	fmt.Println(defStyle.Render(i18n.T("Perfil %q eliminado", name)))
+
+	return nil
+} // }}}
+
+// confirm muestra la pregunta y devuelve verdadero si la respuesta es afirmativa.
+func confirm(question string) bool { // {{{
+	fmt.Print(wStyle.Render(question))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "s" || answer == "si" || answer == "sí" || answer == "y" || answer == "yes"
+} // }}}
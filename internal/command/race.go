@@ -0,0 +1,183 @@
+package command
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wrodriguez/thot/internal/challenge"
+	"github.com/wrodriguez/thot/internal/config"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/race"
+	"github.com/wrodriguez/thot/internal/util"
+	"gitlab.com/tozd/go/errors"
+)
+
+// RaceHost inicia una carrera en `addr` y participa en ella con el nombre `name`. El texto se elige con
+// las mismas opciones que `Train`, siempre en el modo `words`.
+func RaceHost(opts TrainOptions, addr, name string) errors.E { // {{{
+	words, code, err := raceWords(opts)
+	if err != nil {
+		return err
+	}
+
+	srv, err := race.Listen(addr)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+	go srv.Serve()
+
+	port := strconv.Itoa(srv.Addr().(*net.TCPAddr).Port)
+	fmt.Println(defStyle.Render(i18n.T("󰩠  Los participantes se pueden unir con:")))
+	for _, ip := range lanAddrs() {
+		fmt.Println("    thot race join " + net.JoinHostPort(ip, port))
+	}
+
+	client, err := race.Join(net.JoinHostPort("127.0.0.1", port), raceName(name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	// `Start` cierra `Joined`, se espera a que termine de mostrar los avisos para no escribir sobre la vista
+	// de la carrera
+	announced := make(chan struct{})
+	go func() {
+		defer close(announced)
+		for joined := range srv.Joined {
+			fmt.Println(wStyle.Render(i18n.T("󰀄  %s se unió a la carrera", joined)))
+		}
+	}()
+
+	fmt.Println(defStyle.Render(i18n.T("  Para comenzar la carrera pulse Enter ...")))
+	util.Pause(false)
+	srv.Start(words, code)
+	<-announced
+
+	return runRace(client, raceName(name))
+} // }}}
+
+// RaceJoin se une a la carrera del anfitrión en `addr` con el nombre `name`.
+func RaceJoin(addr, name string) errors.E { // {{{
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, race.DefaultAddr[1:])
+	}
+	client, err := race.Join(addr, raceName(name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	fmt.Println(defStyle.Render(i18n.T("󰩠  Conectado a %s, esperando a que comience la carrera ...", addr)))
+
+	return runRace(client, raceName(name))
+} // }}}
+
+// runRace espera el comienzo de la carrera y muestra la vista hasta la clasificación final.
+func runRace(client *race.Client, name string) errors.E { // {{{
+	start, err := client.WaitStart()
+	if err != nil {
+		return err
+	}
+
+	// El anfitrión cambia el nombre si otro participante ya lo usa
+	if start.Name != "" {
+		name = start.Name
+	}
+	model := race.NewModel(client, name, start.Words)
+	if _, err := tea.NewProgram(model).Run(); err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo mostrar la carrera"))
+	}
+	if model.Ranking() == nil {
+		return errors.New(i18n.T("La carrera terminó sin una clasificación"))
+	}
+	if start.Code != "" {
+		fmt.Println(defStyle.Render(i18n.T("󰆏  Desafío:")), start.Code)
+	}
+
+	return nil
+} // }}}
+
+// raceWords elige las palabras de la carrera y devuelve su código de desafío.
+func raceWords(opts TrainOptions) ([]string, string, errors.E) { // {{{
+	if opts.Challenge != "" {
+		c, err := challenge.Parse(opts.Challenge)
+		if err != nil {
+			return nil, "", err
+		}
+		opts.Layout, opts.Lang, opts.Rows = c.Layout, c.Lang, c.Rows
+		opts.Length, opts.Seed, opts.Source = c.Length, &c.Seed, c.Source
+	}
+	if opts.Seed == nil {
+		seed := rand.Int63()
+		opts.Seed = &seed
+	}
+	layout := kbd.FindLayout(opts.Layout)
+	if layout == nil {
+		return nil, "", errors.New(i18n.T("Layout %q no encontrado", opts.Layout))
+	}
+	if !validateLang(opts.Lang) {
+		return nil, "", errors.New(i18n.T("El idioma %q no es valido", opts.Lang))
+	}
+	rows := unique(opts.Rows)
+	if !validateRows(rows) {
+		return nil, "", errors.New(i18n.T("Los valores válidos para las filas son `row1`, `row2`, `row3`, `row4` o `thumb`"))
+	}
+	if util.InSlice(func(i int) bool { return rows[i] == "all" }, len(rows)) != -1 {
+		rows = allRows(layout)
+	}
+
+	source, sourceName, err := openSource(opts.Source)
+	if err != nil {
+		return nil, "", err
+	}
+	defer closeSource(source)
+	words, err := source.Words(opts.Length, opts.Lang, layout.KeySet(rows...), rand.New(rand.NewSource(*opts.Seed)))
+	if err != nil {
+		return nil, "", errors.WithMessage(err, i18n.T("No se pudo obtener las palabras"))
+	}
+	// Sin código la carrera se corre igual, el texto se envía a los participantes
+	code, _ := challenge.Challenge{
+		Layout: opts.Layout,
+		Lang:   opts.Lang,
+		Rows:   rows,
+		Mode:   config.ModeWords,
+		Length: opts.Length,
+		Seed:   *opts.Seed,
+		Source: sourceName,
+	}.Code()
+
+	return words, code, nil
+} // }}}
+
+// raceName devuelve el nombre del participante, si no se indica se usa el usuario del sistema.
+func raceName(name string) string { // {{{
+	if name != "" {
+		return name
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	host, _ := os.Hostname()
+
+	return host
+} // }}}
+
+// lanAddrs devuelve las direcciones IPv4 de la red local de este equipo, o `localhost` si no hay ninguna.
+func lanAddrs() []string { // {{{
+	var ips []string
+	addrs, _ := net.InterfaceAddrs()
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			ips = append(ips, ipnet.IP.String())
+		}
+	}
+	if len(ips) == 0 {
+		ips = append(ips, "localhost")
+	}
+
+	return ips
+} // }}}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/replay"
+	"github.com/wrodriguez/thot/internal/ui"
+	"gitlab.com/tozd/go/errors"
+)
+
+// LastSession es el identificador que se puede usar en `thot replay` para la sesión más reciente.
+const LastSession = "last"
+
+// Replay repite en la vista de entrenamiento la sesión `id` con la velocidad `speed` (1 es tiempo real).
+func Replay(id string, speed float64) errors.E { // {{{
+	if speed <= 0 {
+		return errors.New(i18n.T("La velocidad %g debe ser un número positivo", speed))
+	}
+	if id == LastSession {
+		sessions, err := history.Load()
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return errors.New(i18n.T("No hay sesiones en el historial"))
+		}
+		id = sessions[len(sessions)-1].ID
+	}
+
+	log, err := replay.Load(id)
+	if err != nil {
+		return err
+	}
+	fmt.Println(defStyle.Render(i18n.T("󰑖  Sesión:")), log.ID)
+	fmt.Println(defStyle.Render(i18n.T("󰌌  Pulsaciones:")), len(log.Events))
+
+	model := ui.NewReplay(log, speed)
+	model.Start()
+	if _, err := tea.NewProgram(model).Run(); err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo repetir la sesión"))
+	}
+
+	return nil
+} // }}}
@@ -0,0 +1,17 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/web"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Serve inicia el panel web con el historial de progreso en `addr`.
+func Serve(addr string) errors.E { // {{{
+	fmt.Println(defStyle.Render(i18n.T("󰖟  Panel de progreso en")), "http://"+addr)
+	fmt.Println(defStyle.Render(i18n.T("  Para terminar pulse Ctrl+C")))
+
+	return web.Serve(addr)
+} // }}}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/wrodriguez/thot/internal/config"
+	"github.com/wrodriguez/thot/internal/db"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/paths"
+	"github.com/wrodriguez/thot/internal/words"
+	"gitlab.com/tozd/go/errors"
+)
+
+// userWordsDir es el directorio del perfil con los diccionarios personalizados del usuario.
+const userWordsDir = "words"
+
+// openSource abre el origen de palabras `name` y devuelve también el nombre del origen usado. El origen
+// `auto` usa la base de datos SQLite si está disponible y en otro caso la lista de palabras incluida en el
+// binario.
+func openSource(name string) (words.Source, string, errors.E) { // {{{
+	switch name {
+	case config.SourceAuto, "":
+		if kdb, err := db.NewDatabase(); err == nil {
+			warnOutdated(kdb)
+			return kdb, config.SourceSQLite, nil
+		}
+		return words.Embedded(), config.SourceEmbedded, nil
+	case config.SourceSQLite:
+		kdb, err := db.NewDatabase()
+		if err != nil {
+			return nil, name, errors.WithMessage(err, i18n.T("No se pudo conectar a la Base de Datos"))
+		}
+		warnOutdated(kdb)
+		return kdb, name, nil
+	case config.SourceEmbedded:
+		return words.Embedded(), name, nil
+	case config.SourceUser:
+		dir, err := paths.Profile(paths.Data)
+		if err != nil {
+			return nil, name, err
+		}
+		list, err := words.NewDir(filepath.Join(dir, userWordsDir))
+		if err != nil {
+			return nil, name, err
+		}
+		return list, name, nil
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, name, errors.WithDetails(
+			errors.WithMessage(err, i18n.T("El origen de palabras %q no existe", name)),
+			"path",
+			name,
+		)
+	}
+	var list *words.List
+	var e errors.E
+	if info.IsDir() {
+		list, e = words.NewDir(name)
+	} else {
+		list, e = words.NewFile(name)
+	}
+	if e != nil {
+		return nil, name, e
+	}
+
+	return list, name, nil
+} // }}}
+
+// warnOutdated avisa si la base de datos tiene migraciones pendientes, se sigue usando sin sus mejoras.
+func warnOutdated(kdb *db.Database) { // {{{
+	if kdb.Version() < db.SchemaVersion {
+		fmt.Println(wStyle.Render(i18n.T(
+			"La base de datos de palabras tiene la versión %d y la actual es %d, se puede actualizar con `thot db`",
+			kdb.Version(),
+			db.SchemaVersion,
+		)))
+	}
+} // }}}
+
+// closeSource cierra el origen de palabras si mantiene una conexión abierta, como la base de datos SQLite.
+func closeSource(source words.Source) { // {{{
+	if c, ok := source.(io.Closer); ok {
+		_ = c.Close()
+	}
+} // }}}
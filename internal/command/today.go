@@ -0,0 +1,51 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Today muestra el resumen de la práctica del día, el avance de la meta diaria y la racha actual.
+func Today(goal history.Goal) errors.E { // {{{
+	sessions, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(tStyle.Render(i18n.T("󰃭  Hoy, %s", time.Now().Format("2006-01-02"))))
+	printGoalProgress(sessions, goal)
+
+	return nil
+} // }}}
+
+// printGoalProgress imprime el avance de la meta diaria y la racha de días en que se cumplió la meta.
+func printGoalProgress(sessions []history.Session, goal history.Goal) { // {{{
+	now := time.Now()
+	today := history.Summarize(sessions, now)
+	bar := progress.New(progress.WithDefaultGradient(), progress.WithWidth(30))
+
+	fmt.Println(defStyle.Render(i18n.T("󰔛  Sesiones:")), goalLine(bar, float64(today.Sessions), float64(goal.Sessions), "%.0f"))
+	fmt.Println(defStyle.Render(i18n.T("󱎫  Minutos:")), goalLine(bar, today.Minutes, float64(goal.Minutes), "%.1f"))
+	fmt.Println(defStyle.Render(i18n.T("󰌓  Mejor WPM:")), goalLine(bar, today.BestWPM, goal.WPM, "%.2f"))
+
+	streak := history.Streak(sessions, goal, now)
+	fmt.Println(defStyle.Render(i18n.T("󰈸  Racha:")), i18n.T("%d día(s)", streak))
+	if goal.Met(today) {
+		fmt.Println(wStyle.Render(i18n.T("  ¡Meta del día cumplida!")))
+	}
+} // }}}
+
+// goalLine formatea el valor actual de un objetivo, si el objetivo está definido agrega una barra de avance.
+func goalLine(bar progress.Model, value, target float64, format string) string { // {{{
+	current := fmt.Sprintf(format, value)
+	if target <= 0 {
+		return current
+	}
+
+	return bar.ViewAs(min(value/target, 1)) + " " + current + " / " + fmt.Sprintf(format, target)
+} // }}}
@@ -2,29 +2,62 @@ package command
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/wrodriguez/thot/internal/db"
+	"github.com/wrodriguez/thot/internal/challenge"
+	"github.com/wrodriguez/thot/internal/config"
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
 	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/replay"
+	"github.com/wrodriguez/thot/internal/theme"
 	"github.com/wrodriguez/thot/internal/ui"
 	"github.com/wrodriguez/thot/internal/util"
 	"gitlab.com/tozd/go/errors"
 )
 
-const LenWords = 2
+// WordsPerSecond es la cantidad de palabras por segundo que se solicitan en el modo `time`, suficiente
+// para que no se terminen las palabras antes de cumplir el tiempo.
+const WordsPerSecond = 3
 
 var defStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("36")).Italic(true)
 
+func init() {
+	theme.Register("command.default", &defStyle)
+}
+
+// TrainOptions agrupa los parámetros de una sesión de entrenamiento.
+type TrainOptions struct {
+	Layout string
+	Lang   string
+	Rows   []string
+	Length int
+	Mode   string
+	Source string
+	// Seed es la semilla para elegir las palabras, sin semilla se usa una al azar
+	Seed *int64
+	// Challenge es un código de desafío, si se indica reemplaza a los demás parámetros de la sesión
+	Challenge string
+	// Pace es la velocidad en WPM de un fantasma contra el que competir, con 0 no hay fantasma
+	Pace float64
+	// Ghost compite contra la mejor sesión anterior con el mismo código de desafío
+	Ghost bool
+	Goal  history.Goal
+}
+
 func validateLang(lang string) bool {
 	return lang == "spa" || lang == "eng"
 }
 
 func validateRows(rows []string) bool {
 	for _, row := range rows {
-		if row != "row1" && row != "row2" && row != "row3" && row != "row4" && row != "all" {
+		if row != "row1" && row != "row2" && row != "row3" && row != "row4" && row != "thumb" && row != "all" {
 			return false
 		}
 	}
@@ -32,6 +65,16 @@ func validateRows(rows []string) bool {
 	return true
 }
 
+// allRows devuelve todas las filas del layout `k`, la fila del pulgar solo si el layout la tiene.
+func allRows(k *kbd.Keyboard) []string { // {{{
+	rows := []string{"row1", "row2", "row3", "row4"}
+	if k.HasThumbs() {
+		rows = append(rows, "thumb")
+	}
+
+	return rows
+} // }}}
+
 func unique(slice []string) []string {
 	keys := make(map[string]bool)
 	list := []string{}
@@ -44,36 +87,131 @@ func unique(slice []string) []string {
 	return list
 }
 
-func Train(layoutName, lang string, rows []string) errors.E { // {{{
-	rows = unique(rows)
+func Train(opts TrainOptions) errors.E { // {{{
+	if opts.Challenge != "" {
+		c, err := challenge.Parse(opts.Challenge)
+		if err != nil {
+			return err
+		}
+		opts.Layout, opts.Lang, opts.Rows, opts.Mode = c.Layout, c.Lang, c.Rows, c.Mode
+		opts.Length, opts.Seed, opts.Source = c.Length, &c.Seed, c.Source
+	}
+	if opts.Seed == nil {
+		seed := rand.Int63()
+		opts.Seed = &seed
+	}
+	layoutName, lang := opts.Layout, opts.Lang
+	rows := unique(opts.Rows)
+	if opts.Mode != config.ModeWords && opts.Mode != config.ModeTime {
+		fmt.Println(errStyle.Render(i18n.T("El modo %q no es valido, se acepta `words` o `time`", opts.Mode)))
+		os.Exit(2)
+	}
+	if opts.Length <= 0 {
+		fmt.Println(errStyle.Render(i18n.T("La longitud %d debe ser un número entero positivo", opts.Length)))
+		os.Exit(2)
+	}
 	if layout := kbd.FindLayout(layoutName); layout != nil {
 		if validateLang(lang) {
-			lng := util.IF(lang == "spa", db.Spanish, db.English)
 			if validateRows(rows) {
 				checkAll := util.InSlice(func(i int) bool {
 					return rows[i] == "all"
 				}, len(rows))
 				if checkAll != -1 {
-					rows = []string{"row1", "row2", "row3", "row4"}
+					rows = allRows(layout)
 				}
 
-				kdb, err := db.NewDatabase()
+				source, sourceName, err := openSource(opts.Source)
 				if err != nil {
-					return errors.WithMessage(err, "No se pudo conectar a la Base de Datos")
+					return err
 				}
-				words, err := kdb.Words(LenWords, lng, layout.GetKeys(rows...))
+				defer closeSource(source)
+				limit := util.IF(opts.Mode == config.ModeTime, opts.Length*WordsPerSecond, opts.Length)
+				rng := rand.New(rand.NewSource(*opts.Seed))
+				words, err := source.Words(limit, lang, layout.KeySet(rows...), rng)
 				if err != nil {
-					return errors.WithMessage(err, "No se pudo obtener las palabras")
+					return errors.WithMessage(err, i18n.T("No se pudo obtener las palabras"))
+				}
+				code, codeErr := challenge.Challenge{
+					Layout: layoutName,
+					Lang:   lang,
+					Rows:   rows,
+					Mode:   opts.Mode,
+					Length: opts.Length,
+					Seed:   *opts.Seed,
+					Source: sourceName,
+				}.Code()
+				fmt.Println(defStyle.Render(i18n.T("󰌓  Layout:")), layoutName)
+				fmt.Println(defStyle.Render(i18n.T("  Idioma:")), lang)
+				fmt.Println(defStyle.Render(i18n.T("󰠷  Filas:")), strings.Join(rows, ", "))
+				if opts.Mode == config.ModeTime {
+					fmt.Println(defStyle.Render(i18n.T("󱎫  Duración: ")), fmt.Sprintf("%ds", opts.Length))
 				}
-				fmt.Println(defStyle.Render("󰌓  Layout:"), layoutName)
-				fmt.Println(defStyle.Render("  Idioma:"), lang)
-				fmt.Println(defStyle.Render("󰠷  Filas:"), strings.Join(rows, ", "))
-				fmt.Println(defStyle.Render("󱀍  Cantidad de palabras: "), len(words))
-				fmt.Println(defStyle.Render("󰘝  Letras a practicar: "), layout.GetKeys(rows...))
-				fmt.Println(defStyle.Render("  Para comenzar pulse Enter ..."))
+				fmt.Println(defStyle.Render(i18n.T("󱀍  Cantidad de palabras: ")), len(words))
+				fmt.Println(defStyle.Render(i18n.T("󰘝  Letras a practicar: ")), layout.GetKeys(rows...))
+				fmt.Println(defStyle.Render(i18n.T("󰒲  Semilla:")), *opts.Seed)
+				if codeErr == nil {
+					fmt.Println(defStyle.Render(i18n.T("󰆏  Desafío:")), code)
+				} else {
+					fmt.Println(wStyle.Render(i18n.T("El origen %q solo existe en este equipo, la sesión no tiene código de desafío", sourceName)))
+				}
+				var ghost ui.Ghost
+				if opts.Pace > 0 {
+					ghost = ui.PaceGhost(words, opts.Pace)
+					fmt.Println(defStyle.Render(i18n.T("󰊠  Fantasma:")), fmt.Sprintf("%.0f WPM", opts.Pace))
+				} else if opts.Ghost {
+					if best, ok := bestRun(code); ok {
+						ghost = ui.RunGhost(best.log)
+						fmt.Println(defStyle.Render(i18n.T("󰊠  Fantasma:")), i18n.T("sesión %s (%.2f WPM)", best.session.ID, best.session.WPM))
+					} else {
+						fmt.Println(wStyle.Render(i18n.T("No hay una sesión anterior con este desafío, se entrena sin fantasma")))
+					}
+				}
+				if sessions, err := history.Load(); err == nil {
+					printGoalProgress(sessions, opts.Goal)
+				}
+				fmt.Println(defStyle.Render(i18n.T("  Para comenzar pulse Enter ...")))
 				util.Pause(false)
 
 				model := ui.NewModel(words)
+				if opts.Mode == config.ModeTime {
+					model.Limit(time.Duration(opts.Length) * time.Second)
+				}
+				if ghost != nil {
+					model.Ghost(ghost)
+				}
+				model.Hint(layout.Combo)
+				var recordErr errors.E
+				model.OnStop(func(stats ui.Stats) []string {
+					// En el modo `time` el tiempo se puede acabar antes de escribir algo
+					if stats.Chars() == 0 {
+						return []string{i18n.T("No se escribió ningún carácter, la sesión no se guardó")}
+					}
+					minutes := stats.Seconds() / 60
+					lines, err := recordSession(history.Session{
+						ID:        model.StartTime().Format(history.IDFormat),
+						Start:     model.StartTime(),
+						Seconds:   stats.Seconds(),
+						Layout:    layoutName,
+						Lang:      lang,
+						Rows:      rows,
+						Mode:      opts.Mode,
+						Challenge: code,
+						Chars:     stats.Chars(),
+						Mistakes:  stats.Mistakes(),
+						WPM:       stats.WPM(stats.Chars(), stats.Mistakes(), minutes),
+						Accuracy:  stats.Accuracy(stats.Chars(), stats.Mistakes()),
+					}, opts.Goal, allRows(layout))
+					recordErr = err
+					if err == nil {
+						recordErr = replay.Save(replay.Log{
+							ID:     model.StartTime().Format(history.IDFormat),
+							Lines:  model.Lines(),
+							Limit:  util.IF(opts.Mode == config.ModeTime, time.Duration(opts.Length)*time.Second, 0),
+							Events: model.Events(),
+						})
+					}
+					return lines
+				})
 				// fmt.Printf("model: %#v\n", model)
 				p := tea.NewProgram(model)
 
@@ -83,19 +221,52 @@ func Train(layoutName, lang string, rows []string) errors.E { // {{{
 					fmt.Printf("Alas, there's been an error: %v", err)
 					os.Exit(1)
 				}
+				if recordErr != nil {
+					return recordErr
+				}
 			} else {
-				fmt.Println(errStyle.Render("Los valores válidos para las filas son `row1`, `row2`, `row3` o `row4`"))
+				fmt.Println(errStyle.Render(i18n.T("Los valores válidos para las filas son `row1`, `row2`, `row3`, `row4` o `thumb`")))
 				os.Exit(2)
 			}
 		} else {
-			fmt.Println(errStyle.Render(fmt.Sprintf("El idioma %q no es valido", lang)))
+			fmt.Println(errStyle.Render(i18n.T("El idioma %q no es valido", lang)))
 			os.Exit(2)
 		}
 	} else {
-		fmt.Println(errStyle.Render(fmt.Sprintf("Layout %q no encontrado", layoutName)))
+		fmt.Println(errStyle.Render(i18n.T("Layout %q no encontrado", layoutName)))
 		os.Exit(2)
 	}
 
 	return nil
 
 } // }}}
+
+// run es una sesión del historial junto con su registro de pulsaciones.
+type run struct {
+	session history.Session
+	log     replay.Log
+}
+
+// bestRun busca la sesión con más WPM del desafío `code` que tenga registro de pulsaciones.
+func bestRun(code string) (run, bool) { // {{{
+	if code == "" {
+		return run{}, false
+	}
+	sessions, err := history.Load()
+	if err != nil {
+		return run{}, false
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].WPM > sessions[j].WPM
+	})
+	for _, s := range sessions {
+		if s.Challenge != code {
+			continue
+		}
+		if log, err := replay.Load(s.ID); err == nil {
+			return run{session: s, log: log}, true
+		}
+	}
+
+	return run{}, false
+} // }}}
@@ -0,0 +1,355 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/paths"
+	"github.com/wrodriguez/thot/internal/theme"
+	"gitlab.com/tozd/go/errors"
+)
+
+const FileName = "config.toml"
+
+const (
+	ModeWords = "words"
+	ModeTime  = "time"
+)
+
+// Orígenes de palabras que se pueden seleccionar con `--source` o la clave `source` de la configuración,
+// cualquier otro valor se interpreta como la ruta a una lista de palabras o a un directorio con listas.
+const (
+	SourceAuto     = "auto"
+	SourceSQLite   = "sqlite"
+	SourceEmbedded = "embedded"
+	SourceUser     = "user"
+)
+
+// Sources son los orígenes de palabras con nombre, en el orden en que se muestran en los mensajes.
+var Sources = []string{SourceAuto, SourceSQLite, SourceEmbedded, SourceUser}
+
+// Config contiene los valores por defecto de Thot que se leen desde `config.toml`.
+type Config struct {
+	Layout string       `toml:"layout"`
+	Lang   string       `toml:"lang"`
+	Rows   []string     `toml:"rows"`
+	Length int          `toml:"length"`
+	Mode   string       `toml:"mode"`
+	Source string       `toml:"source"`
+	UILang string       `toml:"ui_lang,omitempty"`
+	Goal   history.Goal `toml:"goal"`
+	Theme  theme.Theme  `toml:"theme,omitempty"`
+}
+
+// invalidError es un valor no válido del archivo de configuración, ver `IsInvalid`.
+type invalidError struct{ error }
+
+// Default devuelve la configuración utilizada cuando no existe el archivo de configuración.
+func Default() *Config { // {{{
+	return &Config{
+		Layout: "qwerty",
+		Lang:   "spa",
+		Rows:   []string{"row3"},
+		Length: 2,
+		Mode:   ModeWords,
+		Source: SourceAuto,
+		Theme:  theme.Theme{},
+	}
+} // }}}
+
+// Path devuelve la ruta del archivo de configuración.
+func Path() (string, errors.E) { // {{{
+	base, err := paths.Profile(paths.Config)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, FileName), nil
+} // }}}
+
+// Load lee el archivo de configuración, los valores que no estén definidos en el archivo conservan su
+// valor por defecto. Si el archivo no existe devuelve la configuración por defecto. Si algún valor no es
+// válido devuelve la configuración leída junto con el error, ver `IsInvalid`.
+func Load() (*Config, errors.E) { // {{{
+	cfg := Default()
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return cfg, errors.WithDetails(
+			errors.WithMessage(err, i18n.T("No se pudo leer el archivo de configuración")),
+			"path",
+			path,
+		)
+	}
+	if cfg.Theme == nil {
+		cfg.Theme = theme.Theme{}
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, errors.WithDetails(errors.WithStack(invalidError{err}), "path", path)
+	}
+
+	return cfg, nil
+} // }}}
+
+// IsInvalid indica si el error de `Load` es por un valor no válido, la configuración se puede leer y
+// corregir con `thot config`.
+func IsInvalid(err error) bool { // {{{
+	var invalid invalidError
+	return errors.As(err, &invalid)
+} // }}}
+
+// Save escribe la configuración en el archivo de configuración.
+func (c *Config) Save() errors.E { // {{{
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.Buffer{}
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo generar el archivo de configuración"))
+	}
+
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return errors.WithDetails(
+			errors.WithMessage(err, i18n.T("No se pudo guardar el archivo de configuración")),
+			"path",
+			path,
+		)
+	}
+
+	return nil
+} // }}}
+
+// Get devuelve el valor de una clave de la configuración, las claves del tema tienen la forma
+// `theme.<estilo>.<atributo>`, p.e. `theme.ui.cursor.foreground`.
+func (c *Config) Get(key string) (string, errors.E) { // {{{
+	switch key {
+	case "layout":
+		return c.Layout, nil
+	case "lang":
+		return c.Lang, nil
+	case "rows":
+		return strings.Join(c.Rows, ","), nil
+	case "length":
+		return strconv.Itoa(c.Length), nil
+	case "mode":
+		return c.Mode, nil
+	case "source":
+		return c.Source, nil
+	case "ui_lang":
+		return c.UILang, nil
+	case "goal.minutes":
+		return strconv.Itoa(c.Goal.Minutes), nil
+	case "goal.sessions":
+		return strconv.Itoa(c.Goal.Sessions), nil
+	case "goal.wpm":
+		return strconv.FormatFloat(c.Goal.WPM, 'f', -1, 64), nil
+	}
+
+	name, attr, err := themeKey(key)
+	if err != nil {
+		return "", err
+	}
+	s := c.Theme[name]
+	switch attr {
+	case "foreground":
+		return s.Foreground, nil
+	case "background":
+		return s.Background, nil
+	case "border":
+		return s.Border, nil
+	case "bold":
+		return boolString(s.Bold), nil
+	default:
+		return boolString(s.Italic), nil
+	}
+} // }}}
+
+// Set asigna el valor de una clave de la configuración validando su contenido.
+func (c *Config) Set(key, value string) errors.E { // {{{
+	switch key {
+	case "layout":
+		c.Layout = value
+		return nil
+	case "lang":
+		if value != "spa" && value != "eng" {
+			return errors.New(i18n.T("El idioma %q no es valido", value))
+		}
+		c.Lang = value
+		return nil
+	case "rows":
+		c.Rows = strings.Split(value, ",")
+		return nil
+	case "length":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return errors.New(i18n.T("La longitud %q debe ser un número entero positivo", value))
+		}
+		c.Length = n
+		return nil
+	case "mode":
+		if value != ModeWords && value != ModeTime {
+			return errors.New(i18n.T("El modo %q no es valido, se acepta `words` o `time`", value))
+		}
+		c.Mode = value
+		return nil
+	case "source":
+		c.Source = value
+		return nil
+	case "ui_lang":
+		if _, ok := i18n.Parse(value); !ok {
+			return errors.New(i18n.T("El idioma de la interfaz %q no es valido, se acepta `es` o `en`", value))
+		}
+		c.UILang = value
+		return nil
+	case "goal.minutes", "goal.sessions":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return errors.New(i18n.T("La meta %q debe ser un número entero mayor o igual a cero", value))
+		}
+		if key == "goal.minutes" {
+			c.Goal.Minutes = n
+		} else {
+			c.Goal.Sessions = n
+		}
+		return nil
+	case "goal.wpm":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || n < 0 {
+			return errors.New(i18n.T("La meta %q debe ser un número mayor o igual a cero", value))
+		}
+		c.Goal.WPM = n
+		return nil
+	}
+
+	name, attr, err := themeKey(key)
+	if err != nil {
+		return err
+	}
+	s := c.Theme[name]
+	switch attr {
+	case "foreground":
+		s.Foreground = value
+	case "background":
+		s.Background = value
+	case "border":
+		s.Border = value
+	case "bold", "italic":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New(i18n.T("El valor %q no es un booleano", value))
+		}
+		if attr == "bold" {
+			s.Bold = &b
+		} else {
+			s.Italic = &b
+		}
+	}
+	if c.Theme == nil {
+		c.Theme = theme.Theme{}
+	}
+	c.Theme[name] = s
+
+	return nil
+} // }}}
+
+// Validate comprueba que los valores de la configuración sean válidos, el layout debe existir por eso los
+// layouts del usuario se deben cargar antes.
+func (c *Config) Validate() errors.E { // {{{
+	if kbd.FindLayout(c.Layout) == nil {
+		return errors.New(i18n.T("Layout %q no encontrado", c.Layout))
+	}
+	if c.Lang != "spa" && c.Lang != "eng" {
+		return errors.New(i18n.T("El idioma %q no es valido", c.Lang))
+	}
+	if len(c.Rows) == 0 {
+		return errors.New(i18n.T("Los valores válidos para las filas son `row1`, `row2`, `row3`, `row4` o `thumb`"))
+	}
+	for _, row := range c.Rows {
+		if !slices.Contains([]string{kbd.Row1, kbd.Row2, kbd.Row3, kbd.Row4, kbd.Thumb, "all"}, row) {
+			return errors.New(i18n.T("Los valores válidos para las filas son `row1`, `row2`, `row3`, `row4` o `thumb`"))
+		}
+	}
+	if c.UILang != "" {
+		if _, ok := i18n.Parse(c.UILang); !ok {
+			return errors.New(i18n.T("El idioma de la interfaz %q no es valido, se acepta `es` o `en`", c.UILang))
+		}
+	}
+	if c.Goal.Minutes < 0 || c.Goal.Sessions < 0 || c.Goal.WPM < 0 {
+		return errors.New(i18n.T("Las metas deben ser mayores o iguales a cero"))
+	}
+	if c.Mode != ModeWords && c.Mode != ModeTime {
+		return errors.New(i18n.T("El modo %q no es valido, se acepta `words` o `time`", c.Mode))
+	}
+	if c.Length <= 0 {
+		return errors.New(i18n.T("La longitud %d debe ser un número entero positivo", c.Length))
+	}
+	if c.Source != "" && !slices.Contains(Sources, c.Source) {
+		if _, err := os.Stat(c.Source); err != nil {
+			return errors.WithDetails(
+				errors.WithMessage(err, i18n.T(
+					"El origen de palabras %q no es válido, acepta %s o la ruta a una lista de palabras",
+					c.Source,
+					"`"+strings.Join(Sources, "`, `")+"`",
+				)),
+				"path",
+				c.Source,
+			)
+		}
+	}
+
+	return nil
+} // }}}
+
+func themeKey(key string) (string, string, errors.E) { // {{{
+	if !strings.HasPrefix(key, "theme.") {
+		return "", "", errors.New(i18n.T("La clave %q no existe", key))
+	}
+	key = strings.TrimPrefix(key, "theme.")
+	i := strings.LastIndex(key, ".")
+	if i == -1 {
+		return "", "", errors.New(i18n.T("La clave %q debe tener la forma `theme.<estilo>.<atributo>`", "theme."+key))
+	}
+	name, attr := key[:i], key[i+1:]
+	if !theme.Exists(name) {
+		return "", "", errors.WithDetails(
+			errors.New(i18n.T("El estilo %q no existe", name)),
+			"estilos",
+			strings.Join(theme.Names(), ", "),
+		)
+	}
+	switch attr {
+	case "foreground", "background", "border", "bold", "italic":
+		return name, attr, nil
+	}
+
+	return "", "", errors.New(i18n.T(
+		"El atributo %q no es valido, se acepta `foreground`, `background`, `border`, `bold` o `italic`",
+		attr,
+	))
+} // }}}
+
+func boolString(b *bool) string { // {{{
+	if b == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*b)
+} // }}}
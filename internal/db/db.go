@@ -1,95 +1,129 @@
+//go:build cgo
+
 package db
 
 import (
 	"database/sql"
 	"fmt"
+	"math/rand"
 	"os"
-	"path/filepath"
-	"regexp"
 
-	sqlite3 "github.com/mattn/go-sqlite3"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/words"
 	"gitlab.com/tozd/go/errors"
 )
 
-type Lang string
-
-const (
-	English Lang = "dic_en"
-	Spanish Lang = "dic_es"
-)
+// Available indica si el binario fue compilado con soporte para SQLite.
+const Available = true
 
 type Database struct {
 	db *sql.DB
+	// version es la última migración aplicada a la base de datos, ver `Version`
+	version int
+	// masked indica si las tablas tienen la columna `mascara`, si aún no se aplicó la migración las
+	// palabras se filtran en Go.
+	masked bool
 }
 
-// fnRegexp es una función auxiliar para SQLite3 ya que no soporta su implementación nativa
-func fnRegexp(re, search string) bool { // {{{
-	match, e := regexp.MatchString(re, search)
-	if e != nil {
-		return false
-	}
-
-	return match
+// NewDatabase abre la base de datos de palabras de solo lectura, la base de datos compartida entre perfiles
+// nunca se modifica al entrenar. Las migraciones pendientes se aplican con `Install` o `Upgrade`, que antes
+// crean una copia de seguridad, mientras tanto se usa sin sus mejoras (ver `Version`).
+func NewDatabase() (*Database, errors.E) { // {{{
+	return open(false)
 } // }}}
 
-func NewDatabase() (*Database, errors.E) { // {{{
-	h, err := os.UserHomeDir()
-	if err != nil {
-		h = os.Getenv("HOME")
-		if h == "" {
-			return nil, errors.WithMessage(err, "No se pudo obtener el directorio HOME")
-		}
+// open abre la base de datos de palabras instalada, solo con `write` se puede modificar.
+func open(write bool) (*Database, errors.E) { // {{{
+	dbPath, e := Path()
+	if e != nil {
+		return nil, e
 	}
-
-	dbPath := filepath.Join(h, ".config", "thot", "words.db")
 	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
 		return nil, errors.WithDetails(
-			errors.WithMessage(err, "No se pudo encontrar la base de datos"),
+			errors.WithMessage(err, i18n.T("No se pudo encontrar la base de datos")),
 			"path",
 			dbPath,
 		)
 	}
 
-	sql.Register("sqlite3_with_regexp", &sqlite3.SQLiteDriver{
-		ConnectHook: func(cnx *sqlite3.SQLiteConn) error {
-			if err := cnx.RegisterFunc("regexg", fnRegexp, true); err != nil {
-				return errors.WithMessage(err, "No se pudo registrar la función fnRegexp")
-			}
-			return nil
-		},
-	})
-
-	db, err := sql.Open("sqlite3_with_regexp", dbPath)
+	dsn := "file:" + dbPath + "?mode=ro"
+	if write {
+		dsn = dbPath
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		errors.WithMessage(err, "No se pudo conectar a la base de datos")
+		return nil, errors.WithMessage(err, i18n.T("No se pudo conectar a la base de datos"))
 	}
 
-	return &Database{db}, nil
+	d := &Database{db: db}
+	if d.version, e = version(db); e != nil {
+		db.Close()
+		return nil, errors.WithDetails(e, "path", dbPath)
+	}
+	d.masked = true
+	for _, table := range []Lang{Spanish, English} {
+		if ok, err := hasColumn(db, table, maskColumn); err != nil || !ok {
+			d.masked = false
+		}
+	}
+
+	return d, nil
+} // }}}
+
+// Version devuelve la versión de la estructura de la base de datos abierta, si es menor que
+// `SchemaVersion` hay migraciones pendientes que se aplican con `thot db`.
+func (d Database) Version() int { // {{{
+	return d.version
+} // }}}
+
+// Close cierra la conexión con la base de datos.
+func (d Database) Close() error { // {{{
+	return d.db.Close()
 } // }}}
 
-func (d Database) Words(limit int, l Lang, re string) ([]string, errors.E) { //{{{
-	var words []string = make([]string, 0)
-	qry := fmt.Sprintf(
-		"SELECT palabra FROM %s WHERE regexg('^[%s]+$', palabra) ORDER BY RANDOM() LIMIT ?",
-		string(l),
-		re,
-	)
-	rows, err := d.db.Query(
-		qry,
-		limit,
-	)
+func (d Database) Words(limit int, lang string, keys words.KeySet, rng *rand.Rand) ([]string, errors.E) { //{{{
+	var list []string = make([]string, 0)
+	// Las máscaras se guardan como enteros con signo, SQLite no tiene enteros sin signo
+	rejected := keys.Rejected()
+
+	// El nombre de la tabla sale de `LangTable`, nunca de la entrada del usuario, y las teclas solo llegan a
+	// la consulta como una máscara en un parámetro. Las palabras se leen en un orden fijo y se eligen con
+	// `rng` para que la misma semilla devuelva siempre las mismas palabras
+	var rows *sql.Rows
+	var err error
+	if d.masked {
+		rows, err = d.db.Query(
+			fmt.Sprintf(
+				"SELECT palabra FROM %s WHERE (%s & ?) = 0 ORDER BY rowid",
+				string(LangTable(lang)),
+				maskColumn,
+			),
+			int64(rejected),
+		)
+	} else {
+		rows, err = d.db.Query(fmt.Sprintf("SELECT palabra FROM %s ORDER BY rowid", string(LangTable(lang))))
+	}
 	if err != nil {
-		return nil, errors.WithMessage(err, "No se pudo obtener las palabras")
+		return nil, errors.WithMessage(err, i18n.T("No se pudo obtener las palabras"))
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var word string
 		if err := rows.Scan(&word); err != nil {
-			return nil, errors.WithMessage(err, "No se pudo obtener la palabra")
+			return nil, errors.WithMessage(err, i18n.T("No se pudo obtener la palabra"))
 		}
-		words = append(words, word)
+		if words.Mask(word)&rejected == 0 && keys.Allows(word) {
+			list = append(list, word)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithMessage(err, i18n.T("No se pudo obtener las palabras"))
+	}
+	if len(list) == 0 {
+		return nil, words.NoWords(lang, keys)
 	}
 
-	return words, nil
+	return words.Pick(list, limit, rng), nil
 } //}}}
@@ -0,0 +1,48 @@
+//go:build !cgo
+
+package db
+
+import (
+	"math/rand"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/words"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Available indica si el binario fue compilado con soporte para SQLite.
+const Available = false
+
+// Database no está disponible cuando se compila con `CGO_ENABLED=0`, ya que go-sqlite3 necesita cgo.
+type Database struct{}
+
+func NewDatabase() (*Database, errors.E) { // {{{
+	return nil, errors.New(i18n.T("Thot fue compilado sin soporte para SQLite (CGO_ENABLED=0)"))
+} // }}}
+
+func (d Database) Words(limit int, lang string, keys words.KeySet, rng *rand.Rand) ([]string, errors.E) { //{{{
+	return nil, errors.New(i18n.T("Thot fue compilado sin soporte para SQLite (CGO_ENABLED=0)"))
+} //}}}
+
+func (d Database) Version() int { // {{{
+	return 0
+} // }}}
+
+func (d Database) Close() error { // {{{
+	return nil
+} // }}}
+
+// SchemaVersion es la versión de la estructura de la base de datos que espera esta versión de Thot.
+var SchemaVersion = 0
+
+func Install(bundled []byte) (string, errors.E) { // {{{
+	return "", errors.New(i18n.T("Thot fue compilado sin soporte para SQLite (CGO_ENABLED=0)"))
+} // }}}
+
+func Upgrade(bundled []byte) (string, errors.E) { // {{{
+	return "", errors.New(i18n.T("Thot fue compilado sin soporte para SQLite (CGO_ENABLED=0)"))
+} // }}}
+
+func Stat() (Status, errors.E) { // {{{
+	return Status{}, errors.New(i18n.T("Thot fue compilado sin soporte para SQLite (CGO_ENABLED=0)"))
+} // }}}
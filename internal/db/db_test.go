@@ -0,0 +1,149 @@
+//go:build cgo
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/wrodriguez/thot/internal/words"
+)
+
+// registerOnce registra el driver con la función `regexg` del filtro anterior a las máscaras.
+var registerOnce sync.Once
+
+// testKeys son conjuntos de teclas como los de las filas de los layouts, con letras acentuadas.
+var testKeys = []string{
+	"asdfghjklñ",
+	"asdfghjklñqwertyuiop",
+	"qwertyuiopasdfghjklñzxcvbnm",
+	"aeiouáéíóúnrstlcdm",
+	"abcdefghijklmnopqrstuvwxyzñáéíóúü",
+}
+
+// testDatabase crea una base de datos temporal con las palabras incluidas en Thot y le aplica las
+// migraciones, `path` sirve para abrirla con otro driver.
+func testDatabase(tb testing.TB) (*Database, string) { // {{{
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), FileName)
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+
+	embedded := words.Embedded()
+	for _, table := range []Lang{Spanish, English} {
+		if _, err := conn.Exec(fmt.Sprintf("CREATE TABLE %s (palabra TEXT NOT NULL)", table)); err != nil {
+			tb.Fatal(err)
+		}
+		lang := map[Lang]string{Spanish: "spa", English: "eng"}[table]
+		for _, word := range embedded.All(lang) {
+			if _, err := conn.Exec(fmt.Sprintf("INSERT INTO %s (palabra) VALUES (?)", table), word); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+	if err := migrate(conn); err != nil {
+		tb.Fatal(err)
+	}
+
+	return &Database{db: conn, masked: true}, path
+} // }}}
+
+// regexpDatabase abre la base de datos `path` con la función `regexg` que usaba el filtro anterior.
+func regexpDatabase(tb testing.TB, path string) *sql.DB { // {{{
+	tb.Helper()
+	registerOnce.Do(func() {
+		sql.Register("sqlite3_with_regexp", &sqlite3.SQLiteDriver{
+			ConnectHook: func(cnx *sqlite3.SQLiteConn) error {
+				return cnx.RegisterFunc("regexg", func(re, search string) bool {
+					match, _ := regexp.MatchString(re, search)
+					return match
+				}, true)
+			},
+		})
+	})
+	conn, err := sql.Open("sqlite3_with_regexp", path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+
+	return conn
+} // }}}
+
+// regexpWords devuelve las palabras del idioma `lang` con el filtro anterior a las máscaras.
+func regexpWords(tb testing.TB, conn *sql.DB, lang, keys string) []string { // {{{
+	rows, err := conn.Query(
+		fmt.Sprintf("SELECT palabra FROM %s WHERE regexg('^[%s]+$', palabra)", LangTable(lang), keys),
+	)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer rows.Close()
+
+	var list []string
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			tb.Fatal(err)
+		}
+		list = append(list, word)
+	}
+
+	return list
+} // }}}
+
+func TestWordsMatchesRegexp(t *testing.T) { // {{{
+	d, path := testDatabase(t)
+	old := regexpDatabase(t, path)
+	for _, masked := range []bool{true, false} {
+		d.masked = masked
+		for _, lang := range []string{"spa", "eng"} {
+			for _, keys := range testKeys {
+				want := regexpWords(t, old, lang, keys)
+				got, err := d.Words(1<<20, lang, words.NewKeySet([]rune(keys)...), rand.New(rand.NewSource(1)))
+				if err != nil && len(want) > 0 {
+					t.Fatalf("Words(%s, %q): %s", lang, keys, err)
+				}
+				slices.Sort(got)
+				slices.Sort(want)
+				if !slices.Equal(got, want) {
+					t.Errorf(
+						"Words(%s, %q) con masked=%v devolvió %d palabras, la expresión regular %d",
+						lang, keys, masked, len(got), len(want),
+					)
+				}
+			}
+		}
+	}
+} // }}}
+
+func BenchmarkWords(b *testing.B) { // {{{
+	d, path := testDatabase(b)
+	old := regexpDatabase(b, path)
+	keys := testKeys[1]
+
+	b.Run("regexp", func(b *testing.B) {
+		for range b.N {
+			regexpWords(b, old, "spa", keys)
+		}
+	})
+	b.Run("mask", func(b *testing.B) {
+		set := words.NewKeySet([]rune(keys)...)
+		rng := rand.New(rand.NewSource(1))
+		for range b.N {
+			if _, err := d.Words(1<<20, "spa", set, rng); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+} // }}}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"path/filepath"
+
+	"github.com/wrodriguez/thot/internal/paths"
+	"gitlab.com/tozd/go/errors"
+)
+
+const FileName = "words.db"
+
+type Lang string
+
+const (
+	English Lang = "dic_en"
+	Spanish Lang = "dic_es"
+)
+
+// LangTable devuelve la tabla del diccionario para un idioma de entrenamiento (`spa` o `eng`).
+func LangTable(lang string) Lang { // {{{
+	if lang == "spa" {
+		return Spanish
+	}
+
+	return English
+} // }}}
+
+// Path devuelve la ruta de la base de datos de palabras, es de solo lectura y se comparte entre todos los
+// perfiles.
+func Path() (string, errors.E) { // {{{
+	base, err := paths.Dir(paths.Data)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, FileName), nil
+} // }}}
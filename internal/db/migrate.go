@@ -0,0 +1,157 @@
+//go:build cgo
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/words"
+	"gitlab.com/tozd/go/errors"
+)
+
+// maskColumn guarda el conjunto de letras de cada palabra (ver `words.Mask`), permite filtrar las palabras
+// con una comparación de enteros en lugar de evaluar una expresión regular por cada fila.
+const maskColumn = "mascara"
+
+// migration es un cambio en la estructura de la base de datos, se aplica una sola vez y en orden.
+type migration struct {
+	version int
+	apply   func(tx *sql.Tx) errors.E
+}
+
+// migrations contiene todos los cambios de la base de datos, `SchemaVersion` es la versión de la última.
+// Las migraciones nunca se modifican una vez publicadas, los cambios nuevos se agregan al final.
+var migrations = []migration{
+	// 1: Tablas originales de los diccionarios, solo se registra la versión
+	{version: 1, apply: func(tx *sql.Tx) errors.E { return nil }},
+	// 2: Máscara de letras de cada palabra
+	{version: 2, apply: func(tx *sql.Tx) errors.E {
+		for _, table := range []Lang{Spanish, English} {
+			if err := addMasks(tx, table); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+// SchemaVersion es la versión de la estructura de la base de datos que espera esta versión de Thot.
+var SchemaVersion = migrations[len(migrations)-1].version
+
+// migrate crea la tabla `schema_version` si no existe y aplica las migraciones pendientes, cada una en su
+// propia transacción.
+func migrate(db *sql.DB) errors.E { // {{{
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER PRIMARY KEY, applied TEXT NOT NULL)")
+	if err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+	}
+	current, e := version(db)
+	if e != nil {
+		return e
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+		}
+		if err := m.apply(tx); err != nil {
+			tx.Rollback()
+			return errors.WithDetails(err, "version", m.version)
+		}
+		_, err = tx.Exec(
+			"INSERT INTO schema_version (version, applied) VALUES (?, ?)",
+			m.version,
+			time.Now().Format(time.RFC3339),
+		)
+		if err != nil {
+			tx.Rollback()
+			return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+		}
+		if err := tx.Commit(); err != nil {
+			return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+		}
+	}
+
+	return nil
+} // }}}
+
+// version devuelve la última migración aplicada, 0 si la base de datos nunca fue migrada.
+func version(db *sql.DB) (int, errors.E) { // {{{
+	var exists int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'").Scan(&exists)
+	if err != nil {
+		return 0, errors.WithMessage(err, i18n.T("No se pudo leer la estructura de la base de datos"))
+	}
+	if exists == 0 {
+		return 0, nil
+	}
+
+	var current int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&current); err != nil {
+		return 0, errors.WithMessage(err, i18n.T("No se pudo leer la estructura de la base de datos"))
+	}
+
+	return current, nil
+} // }}}
+
+// querier es la parte común de `sql.DB` y `sql.Tx` que se usa para consultar la estructura.
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// hasColumn indica si la tabla `table` tiene la columna `column`.
+func hasColumn(q querier, table Lang, column string) (bool, errors.E) { // {{{
+	var count int
+	err := q.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", string(table), column).Scan(&count)
+	if err != nil {
+		return false, errors.WithMessage(err, i18n.T("No se pudo leer la estructura de la base de datos"))
+	}
+
+	return count > 0, nil
+} // }}}
+
+// addMasks agrega y calcula la columna `mascara` de un diccionario si aún no existe.
+func addMasks(tx *sql.Tx, table Lang) errors.E { // {{{
+	if ok, err := hasColumn(tx, table, maskColumn); err != nil || ok {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s INTEGER", table, maskColumn)); err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+	}
+
+	rows, err := tx.Query(fmt.Sprintf("SELECT rowid, palabra FROM %s", table))
+	if err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo obtener las palabras"))
+	}
+	masks := make(map[int64]uint64)
+	for rows.Next() {
+		var id int64
+		var word string
+		if err := rows.Scan(&id, &word); err != nil {
+			rows.Close()
+			return errors.WithMessage(err, i18n.T("No se pudo obtener la palabra"))
+		}
+		masks[id] = words.Mask(word)
+	}
+	rows.Close()
+
+	stmt, err := tx.Prepare(fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", table, maskColumn))
+	if err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+	}
+	defer stmt.Close()
+	for id, mask := range masks {
+		if _, err := stmt.Exec(int64(mask), id); err != nil {
+			return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+		}
+	}
+
+	return nil
+} // }}}
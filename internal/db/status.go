@@ -0,0 +1,108 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// backupFormat es el formato de la fecha en el nombre de las copias de seguridad,
+// p.e. `words.db.20240102-150405.bak`.
+const backupFormat = "20060102-150405"
+
+// Status describe la base de datos de palabras instalada.
+type Status struct {
+	Path      string
+	Installed bool
+	// Version es la última migración aplicada y Latest la que espera esta versión de Thot
+	Version int
+	Latest  int
+	Tables  []Table
+	Backups []string
+}
+
+// Table es una tabla de la base de datos, `Bundled` indica si es un diccionario incluido en Thot, las
+// demás tablas son del usuario y nunca se modifican al actualizar.
+type Table struct {
+	Name    string
+	Words   int
+	Bundled bool
+}
+
+// Bundled indica si `name` es una de las tablas de diccionario que se reemplazan al actualizar.
+func Bundled(name string) bool { // {{{
+	return name == string(Spanish) || name == string(English)
+} // }}}
+
+// Backups devuelve las copias de seguridad de la base de datos, de la más antigua a la más reciente.
+func Backups() ([]string, errors.E) { // {{{
+	dbPath, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	list, e := filepath.Glob(dbPath + ".*.bak")
+	if e != nil {
+		return nil, errors.WithStack(e)
+	}
+	sort.Strings(list)
+
+	return list, nil
+} // }}}
+
+// Backup copia la base de datos instalada a `words.db.<fecha>.bak` en el mismo directorio y devuelve la
+// ruta de la copia. Si ya hay una copia con la misma fecha se agrega un contador, p.e.
+// `words.db.20240102-150405_02.bak`, que se ordena después de la primera.
+func Backup() (string, errors.E) { // {{{
+	dbPath, err := Path()
+	if err != nil {
+		return "", err
+	}
+	stamp := time.Now().Format(backupFormat)
+	backup := strings.Join([]string{dbPath, stamp, "bak"}, ".")
+
+	src, e := os.Open(dbPath)
+	if e != nil {
+		return "", errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo crear la copia de seguridad")),
+			"path",
+			dbPath,
+		)
+	}
+	defer src.Close()
+	dst, e := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	for n := 2; os.IsExist(e) && n < 100; n++ {
+		backup = strings.Join([]string{dbPath, fmt.Sprintf("%s_%02d", stamp, n), "bak"}, ".")
+		dst, e = os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	}
+	if e != nil {
+		return "", errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo crear la copia de seguridad")),
+			"path",
+			backup,
+		)
+	}
+	if _, e := io.Copy(dst, src); e != nil {
+		dst.Close()
+		return "", errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo crear la copia de seguridad")),
+			"path",
+			backup,
+		)
+	}
+	if e := dst.Close(); e != nil {
+		return "", errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo crear la copia de seguridad")),
+			"path",
+			backup,
+		)
+	}
+
+	return backup, nil
+} // }}}
@@ -0,0 +1,199 @@
+//go:build cgo
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Install instala la base de datos `bundled` si no existe o, si ya existe, la actualiza con `Upgrade`.
+// Devuelve la ruta de la copia de seguridad creada antes de actualizar, vacía en una instalación nueva.
+func Install(bundled []byte) (string, errors.E) { // {{{
+	dbPath, err := Path()
+	if err != nil {
+		return "", err
+	}
+	if _, e := os.Stat(dbPath); os.IsNotExist(e) {
+		_ = os.MkdirAll(filepath.Dir(dbPath), 0755)
+		if e := os.WriteFile(dbPath, bundled, 0644); e != nil {
+			return "", errors.WithMessage(e, i18n.T("No se pudo copiar la Base de Datos"))
+		}
+		d, err := open(true)
+		if err != nil {
+			return "", err
+		}
+		defer d.Close()
+		return "", errors.WithDetails(migrate(d.db), "path", dbPath)
+	}
+
+	return Upgrade(bundled)
+} // }}}
+
+// Upgrade aplica las migraciones pendientes y reemplaza los diccionarios incluidos en Thot (`dic_es` y
+// `dic_en`) por los de `bundled` sin tocar las demás tablas de la base de datos instalada. Antes de
+// cualquier cambio crea una copia de seguridad y devuelve su ruta.
+func Upgrade(bundled []byte) (string, errors.E) { // {{{
+	dbPath, err := Path()
+	if err != nil {
+		return "", err
+	}
+	backup, err := Backup()
+	if err != nil {
+		return "", err
+	}
+
+	// La copia incluida se escribe junto a la base de datos para poder adjuntarla con ATTACH
+	tmp, e := os.CreateTemp(filepath.Dir(dbPath), FileName+".*.new")
+	if e != nil {
+		return backup, errors.WithMessage(e, i18n.T("No se pudo actualizar la base de datos"))
+	}
+	defer os.Remove(tmp.Name())
+	if _, e := tmp.Write(bundled); e != nil {
+		tmp.Close()
+		return backup, errors.WithMessage(e, i18n.T("No se pudo actualizar la base de datos"))
+	}
+	tmp.Close()
+
+	d, err := open(true)
+	if err != nil {
+		return backup, err
+	}
+	defer d.Close()
+	if err := migrate(d.db); err != nil {
+		return backup, errors.WithDetails(err, "path", dbPath)
+	}
+	// ATTACH y DETACH no se pueden ejecutar dentro de una transacción, se usa una sola conexión
+	d.db.SetMaxOpenConns(1)
+	if _, e := d.db.Exec("ATTACH DATABASE ? AS bundled", tmp.Name()); e != nil {
+		return backup, errors.WithMessage(e, i18n.T("No se pudo actualizar la base de datos"))
+	}
+	defer d.db.Exec("DETACH DATABASE bundled")
+
+	tx, e := d.db.Begin()
+	if e != nil {
+		return backup, errors.WithMessage(e, i18n.T("No se pudo actualizar la base de datos"))
+	}
+	defer tx.Rollback()
+	for _, table := range []Lang{Spanish, English} {
+		if err := replaceTable(tx, table); err != nil {
+			return backup, errors.WithDetails(err, "table", string(table))
+		}
+		if err := addMasks(tx, table); err != nil {
+			return backup, errors.WithDetails(err, "table", string(table))
+		}
+	}
+	if e := tx.Commit(); e != nil {
+		return backup, errors.WithMessage(e, i18n.T("No se pudo actualizar la base de datos"))
+	}
+
+	return backup, nil
+} // }}}
+
+// replaceTable reemplaza la tabla `table` de la base de datos instalada por la de la copia adjunta como
+// `bundled`, con la misma definición e índices.
+func replaceTable(tx *sql.Tx, table Lang) errors.E { // {{{
+	var create string
+	err := tx.QueryRow(
+		"SELECT sql FROM bundled.sqlite_master WHERE type = 'table' AND name = ?",
+		string(table),
+	).Scan(&create)
+	if err == sql.ErrNoRows {
+		// La copia incluida no tiene este diccionario, se conserva el instalado
+		return nil
+	} else if err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo leer la estructura de la base de datos"))
+	}
+
+	var indexes []string
+	rows, err := tx.Query(
+		"SELECT sql FROM bundled.sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL",
+		string(table),
+	)
+	if err != nil {
+		return errors.WithMessage(err, i18n.T("No se pudo leer la estructura de la base de datos"))
+	}
+	for rows.Next() {
+		var index string
+		if err := rows.Scan(&index); err != nil {
+			rows.Close()
+			return errors.WithMessage(err, i18n.T("No se pudo leer la estructura de la base de datos"))
+		}
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+
+	// Las sentencias CREATE sin esquema crean los objetos en `main`
+	statements := []string{fmt.Sprintf("DROP TABLE IF EXISTS main.%s", table), create}
+	statements = append(statements, fmt.Sprintf("INSERT INTO main.%s SELECT * FROM bundled.%s", table, table))
+	statements = append(statements, indexes...)
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return errors.WithMessage(err, i18n.T("No se pudo actualizar la base de datos"))
+		}
+	}
+
+	return nil
+} // }}}
+
+// Stat devuelve el estado de la base de datos instalada sin modificarla.
+func Stat() (Status, errors.E) { // {{{
+	status := Status{Latest: SchemaVersion}
+	dbPath, err := Path()
+	if err != nil {
+		return status, err
+	}
+	status.Path = dbPath
+	if status.Backups, err = Backups(); err != nil {
+		return status, err
+	}
+	if _, e := os.Stat(dbPath); os.IsNotExist(e) {
+		return status, nil
+	}
+	status.Installed = true
+
+	db, e := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if e != nil {
+		return status, errors.WithMessage(e, i18n.T("No se pudo conectar a la base de datos"))
+	}
+	defer db.Close()
+	if status.Version, err = version(db); err != nil {
+		return status, err
+	}
+
+	rows, e := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if e != nil {
+		return status, errors.WithMessage(e, i18n.T("No se pudo leer la estructura de la base de datos"))
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if e := rows.Scan(&name); e != nil {
+			rows.Close()
+			return status, errors.WithMessage(e, i18n.T("No se pudo leer la estructura de la base de datos"))
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+
+	for _, name := range names {
+		if name == "schema_version" {
+			continue
+		}
+		table := Table{Name: name, Bundled: Bundled(name)}
+		// El nombre sale de sqlite_master, se escapa como identificador por si contiene comillas
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, strings.ReplaceAll(name, `"`, `""`))
+		if e := db.QueryRow(query).Scan(&table.Words); e != nil {
+			return status, errors.WithMessage(e, i18n.T("No se pudo obtener las palabras"))
+		}
+		status.Tables = append(status.Tables, table)
+	}
+
+	return status, nil
+} // }}}
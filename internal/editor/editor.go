@@ -0,0 +1,434 @@
+package editor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/theme"
+)
+
+// Secuencias de escape para resaltar teclas dentro de las plantillas, un estilo de lipgloss termina con un
+// reinicio completo que borraría el color de dedo del resto de la fila.
+const (
+	cursorOn  = "\x1b[7m"
+	cursorOff = "\x1b[27m"
+	markOn    = "\x1b[4;1m"
+	markOff   = "\x1b[24;22m"
+)
+
+var (
+	titleStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("38")).Bold(true)
+	labelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("151"))
+	valueStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
+	warnStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("197"))
+)
+
+func init() { // {{{
+	theme.Register("editor.title", &titleStyle)
+	theme.Register("editor.label", &labelStyle)
+	theme.Register("editor.value", &valueStyle)
+	theme.Register("editor.status", &statusStyle)
+	theme.Register("editor.warn", &warnStyle)
+} // }}}
+
+// KeyMap son las combinaciones de teclas del editor.
+type KeyMap struct {
+	Mover     key.Binding
+	Marcar    key.Binding
+	Editar    key.Binding
+	Reiniciar key.Binding
+	Guardar   key.Binding
+	Salir     key.Binding
+}
+
+// DefaultKeyMap devuelve las combinaciones de teclas del editor en el idioma actual.
+func DefaultKeyMap() KeyMap { // {{{
+	return KeyMap{
+		Mover: key.NewBinding(
+			key.WithKeys("up", "down", "left", "right", "h", "j", "k", "l"),
+			key.WithHelp("←↓↑→", i18n.T("Mover")),
+		),
+		Marcar: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp(i18n.T("espacio"), i18n.T("Intercambiar")),
+		),
+		Editar: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", i18n.T("Cambiar caracteres")),
+		),
+		Reiniciar: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", i18n.T("Restaurar tecla")),
+		),
+		Guardar: key.NewBinding(
+			key.WithKeys("s", "ctrl+s"),
+			key.WithHelp("s", i18n.T("Guardar")),
+		),
+		Salir: key.NewBinding(
+			key.WithKeys("esc", "q", "ctrl+c"),
+			key.WithHelp("esc", i18n.T("Salir")),
+		),
+	}
+} // }}}
+
+// Forma parte de la interfaz key.Map.
+func (k KeyMap) ShortHelp() []key.Binding { // {{{
+	return []key.Binding{k.Mover, k.Marcar, k.Editar, k.Reiniciar, k.Guardar, k.Salir}
+} // }}}
+
+// Forma parte de la interfaz key.Map.
+func (k KeyMap) FullHelp() [][]key.Binding { // {{{
+	return [][]key.Binding{k.ShortHelp()}
+} // }}}
+
+// slot es la posición de una tecla en el teclado.
+type slot struct {
+	row int
+	col int
+}
+
+// Model es el editor interactivo de layouts: muestra el teclado con un cursor sobre una tecla, permite
+// intercambiar teclas o cambiar sus caracteres y recalcula las métricas con cada cambio.
+type Model struct {
+	name   string
+	base   *kbd.Keyboard
+	layout *kbd.Keyboard
+	// rows son las filas que se pueden editar, las del pulgar solo en los teclados matriciales
+	rows    []string
+	words   []string
+	metrics kbd.Metrics
+	cursor  slot
+	// marked es la tecla elegida para intercambiar, nil si no hay ninguna
+	marked *slot
+	// editing indica que las siguientes pulsaciones son los caracteres de la tecla, lower guarda la
+	// minúscula mientras se espera la mayúscula
+	editing bool
+	lower   rune
+	dirty   bool
+	saved   bool
+	// leaving indica que se pidió salir con cambios sin guardar
+	leaving bool
+	status  string
+	warn    bool
+	width   int
+	keys    KeyMap
+	help    help.Model
+}
+
+// New crea el editor del layout `base` que se guarda con el nombre `name`, las métricas se calculan con
+// las palabras de `words`. En los teclados matriciales las teclas del pulgar que faltan se agregan vacías.
+func New(name string, base *kbd.Keyboard, words []string) *Model { // {{{
+	m := &Model{
+		name:   name,
+		base:   base,
+		layout: base.Clone(),
+		rows:   []string{kbd.Row1, kbd.Row2, kbd.Row3, kbd.Row4},
+		words:  words,
+		width:  kbd.Full.Width(),
+		keys:   DefaultKeyMap(),
+		help:   help.New(),
+	}
+	if kbd.IsMatrix(base.Type) {
+		m.rows = append(m.rows, kbd.Thumb)
+		thumbs := m.layout.Keys[kbd.Thumb]
+		for len(thumbs) < 2*kbd.ThumbKeys {
+			thumbs = append(thumbs, "")
+		}
+		m.layout.Keys[kbd.Thumb] = thumbs
+	}
+	m.metrics = m.layout.Metrics(words)
+	// Un layout del usuario puede no tener alguna fila, el cursor empieza en la primera con teclas
+	if len(m.keysOf(0)) == 0 {
+		m.cursor.row = m.nextRow(1)
+	}
+
+	return m
+} // }}}
+
+// Layout devuelve el layout con los cambios hechos hasta el momento.
+func (m *Model) Layout() *kbd.Keyboard { // {{{
+	return m.layout
+} // }}}
+
+// Saved indica si el layout se guardó al menos una vez.
+func (m *Model) Saved() bool { // {{{
+	return m.saved
+} // }}}
+
+// Dirty indica si quedaron cambios sin guardar.
+func (m *Model) Dirty() bool { // {{{
+	return m.dirty
+} // }}}
+
+func (m *Model) Init() tea.Cmd { // {{{
+	return nil
+} // }}}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) { // {{{
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.help.Width = msg.Width
+	case tea.KeyMsg:
+		if m.editing {
+			m.edit(msg)
+			return m, nil
+		}
+		if key.Matches(msg, m.keys.Salir) {
+			if m.dirty && !m.leaving && msg.String() != "ctrl+c" {
+				m.leaving = true
+				m.setStatus(i18n.T("Hay cambios sin guardar, vuelve a pulsar esc para salir"), true)
+				return m, nil
+			}
+			return m, tea.Quit
+		}
+		m.leaving = false
+		switch {
+		case key.Matches(msg, m.keys.Mover):
+			m.move(msg.String())
+		case key.Matches(msg, m.keys.Marcar):
+			m.mark()
+		case key.Matches(msg, m.keys.Editar) && m.onKey():
+			m.editing = true
+			m.lower = 0
+			m.setStatus(i18n.T("Escribe el carácter normal de la tecla, esc cancela"), false)
+		case key.Matches(msg, m.keys.Reiniciar):
+			m.restore()
+		case key.Matches(msg, m.keys.Guardar):
+			m.save()
+		}
+	}
+
+	return m, nil
+} // }}}
+
+// move mueve el cursor, al cambiar de fila se queda en la última tecla si la nueva fila es más corta y salta
+// las filas sin teclas.
+func (m *Model) move(dir string) { // {{{
+	switch dir {
+	case "up", "k":
+		m.cursor.row = m.nextRow(-1)
+	case "down", "j":
+		m.cursor.row = m.nextRow(1)
+	case "left", "h":
+		m.cursor.col--
+	case "right", "l":
+		m.cursor.col++
+	}
+	m.cursor.col = max(min(m.cursor.col, len(m.keysOf(m.cursor.row))-1), 0)
+} // }}}
+
+// nextRow devuelve la siguiente fila con teclas en la dirección `step`, la fila actual si no hay otra.
+func (m *Model) nextRow(step int) int { // {{{
+	for row := m.cursor.row + step; row >= 0 && row < len(m.rows); row += step {
+		if len(m.keysOf(row)) > 0 {
+			return row
+		}
+	}
+
+	return m.cursor.row
+} // }}}
+
+// onKey indica si el cursor está sobre una tecla, solo puede no estarlo si el layout no tiene teclas en
+// ninguna fila.
+func (m *Model) onKey() bool { // {{{
+	return m.cursor.col < len(m.keysOf(m.cursor.row))
+} // }}}
+
+// mark elige la tecla del cursor para intercambiarla, si ya había otra elegida las intercambia junto con sus
+// caracteres de AltGr.
+func (m *Model) mark() { // {{{
+	if !m.onKey() {
+		return
+	}
+	if m.marked == nil {
+		s := m.cursor
+		m.marked = &s
+		m.setStatus(i18n.T("Elige la otra tecla y pulsa espacio para intercambiarlas"), false)
+		return
+	}
+	a, b := *m.marked, m.cursor
+	m.marked = nil
+	if a == b {
+		m.setStatus("", false)
+		return
+	}
+	m.layout.Swap(m.rows[a.row], a.col, m.rows[b.row], b.col)
+	ka, kb := m.keysOf(a.row), m.keysOf(b.row)
+	m.changed(i18n.T("Se intercambiaron %s y %s", label(kb[b.col]), label(ka[a.col])))
+} // }}}
+
+// edit recibe los caracteres de la tecla del cursor, si el primero tiene mayúscula no espera el segundo.
+func (m *Model) edit(msg tea.KeyMsg) { // {{{
+	if msg.Type == tea.KeyEsc || msg.Type == tea.KeyCtrlC {
+		m.editing = false
+		m.setStatus(i18n.T("Edición cancelada"), false)
+		return
+	}
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || unicode.IsSpace(msg.Runes[0]) {
+		return
+	}
+	r := msg.Runes[0]
+	if m.lower == 0 {
+		m.lower = r
+		if upper := unicode.ToUpper(r); upper != r {
+			m.set(r, upper)
+			return
+		}
+		m.setStatus(i18n.T("Escribe el carácter con Shift de la tecla, esc cancela"), false)
+		return
+	}
+	m.set(m.lower, r)
+} // }}}
+
+// set cambia los caracteres de la tecla del cursor y avisa si ya estaban en otra tecla.
+func (m *Model) set(lower, upper rune) { // {{{
+	m.editing = false
+	if !m.onKey() {
+		return
+	}
+	keys := m.keysOf(m.cursor.row)
+	keys[m.cursor.col] = string([]rune{lower, upper})
+
+	var repeated []string
+	for r, row := range m.rows {
+		for c, k := range m.layout.Keys[row] {
+			if r == m.cursor.row && c == m.cursor.col {
+				continue
+			}
+			if strings.ContainsRune(k, lower) || strings.ContainsRune(k, upper) {
+				repeated = append(repeated, label(k))
+			}
+		}
+	}
+	m.changed(i18n.T("La tecla ahora es %s", label(keys[m.cursor.col])))
+	if len(repeated) > 0 {
+		m.setStatus(i18n.T("Los caracteres de %s ya están en %s", label(keys[m.cursor.col]), strings.Join(repeated, ", ")), true)
+	}
+} // }}}
+
+// restore devuelve la tecla del cursor y su carácter de AltGr a sus valores en el layout base.
+func (m *Model) restore() { // {{{
+	row := m.rows[m.cursor.row]
+	base := m.base.Keys[row]
+	if !m.onKey() || m.cursor.col >= len(base) {
+		return
+	}
+	m.keysOf(m.cursor.row)[m.cursor.col] = base[m.cursor.col]
+	if altgr := m.layout.AltGr[row]; m.cursor.col < len(altgr) {
+		altgr[m.cursor.col] = ""
+		if m.cursor.col < len(m.base.AltGr[row]) {
+			altgr[m.cursor.col] = m.base.AltGr[row][m.cursor.col]
+		}
+	}
+	m.changed(i18n.T("Se restauró %s", label(base[m.cursor.col])))
+} // }}}
+
+// save guarda el layout como layout del usuario, sin la fila del pulgar si todas sus teclas están vacías.
+func (m *Model) save() { // {{{
+	k := m.layout.Clone()
+	if !slices.ContainsFunc(k.Keys[kbd.Thumb], func(key string) bool { return key != "" }) {
+		delete(k.Keys, kbd.Thumb)
+	}
+	if err := kbd.SaveUserLayout(m.name, k); err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+	m.dirty, m.saved = false, true
+	m.setStatus(i18n.T("Layout guardado como %q", m.name), false)
+} // }}}
+
+func (m *Model) changed(status string) { // {{{
+	m.dirty = true
+	m.metrics = m.layout.Metrics(m.words)
+	m.setStatus(status, false)
+} // }}}
+
+func (m *Model) setStatus(status string, warn bool) { // {{{
+	m.status, m.warn = status, warn
+} // }}}
+
+func (m *Model) keysOf(row int) []string { // {{{
+	return m.layout.Keys[m.rows[row]]
+} // }}}
+
+func (m *Model) View() string { // {{{
+	sb := strings.Builder{}
+	title := i18n.T("󰌌 Editor de layouts: %s", m.name)
+	if m.dirty {
+		title += " *"
+	}
+	sb.WriteString(titleStyle.Render(title) + "\n\n")
+
+	size := kbd.FitSize(m.width)
+	sb.WriteString(m.layout.Render(size, func(row string, col int, s string) string {
+		if m.rows[m.cursor.row] == row && m.cursor.col == col {
+			return cursorOn + s + cursorOff
+		}
+		if m.marked != nil && m.rows[m.marked.row] == row && m.marked.col == col {
+			return markOn + s + markOff
+		}
+		return s
+	}))
+	sb.WriteString(m.layout.FingerLegend(size.Width()))
+
+	sb.WriteString("\n" + m.metricsView() + "\n\n")
+	if m.warn {
+		sb.WriteString(warnStyle.Render(m.status))
+	} else {
+		sb.WriteString(statusStyle.Render(m.status))
+	}
+	sb.WriteString("\n\n" + m.help.View(m.keys) + "\n")
+
+	return sb.String()
+} // }}}
+
+// metricsView muestra las métricas del layout con las palabras del idioma elegido.
+func (m *Model) metricsView() string { // {{{
+	percent := func(f float64) string {
+		return valueStyle.Render(fmt.Sprintf("%5.1f%%", f*100))
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf(
+		"%s %s   %s %s   %s %s\n",
+		labelStyle.Render(i18n.T("Fila base:")),
+		percent(m.metrics.HomeRow),
+		labelStyle.Render(i18n.T("Mismo dedo:")),
+		percent(m.metrics.SameFinger),
+		labelStyle.Render(i18n.T("Alternancia de manos:")),
+		percent(m.metrics.Alternation),
+	))
+
+	fingers := m.layout.UsedFingers()
+	loads := make([]string, 0, len(fingers))
+	for _, f := range fingers {
+		loads = append(loads, fmt.Sprintf("%s %s", labelStyle.Render(f.String()), percent(m.metrics.Load[f])))
+	}
+	sb.WriteString(labelStyle.Render(i18n.T("Carga por dedo:")) + " " + strings.Join(loads, " "))
+
+	if len(m.metrics.Missing) > 0 {
+		sb.WriteString("\n" + warnStyle.Render(i18n.T("Letras sin tecla: %s", string(m.metrics.Missing))))
+	}
+
+	return sb.String()
+} // }}}
+
+// label muestra los caracteres de una tecla, p.e. `[q Q]`.
+func label(k string) string { // {{{
+	r := []rune(k)
+	if len(r) != 2 {
+		return fmt.Sprintf("[%s]", k)
+	}
+
+	return fmt.Sprintf("[%c %c]", r[0], r[1])
+} // }}}
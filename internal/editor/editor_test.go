@@ -0,0 +1,62 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wrodriguez/thot/internal/kbd"
+)
+
+// press envía al editor las teclas `keys`, cada una con el nombre que usa bubbletea.
+func press(m *Model, keys ...string) { // {{{
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "up", "down", "left", "right":
+			msg = tea.KeyMsg{Type: map[string]tea.KeyType{
+				"up": tea.KeyUp, "down": tea.KeyDown, "left": tea.KeyLeft, "right": tea.KeyRight,
+			}[k]}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+		}
+		m.Update(msg)
+	}
+} // }}}
+
+func TestMissingRows(t *testing.T) { // {{{
+	// Un layout del usuario sin la fila de números ni la inferior
+	base := &kbd.Keyboard{Type: "ansi", Keys: map[string][]string{
+		kbd.Row2: {"qQ", "wW"},
+		kbd.Row3: {"aA"},
+	}}
+	m := New("prueba", base, nil)
+	if got := m.rows[m.cursor.row]; got != kbd.Row2 {
+		t.Fatalf("el cursor empieza en la fila %s, se esperaba %s", got, kbd.Row2)
+	}
+
+	press(m, "up", "enter", "x", "r")
+	if got := m.Layout().Keys[kbd.Row2][0]; got != "qQ" {
+		t.Errorf("la tecla es %q después de restaurarla, se esperaba qQ", got)
+	}
+	press(m, "right", "down", "down", "down", "enter", "ñ")
+	if m.rows[m.cursor.row] != kbd.Row3 || m.cursor.col != 0 {
+		t.Errorf("el cursor quedó en %+v, se esperaba la primera tecla de %s", m.cursor, kbd.Row3)
+	}
+	if got := m.Layout().Keys[kbd.Row3][0]; got != "ñÑ" {
+		t.Errorf("la tecla es %q, se esperaba ñÑ", got)
+	}
+	press(m, " ", "up", " ")
+	if got := m.Layout().Keys[kbd.Row2][0]; got != "ñÑ" {
+		t.Errorf("la tecla intercambiada es %q, se esperaba ñÑ", got)
+	}
+} // }}}
+
+func TestEmptyLayout(t *testing.T) { // {{{
+	m := New("vacío", &kbd.Keyboard{Type: "ansi", Keys: map[string][]string{}}, nil)
+	press(m, "down", "right", "enter", "a", " ", " ", "r", "up")
+	if m.Dirty() {
+		t.Error("el layout sin teclas cambió")
+	}
+} // }}}
@@ -0,0 +1,109 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/paths"
+	"gitlab.com/tozd/go/errors"
+)
+
+const FileName = "history.jsonl"
+
+// IDFormat es el formato de fecha usado como identificador de las sesiones.
+const IDFormat = "20060102-150405"
+
+// Session es el resultado de una sesión de entrenamiento terminada.
+type Session struct {
+	ID      string    `json:"id"`
+	Start   time.Time `json:"start"`
+	Seconds float64   `json:"seconds"`
+	Layout  string    `json:"layout"`
+	Lang    string    `json:"lang"`
+	Rows    []string  `json:"rows"`
+	Mode    string    `json:"mode"`
+	// Challenge es el código de desafío de la sesión, las sesiones con el mismo código usaron el mismo texto
+	Challenge string  `json:"challenge,omitempty"`
+	Chars     int     `json:"chars"`
+	Mistakes  int     `json:"mistakes"`
+	WPM       float64 `json:"wpm"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+// Path devuelve la ruta del archivo con el historial de sesiones.
+func Path() (string, errors.E) { // {{{
+	base, err := paths.Profile(paths.State)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, FileName), nil
+} // }}}
+
+// Load lee todas las sesiones del historial en el orden en que fueron guardadas.
+func Load() ([]Session, errors.E) { // {{{
+	var sessions []Session = make([]Session, 0)
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	f, e := os.Open(path)
+	if os.IsNotExist(e) {
+		return sessions, nil
+	}
+	if e != nil {
+		return nil, errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo leer el historial")),
+			"path",
+			path,
+		)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Session
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			// Una línea dañada no debe impedir leer el resto del historial
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+} // }}}
+
+// Append agrega una sesión al final del historial.
+func Append(s Session) errors.E { // {{{
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	line, e := json.Marshal(s)
+	if e != nil {
+		return errors.WithMessage(e, i18n.T("No se pudo guardar la sesión"))
+	}
+
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	f, e := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if e != nil {
+		return errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo guardar la sesión")),
+			"path",
+			path,
+		)
+	}
+	defer f.Close()
+
+	if _, e := f.Write(append(line, '\n')); e != nil {
+		return errors.WithMessage(e, i18n.T("No se pudo guardar la sesión"))
+	}
+
+	return nil
+} // }}}
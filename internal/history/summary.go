@@ -0,0 +1,72 @@
+package history
+
+import "time"
+
+// Goal es la meta diaria de práctica, los valores en cero no se toman en cuenta.
+type Goal struct {
+	Minutes  int     `toml:"minutes"`
+	Sessions int     `toml:"sessions"`
+	WPM      float64 `toml:"wpm"`
+}
+
+// Summary resume las sesiones de un día.
+type Summary struct {
+	Day      time.Time
+	Sessions int
+	Minutes  float64
+	BestWPM  float64
+}
+
+// Empty indica si la meta no tiene ningún objetivo definido.
+func (g Goal) Empty() bool { // {{{
+	return g.Minutes <= 0 && g.Sessions <= 0 && g.WPM <= 0
+} // }}}
+
+// Met indica si el resumen de un día cumple con todos los objetivos de la meta. Si la meta está vacía basta
+// con haber practicado al menos una sesión.
+func (g Goal) Met(s Summary) bool { // {{{
+	if g.Empty() {
+		return s.Sessions > 0
+	}
+
+	return s.Minutes >= float64(g.Minutes) && s.Sessions >= g.Sessions && s.BestWPM >= g.WPM
+} // }}}
+
+// Day devuelve el inicio (medianoche, hora local) del día de `t`.
+func Day(t time.Time) time.Time { // {{{
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+} // }}}
+
+// Summarize agrupa las sesiones que comenzaron el mismo día que `day`.
+func Summarize(sessions []Session, day time.Time) Summary { // {{{
+	day = Day(day)
+	s := Summary{Day: day}
+	for _, session := range sessions {
+		if !Day(session.Start).Equal(day) {
+			continue
+		}
+		s.Sessions++
+		s.Minutes += session.Seconds / 60
+		s.BestWPM = max(s.BestWPM, session.WPM)
+	}
+
+	return s
+} // }}}
+
+// Streak cuenta los días consecutivos en los que se cumplió la meta terminando en `now`. Si la meta de hoy
+// aún no se cumple, la racha se cuenta desde ayer para no perderla antes de terminar el día.
+func Streak(sessions []Session, g Goal, now time.Time) int { // {{{
+	day := Day(now)
+	if !g.Met(Summarize(sessions, day)) {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for g.Met(Summarize(sessions, day)) {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return streak
+} // }}}
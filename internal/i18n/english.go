@@ -0,0 +1,652 @@
+package i18n
+
+// english contiene la traducción al inglés de los mensajes de la interfaz.
+var english = map[string]string{
+	"Un pequeño entrenador de teclado": "A small keyboard trainer",
+
+	"El idioma de la interfaz, acepta solo los valores `es` o `en`": "The interface language, accepts only the values `es` or `en`",
+
+	"Lista los layouts que pueden ser utilizados por Thot": "Lists the layouts that can be used by Thot",
+
+	"Imprime el layout de teclado seleccionado": "Prints the selected keyboard layout",
+
+	"El nombre del layout, se puede consultar la lista de layouts disponibles a través del comando `thot list`": "The layout name, the list of available layouts can be queried with the `thot list` command",
+
+	"Practica con Thot para mejorar el método de mecanografía": "Practice with Thot to improve your typing technique",
+
+	"El idioma a mostrar las palabras, acepta solo los valores `spa` o `eng`": "The language of the words, accepts only the values `spa` or `eng`",
+
+	"La longitud de la sesión, cantidad de palabras en el modo `words` o segundos en el modo `time`": "The session length, number of words in `words` mode or seconds in `time` mode",
+
+	"El modo de la sesión, acepta solo los valores `words` o `time`": "The session mode, accepts only the values `words` or `time`",
+
+	"Consulta o modifica la configuración de Thot": "Queries or modifies the Thot configuration",
+
+	"Muestra el valor de una clave de la configuración": "Shows the value of a configuration key",
+
+	"Modifica el valor de una clave de la configuración": "Modifies the value of a configuration key",
+
+	"El nuevo valor de la clave": "The new value of the key",
+
+	"Muestra la ruta del archivo de configuración": "Shows the path of the configuration file",
+
+	"Copiando Base de Datos...": "Copying Database...",
+
+	"No se pudo crear la Base de Datos": "Could not create the Database",
+
+	"No se pudo obtener el directorio HOME": "Could not get the HOME directory",
+
+	"No se pudo copiar la Base de Datos": "Could not copy the Database",
+
+	"Base de datos copiada en ": "Database copied to ",
+
+	"LAYOUTS SOPORTADOS POR THOT": "LAYOUTS SUPPORTED BY THOT",
+
+	"Layout %q no encontrado": "Layout %q not found",
+
+	"El modo %q no es valido, se acepta `words` o `time`": "The mode %q is not valid, accepted values are `words` or `time`",
+
+	"La longitud %d debe ser un número entero positivo": "The length %d must be a positive integer",
+
+	"No se pudo conectar a la Base de Datos": "Could not connect to the Database",
+
+	"No se pudo obtener las palabras": "Could not get the words",
+
+	"󰌓  Layout:": "󰌓  Layout:",
+
+	"  Idioma:": "  Language:",
+
+	"󰠷  Filas:": "󰠷  Rows:",
+
+	"󱎫  Duración: ": "󱎫  Duration: ",
+
+	"󱀍  Cantidad de palabras: ": "󱀍  Number of words: ",
+
+	"󰘝  Letras a practicar: ": "󰘝  Letters to practice: ",
+
+	"  Para comenzar pulse Enter ...": "  Press Enter to start ...",
+
+	"El idioma %q no es valido": "The language %q is not valid",
+
+	"No se pudo leer el archivo de configuración": "Could not read the configuration file",
+
+	"No se pudo generar el archivo de configuración": "Could not generate the configuration file",
+
+	"No se pudo guardar el archivo de configuración": "Could not save the configuration file",
+
+	"La longitud %q debe ser un número entero positivo": "The length %q must be a positive integer",
+
+	"El idioma de la interfaz %q no es valido, se acepta `es` o `en`": "The interface language %q is not valid, accepted values are `es` or `en`",
+
+	"El valor %q no es un booleano": "The value %q is not a boolean",
+
+	"La clave %q no existe": "The key %q does not exist",
+
+	"La clave %q debe tener la forma `theme.<estilo>.<atributo>`": "The key %q must have the form `theme.<style>.<attribute>`",
+
+	"El estilo %q no existe": "The style %q does not exist",
+
+	"El atributo %q no es valido, se acepta `foreground`, `background`, `border`, `bold` o `italic`": "The attribute %q is not valid, accepted values are `foreground`, `background`, `border`, `bold` or `italic`",
+
+	"No se pudo encontrar la base de datos": "Could not find the database",
+
+	"No se pudo conectar a la base de datos": "Could not connect to the database",
+
+	"No se pudo obtener la palabra": "Could not get the word",
+
+	"󰌓 Nombre: ": "󰌓 Name: ",
+
+	"󰌓 Tipo: ": "󰌓 Type: ",
+
+	"El tema contiene estilos desconocidos": "The theme contains unknown styles",
+
+	"Salir": "Quit",
+
+	" 󰀬 Total caracteres: %d": " 󰀬 Total characters: %d",
+
+	" Tiempo: %.2fs(%.2fm)": " Time: %.2fs(%.2fm)",
+
+	"󰚌 errores: %d": "󰚌 mistakes: %d",
+
+	"󰌓 WPM: %.2f": "󰌓 WPM: %.2f",
+
+	"󰓾 Precisión: %.2f%%": "󰓾 Accuracy: %.2f%%",
+
+	" Inicio: ": " Start: ",
+
+	"  󱎫 Restante: %s": "  󱎫 Remaining: %s",
+
+	"Presione Enter para continuar...": "Press Enter to continue...",
+
+	"󰃭  Hoy, %s": "󰃭  Today, %s",
+
+	"󰔛  Sesiones:": "󰔛  Sessions:",
+
+	"󱎫  Minutos:": "󱎫  Minutes:",
+
+	"󰌓  Mejor WPM:": "󰌓  Best WPM:",
+
+	"󰈸  Racha:": "󰈸  Streak:",
+
+	"%d día(s)": "%d day(s)",
+
+	"  ¡Meta del día cumplida!": "  Daily goal reached!",
+
+	"No se pudo leer el historial": "Could not read the history",
+
+	"No se pudo guardar la sesión": "Could not save the session",
+
+	"Muestra el avance de la meta diaria y la racha de práctica": "Shows the daily goal progress and the practice streak",
+
+	"La meta %q debe ser un número entero mayor o igual a cero": "The goal %q must be an integer greater than or equal to zero",
+
+	"La meta %q debe ser un número mayor o igual a cero": "The goal %q must be a number greater than or equal to zero",
+
+	"Primera sesión a 40 WPM en %s": "First 40 WPM session on %s",
+
+	"Primera sesión a 60 WPM en %s": "First 60 WPM session on %s",
+
+	"Primera sesión a 80 WPM en %s": "First 80 WPM session on %s",
+
+	"Sesión con 100% de precisión": "Session with 100% accuracy",
+
+	"Racha de 7 días": "7-day streak",
+
+	"Todas las teclas de %s desbloqueadas": "All keys of %s unlocked",
+
+	"No se pudo leer el archivo de logros": "Could not read the achievements file",
+
+	"No se pudo guardar el archivo de logros": "Could not save the achievements file",
+
+	"󰸗  Logros": "󰸗  Achievements",
+
+	"Aún no tienes logros, ¡a practicar!": "No achievements yet, time to practice!",
+
+	"󰸗  ¡Nuevo logro! %s": "󰸗  New achievement! %s",
+
+	"Lista los logros desbloqueados": "Lists the unlocked achievements",
+
+	"El nombre de perfil %q no es valido, solo se aceptan letras, números, `-` y `_`": "The profile name %q is not valid, only letters, digits, `-` and `_` are accepted",
+
+	"El perfil %q ya existe": "The profile %q already exists",
+
+	"No se pudo crear el perfil": "Could not create the profile",
+
+	"Perfil %q creado en %s": "Profile %q created at %s",
+
+	"El perfil %q no se puede eliminar": "The profile %q cannot be deleted",
+
+	"El perfil %q no existe": "The profile %q does not exist",
+
+	"Se eliminará el perfil %q y todo su historial. ¿Seguro? [s/N] ": "The profile %q and all its history will be deleted. Are you sure? [y/N] ",
+
+	"No se pudo eliminar el perfil": "Could not delete the profile",
+
+	"Perfil %q eliminado": "Profile %q deleted",
+
+	"El perfil %q no existe, se puede crear con `thot profile create %s`": "The profile %q does not exist, it can be created with `thot profile create %s`",
+
+	"El perfil con el que se guarda el historial, progreso y configuración": "The profile used to store history, progress and configuration",
+
+	"Administra los perfiles de usuario": "Manages user profiles",
+
+	"Lista los perfiles existentes": "Lists the existing profiles",
+
+	"Crea un perfil nuevo": "Creates a new profile",
+
+	"El nombre del perfil": "The profile name",
+
+	"Elimina un perfil y todos sus archivos": "Deletes a profile and all its files",
+
+	"No pedir confirmación": "Do not ask for confirmation",
+
+	"No se pudo leer la lista de perfiles": "Could not read the list of profiles",
+
+	"El origen de palabras %q no existe": "The word source %q does not exist",
+
+	"Thot fue compilado sin soporte para SQLite (CGO_ENABLED=0)": "Thot was built without SQLite support (CGO_ENABLED=0)",
+
+	"El directorio no contiene listas de palabras (`spa.txt` o `eng.txt`)": "The directory does not contain word lists (`spa.txt` or `eng.txt`)",
+
+	"No se pudo abrir la lista de palabras": "Could not open the word list",
+
+	"No se pudo descomprimir la lista de palabras": "Could not decompress the word list",
+
+	"No se pudo leer la lista de palabras": "Could not read the word list",
+
+	"El origen de las palabras: `auto`, `sqlite`, `embedded`, `user` o la ruta a una lista de palabras": "The word source: `auto`, `sqlite`, `embedded`, `user` or the path to a word list",
+
+	"La clave a consultar: `layout`, `lang`, `rows`, `length`, `mode`, `source`, `ui_lang`, `goal.<objetivo>` o `theme.<estilo>.<atributo>`": "The key to query: `layout`, `lang`, `rows`, `length`, `mode`, `source`, `ui_lang`, `goal.<target>` or `theme.<style>.<attribute>`",
+
+	"La clave a modificar: `layout`, `lang`, `rows`, `length`, `mode`, `source`, `ui_lang`, `goal.<objetivo>` o `theme.<estilo>.<atributo>`": "The key to modify: `layout`, `lang`, `rows`, `length`, `mode`, `source`, `ui_lang`, `goal.<target>` or `theme.<style>.<attribute>`",
+
+	"No se pudo leer la estructura de la base de datos": "Could not read the database structure",
+
+	"No se pudo actualizar la base de datos": "Could not update the database",
+
+	"Ninguna palabra se puede escribir solo con las teclas seleccionadas": "No word can be typed using only the selected keys",
+
+	"No se pudo crear la copia de seguridad": "Could not create the backup",
+
+	"Instala o actualiza la base de datos de palabras de Thot": "Installs or upgrades the Thot word database",
+
+	"Muestra la versión y las tablas de la base de datos de palabras": "Shows the version and tables of the word database",
+
+	"Copia de seguridad creada en ": "Backup created at ",
+
+	"  Ruta:": "  Path:",
+
+	"La base de datos no está instalada, se puede instalar con `thot db`": "The database is not installed, it can be installed with `thot db`",
+
+	"  Versión:": "  Version:",
+
+	"  Tablas:": "  Tables:",
+
+	"usuario": "user",
+
+	"incluida": "bundled",
+
+	"󰁯  Copias de seguridad:": "󰁯  Backups:",
+
+	"Base de datos actualizada en ": "Database upgraded at ",
+
+	"El directorio de los diccionarios, tiene prioridad sobre `THOT_HOME` y `XDG_DATA_HOME`": "The dictionaries directory, takes precedence over `THOT_HOME` and `XDG_DATA_HOME`",
+
+	"El código de desafío no es valido": "The challenge code is not valid",
+
+	"La semilla para elegir las palabras, la misma semilla con los mismos parámetros produce el mismo texto": "The seed used to pick the words, the same seed with the same parameters produces the same text",
+
+	"Un código de desafío compartido por otra persona, reemplaza el layout, idioma, filas, modo, longitud, origen y semilla": "A challenge code shared by someone else, replaces the layout, language, rows, mode, length, source and seed",
+
+	"󰒲  Semilla:": "󰒲  Seed:",
+
+	"󰆏  Desafío:": "󰆏  Challenge:",
+
+	"La velocidad %g debe ser un número positivo": "The speed %g must be a positive number",
+
+	"No hay sesiones en el historial": "There are no sessions in the history",
+
+	"󰑖  Sesión:": "󰑖  Session:",
+
+	"󰌌  Pulsaciones:": "󰌌  Keystrokes:",
+
+	"No se pudo repetir la sesión": "Could not replay the session",
+
+	"No se pudo guardar el registro de la sesión": "Could not save the session log",
+
+	"La sesión %q no tiene un registro de pulsaciones": "The session %q has no keystroke log",
+
+	"No se pudo leer el registro de la sesión": "Could not read the session log",
+
+	"El registro de la sesión no es valido": "The session log is not valid",
+
+	"El registro está vacío": "The log is empty",
+
+	"Versión %d no soportada": "Unsupported version %d",
+
+	"Se esperaban 4 campos": "4 fields were expected",
+
+	"Repite una sesión de entrenamiento a partir de sus pulsaciones": "Replays a training session from its keystrokes",
+
+	"El identificador de la sesión (p.e. `20240131-183000`) o `last` para la más reciente": "The session id (e.g. `20240131-183000`) or `last` for the most recent one",
+
+	"La velocidad de la repetición, `2` es el doble de rápido": "The replay speed, `2` is twice as fast",
+
+	"  󰑖 Repetición x%g": "  󰑖 Replay x%g",
+
+	"Compite contra un fantasma que escribe a esta velocidad en WPM": "Race against a ghost typing at this speed in WPM",
+
+	"Compite contra la mejor sesión anterior con el mismo desafío": "Race against your best previous session on the same challenge",
+
+	"󰊠  Fantasma:": "󰊠  Ghost:",
+
+	"sesión %s (%.2f WPM)": "session %s (%.2f WPM)",
+
+	"No hay una sesión anterior con este desafío, se entrena sin fantasma": "There is no previous session with this challenge, training without a ghost",
+
+	"󰊠  El fantasma te ganó": "󰊠  The ghost beat you",
+
+	"󰊠  Le ganaste al fantasma": "󰊠  You beat the ghost",
+
+	"󰩠  Los participantes se pueden unir con:": "󰩠  Participants can join with:",
+
+	"󰀄  %s se unió a la carrera": "󰀄  %s joined the race",
+
+	"  Para comenzar la carrera pulse Enter ...": "  Press Enter to start the race ...",
+
+	"󰩠  Conectado a %s, esperando a que comience la carrera ...": "󰩠  Connected to %s, waiting for the race to start ...",
+
+	"No se pudo mostrar la carrera": "Could not display the race",
+
+	"La carrera terminó sin una clasificación": "The race ended without a ranking",
+
+	"No se pudo conectar con la carrera": "Could not connect to the race",
+
+	"Se perdió la conexión con la carrera": "Lost the connection to the race",
+
+	"La carrera ya comenzó": "The race has already started",
+
+	"No se pudo iniciar la carrera": "Could not start the race",
+
+	"anónimo": "anonymous",
+
+	"Esperando a que terminen los demás participantes...": "Waiting for the other participants to finish...",
+
+	"󰔸  Clasificación": "󰔸  Ranking",
+
+	" no terminó (%d/%d)": " did not finish (%d/%d)",
+
+	"Carreras de mecanografía en la red local": "Typing races on the local network",
+
+	"Inicia una carrera y espera a los demás participantes": "Starts a race and waits for the other participants",
+
+	"La dirección donde se escucha a los participantes": "The address to listen for participants on",
+
+	"La cantidad de palabras de la carrera": "The number of words in the race",
+
+	"El nombre con el que se participa en la carrera": "The name used in the race",
+
+	"Se une a la carrera de otro equipo de la red local": "Joins a race hosted by another computer on the local network",
+
+	"La dirección del anfitrión, p.e. `192.168.1.10:7878`": "The host address, e.g. `192.168.1.10:7878`",
+
+	"󰖟  Panel de progreso en": "󰖟  Progress dashboard at",
+
+	"  Para terminar pulse Ctrl+C": "  Press Ctrl+C to stop",
+
+	"No se pudo iniciar el panel web": "Could not start the web dashboard",
+
+	"Muestra el historial de progreso en un panel web local": "Shows the progress history in a local web dashboard",
+
+	"La dirección del panel web": "The web dashboard address",
+
+	"Se debe indicar el nombre del nuevo layout con `--as`": "The name of the new layout must be given with `--as`",
+
+	"No se pudo abrir el editor de layouts": "Could not open the layout editor",
+
+	"Se descartaron los cambios sin guardar": "Unsaved changes were discarded",
+
+	"󰌌  Layout:": "󰌌  Layout:",
+
+	"󰉋  Archivo:": "󰉋  File:",
+
+	"Mover": "Move",
+
+	"espacio": "space",
+
+	"Intercambiar": "Swap",
+
+	"Cambiar caracteres": "Change characters",
+
+	"Restaurar tecla": "Restore key",
+
+	"Guardar": "Save",
+
+	"Hay cambios sin guardar, vuelve a pulsar esc para salir": "There are unsaved changes, press esc again to quit",
+
+	"Escribe el carácter normal de la tecla, esc cancela": "Type the key's normal character, esc cancels",
+
+	"Elige la otra tecla y pulsa espacio para intercambiarlas": "Choose the other key and press space to swap them",
+
+	"Se intercambiaron %s y %s": "Swapped %s and %s",
+
+	"Edición cancelada": "Edit cancelled",
+
+	"Escribe el carácter con Shift de la tecla, esc cancela": "Type the key's Shift character, esc cancels",
+
+	"La tecla ahora es %s": "The key is now %s",
+
+	"Los caracteres de %s ya están en %s": "The characters of %s are already on %s",
+
+	"Se restauró %s": "Restored %s",
+
+	"Layout guardado como %q": "Layout saved as %q",
+
+	"󰌌 Editor de layouts: %s": "󰌌 Layout editor: %s",
+
+	"Fila base:": "Home row:",
+
+	"Mismo dedo:": "Same finger:",
+
+	"Alternancia de manos:": "Hand alternation:",
+
+	"Carga por dedo:": "Finger load:",
+
+	"Letras sin tecla: %s": "Letters without a key: %s",
+
+	"Meñique": "Pinky",
+
+	"Anular": "Ring",
+
+	"Corazon": "Middle",
+
+	"Indice": "Index",
+
+	"El nombre %q no es válido, solo puede tener letras, números, `_` y `-`": "The name %q is not valid, it can only contain letters, digits, `_` and `-`",
+
+	"El layout %q viene incluido en Thot, se debe guardar con otro nombre": "The layout %q is bundled with Thot, it must be saved with another name",
+
+	"No se pudo generar el archivo de layouts": "Could not generate the layouts file",
+
+	"No se pudo guardar el archivo de layouts": "Could not save the layouts file",
+
+	"No se pudo leer el archivo de layouts": "Could not read the layouts file",
+
+	"El archivo de layouts no tiene un formato válido": "The layouts file does not have a valid format",
+
+	"Crea y modifica layouts del usuario": "Creates and modifies user layouts",
+
+	"Edita un layout de forma interactiva y lo guarda como layout del usuario": "Edits a layout interactively and saves it as a user layout",
+
+	"El layout del que se parte, se puede consultar la lista de layouts disponibles a través del comando `thot list`": "The layout to start from, the list of available layouts can be checked with the `thot list` command",
+
+	"El nombre con el que se guarda el layout editado": "The name under which the edited layout is saved",
+
+	"El idioma de las palabras con las que se calculan las métricas, acepta solo los valores `spa` o `eng`": "The language of the words used to compute the metrics, only accepts `spa` or `eng`",
+
+	"El formato %q no es válido, acepta solo los valores %s": "The format %q is not valid, only accepts %s",
+
+	"No se pudo leer el archivo generado": "Could not read the generated file",
+
+	"El archivo generado tiene símbolos que no se pudieron leer": "The generated file has symbols that could not be read",
+
+	"El archivo generado no coincide con el layout": "The generated file does not match the layout",
+
+	"La fila tiene más teclas que el teclado": "The row has more keys than the keyboard",
+
+	"El archivo no tiene un bloque `xkb_symbols`": "The file has no `xkb_symbols` block",
+
+	"El archivo no define ninguna tecla": "The file does not define any key",
+
+	"Wayland (libxkbcommon), solo para el usuario actual:": "Wayland (libxkbcommon), for the current user only:",
+
+	"  y usar `%s` como layout en la configuración del compositor, p.e. `xkb_layout %s` en sway": "  and use `%s` as the layout in the compositor settings, e.g. `xkb_layout %s` in sway",
+
+	"X11, para todo el sistema:": "X11, system wide:",
+
+	"Para volver al layout anterior: `setxkbmap us` o el layout que se usaba": "To go back to the previous layout: `setxkbmap us` or the layout in use before",
+
+	"Genera el archivo del layout para instalarlo en el sistema operativo": "Generates the layout file to install it in the operating system",
+
+	"El archivo o directorio donde se guarda el layout, sin este valor se escribe en la salida estándar": "The file or directory where the layout is saved, without it the layout is written to standard output",
+
+	"No se pudo guardar el archivo exportado": "Could not save the exported file",
+
+	"No se pudo generar el archivo de macOS": "Could not generate the macOS file",
+
+	"El archivo no es un `.keylayout` válido": "The file is not a valid `.keylayout`",
+
+	"En macOS:": "On macOS:",
+
+	"  Cerrar la sesión y agregar `Thot (%s)` en `Ajustes del Sistema > Teclado > Fuentes de entrada`, en la categoría `Otros`": "  Log out and add `Thot (%s)` in `System Settings > Keyboard > Input Sources`, under the `Others` category",
+
+	"En Windows:": "On Windows:",
+
+	"  1. Abrir %s con Microsoft Keyboard Layout Creator 1.4": "  1. Open %s with Microsoft Keyboard Layout Creator 1.4",
+
+	"  2. Elegir `Project > Build DLL and Setup Package`": "  2. Choose `Project > Build DLL and Setup Package`",
+
+	"  3. Ejecutar el `setup.exe` generado y reiniciar la sesión": "  3. Run the generated `setup.exe` and log in again",
+
+	"  4. Agregar `Thot (%s)` en `Configuración > Hora e idioma > Idioma`": "  4. Add `Thot (%s)` in `Settings > Time & language > Language`",
+
+	"El formato del archivo, acepta solo los valores %s": "The file format, only accepts %s",
+
+	"La placa %q no es válida, acepta solo los valores %s": "The board %q is not valid, only accepts the values %s",
+
+	"La placa %q es para layouts %s y el layout es %s": "The board %q is for %s layouts and the layout is %s",
+
+	"La tecla %s no tiene lugar en la placa": "The key %s has no place on the board",
+
+	"Con Shift la tecla %s produce %q en lugar de %q": "With Shift the key %s produces %q instead of %q",
+
+	"La tecla %s no existe en el layout de Estados Unidos": "The key %s does not exist in the United States layout",
+
+	"KMonad solo admite las placas `60_ansi` y `60_iso`": "KMonad only supports the `60_ansi` and `60_iso` boards",
+
+	"En QMK:": "In QMK:",
+
+	"  1. Copiar %s a `keyboards/<teclado>/keymaps/thot/keymap.c`": "  1. Copy %s to `keyboards/<keyboard>/keymaps/thot/keymap.c`",
+
+	"  3. Configurar el sistema operativo con el layout de Estados Unidos": "  3. Set the operating system to the United States layout",
+
+	"En ZMK:": "In ZMK:",
+
+	"  1. Reemplazar `config/<placa>.keymap` del repositorio zmk-config con %s": "  1. Replace `config/<board>.keymap` in the zmk-config repository with %s",
+
+	"  2. Subir el cambio para que GitHub Actions compile el firmware y grabarlo en el teclado": "  2. Push the change so GitHub Actions builds the firmware and flash it to the keyboard",
+
+	"En KMonad:": "In KMonad:",
+
+	"  1. Cambiar `device-file` en %s por el teclado a reasignar (`ls /dev/input/by-id`)": "  1. Change `device-file` in %s to the keyboard to remap (`ls /dev/input/by-id`)",
+
+	"La placa de los formatos de firmware, acepta solo los valores %s": "The board for the firmware formats, only accepts the values %s",
+
+	"El formato %q no usa placas, `--board` solo se usa con %s": "The format %q does not use boards, `--board` is only used with %s",
+
+	"No se puede saber el formato del archivo, se debe indicar con `--format` uno de los valores %s": "The file format cannot be determined, use `--format` with one of the values %s",
+
+	"El formato %q no se puede importar, acepta solo los valores %s": "The format %q cannot be imported, only accepts the values %s",
+
+	"El archivo debe tener 3 o 4 filas de teclas": "The file must have 3 or 4 rows of keys",
+
+	"El archivo no es un JSON de keyboard-layout-editor válido": "The file is not a valid keyboard-layout-editor JSON",
+
+	"Importa un layout de otra herramienta y lo guarda como layout del usuario": "Imports a layout from another tool and saves it as a user layout",
+
+	"El archivo del layout a importar": "The layout file to import",
+
+	"El formato del archivo, acepta solo los valores %s. Sin este valor se elige según la extensión del archivo": "The file format, only accepts the values %s. Without this value it is chosen from the file extension",
+
+	"El nombre con el que se guarda el layout importado": "The name the imported layout is saved with",
+
+	"Se debe indicar el nombre del nuevo layout con `--name`": "The name of the new layout must be given with `--name`",
+
+	"No se pudo leer el archivo del layout": "Could not read the layout file",
+
+	"Teclas que no se pudieron asignar:": "Keys that could not be mapped:",
+
+	"El formato del diagrama, acepta solo los valores %s. Sin este valor se muestra en la terminal": "The diagram format, only accepts the values %s. Without this value it is shown in the terminal",
+
+	"El archivo o directorio donde se guarda el diagrama, sin este valor se escribe en la salida estándar": "The file or directory where the diagram is saved, without this value it is written to standard output",
+
+	"No se pudo guardar el diagrama": "Could not save the diagram",
+
+	"El ancho de la consola es demasiado corto para mostrar el diagrama. Se recomienda al menos %d caracteres de ancho.": "The console width is too short to show the diagram. At least %d characters wide is recommended.",
+
+	"Las filas a mostrar, acepta solo los valores `row1`, `row2`, `row3`, `row4`, `thumb` o `all` para mostrar todas": "The rows to practice, accepts only the values `row1`, `row2`, `row3`, `row4`, `thumb` or `all` for every row",
+
+	"El tipo de teclado físico del layout, acepta solo los valores %s": "The physical keyboard type of the layout, accepts only the values %s",
+
+	"Los valores válidos para las filas son `row1`, `row2`, `row3`, `row4` o `thumb`": "Valid values for rows are `row1`, `row2`, `row3`, `row4` or `thumb`",
+
+	"Pulgar": "Thumb",
+
+	"El tipo de teclado %q no es válido, acepta solo los valores %s": "The keyboard type %q is not valid, accepts only the values %s",
+
+	"Un layout %s no se puede usar en un teclado %s": "A %s layout cannot be used on a %s keyboard",
+
+	"El layout tiene teclas del pulgar y los teclados %s no las tienen": "The layout has thumb keys and %s keyboards do not have them",
+
+	"La tecla del pulgar %s no existe en el formato %q": "The thumb key %s does not exist in the %q format",
+
+	"Teclas muertas (subrayadas): %s": "Dead keys (underlined): %s",
+
+	"Espacio": "Space",
+
+	"La capa de caracteres que se muestra, acepta solo los valores %s. Sin este valor se muestra `base`": "The character layer to show, accepts only the values %s. Without this value `base` is shown",
+
+	"La capa %q no es válida, acepta solo los valores %s": "The layer %q is not valid, accepts only the values %s",
+
+	"Los caracteres con AltGr y las teclas muertas no se exportan en el formato %q": "AltGr characters and dead keys are not exported in the %q format",
+
+	"Para escribir %s: %s": "To type %s: %s",
+
+	"El layout está repetido en el archivo, solo se usa el último": "The layout is repeated in the file, only the last one is used",
+
+	"El layout no tiene un formato válido: %s": "The layout does not have a valid format: %s",
+
+	"El archivo debe ser un objeto con los layouts por nombre": "The file must be an object with the layouts by name",
+
+	"El archivo de layouts no tiene un formato válido en la línea %d": "The layouts file does not have a valid format on line %d",
+
+	"La fila %q no existe, las filas son %s": "The row %q does not exist, the rows are %s",
+
+	"Falta la fila %s": "The row %s is missing",
+
+	"La fila %s solo existe en los teclados matriciales y el layout es %s": "The row %s only exists on matrix keyboards and the layout is %s",
+
+	"La fila %s tiene %d teclas y los teclados %s tienen %d": "The row %s has %d keys and %s keyboards have %d",
+
+	"La %s tiene %q, debe tener exactamente dos caracteres": "The %s has %q, it must have exactly two characters",
+
+	"El carácter %q está en la %s y en la %s": "The character %q is on the %s and on the %s",
+
+	"La %s tiene dos veces el carácter %q": "The %s has the character %q twice",
+
+	"Faltan las letras %s": "The letters %s are missing",
+
+	"La fila %s de AltGr no existe en el layout": "The AltGr row %s does not exist in the layout",
+
+	"La fila %s de AltGr tiene %d teclas y la del layout %d": "The AltGr row %s has %d keys and the layout row %d",
+
+	"La %s de AltGr tiene %q, debe tener como máximo dos caracteres": "The AltGr %s has %q, it must have at most two characters",
+
+	"La tecla muerta %q no se conoce, acepta solo %s": "The dead key %q is unknown, only %s are accepted",
+
+	"La tecla muerta %q no está en el layout": "The dead key %q is not in the layout",
+
+	"tecla %d de la fila %s": "key %d in row %s",
+
+	"Revisa los layouts y termina con error si alguno tiene problemas": "Checks the layouts and exits with an error if any has problems",
+
+	"El nombre del layout a revisar, sin este valor se revisan todos": "The name of the layout to check, without this value all of them are checked",
+
+	"El archivo de layouts a revisar, con el formato de `layout.json`. Sin este valor se revisan los layouts incluidos en Thot y los del usuario": "The layouts file to check, in the `layout.json` format. Without this value the layouts included in Thot and the user layouts are checked",
+
+	"Se encontraron %d problemas en %d layouts": "Found %d problems in %d layouts",
+
+	"Los %d layouts no tienen problemas": "The %d layouts have no problems",
+
+	"Los layouts incluidos en Thot no tienen un formato válido": "The layouts included in Thot do not have a valid format",
+
+	"Las metas deben ser mayores o iguales a cero": "The goals must be greater than or equal to zero",
+
+	"No se escribió ningún carácter, la sesión no se guardó": "No character was typed, the session was not saved",
+
+	"El origen de palabras %q no se puede compartir en un código de desafío": "The word source %q cannot be shared in a challenge code",
+
+	"El origen %q solo existe en este equipo, la sesión no tiene código de desafío": "The source %q only exists on this computer, the session has no challenge code",
+
+	"El identificador de sesión %q no es valido": "The session id %q is not valid",
+
+	"El origen de palabras %q no es válido, acepta %s o la ruta a una lista de palabras": "The word source %q is not valid, it accepts %s or the path to a word list",
+
+	"Hay migraciones pendientes, se aplican con `thot db`": "There are pending migrations, they are applied with `thot db`",
+
+	"La base de datos de palabras tiene la versión %d y la actual es %d, se puede actualizar con `thot db`": "The word database has version %d and the current one is %d, it can be upgraded with `thot db`",
+
+	"Teclas muertas: %s": "Dead keys: %s",
+
+	"Las teclas muertas no se exportan en el formato %q": "Dead keys are not exported in the %q format",
+}
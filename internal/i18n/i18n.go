@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lang es el idioma de la interfaz de usuario.
+type Lang string
+
+const (
+	Spanish Lang = "es"
+	English Lang = "en"
+)
+
+// Los mensajes se escriben en español en el código fuente, el resto de idiomas traducen a partir de
+// ese texto. Si un mensaje no tiene traducción se muestra en español.
+var catalogs = map[Lang]map[string]string{
+	English: english,
+}
+
+var current = Spanish
+
+// Parse convierte un identificador de idioma (`es`, `en`, `es_MX.UTF-8`, `en-US`, ...) en un idioma soportado.
+func Parse(s string) (Lang, bool) { // {{{
+	s = strings.ToLower(s)
+	if i := strings.IndexAny(s, "_-.@"); i != -1 {
+		s = s[:i]
+	}
+	switch Lang(s) {
+	case Spanish:
+		return Spanish, true
+	case English:
+		return English, true
+	}
+
+	return Spanish, false
+} // }}}
+
+// Detect devuelve el primer idioma soportado de la lista `values` o, si ninguno lo es, el idioma indicado
+// por las variables de entorno `LC_ALL`, `LC_MESSAGES` y `LANG` en ese orden. Por defecto es español.
+func Detect(values ...string) Lang { // {{{
+	for _, v := range values {
+		if l, ok := Parse(v); ok {
+			return l
+		}
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			if l, ok := Parse(v); ok {
+				return l
+			}
+			// Una variable definida con un idioma no soportado tiene precedencia sobre las siguientes
+			return Spanish
+		}
+	}
+
+	return Spanish
+} // }}}
+
+// Set establece el idioma de la interfaz de usuario.
+func Set(l Lang) { // {{{
+	current = l
+} // }}}
+
+// Current devuelve el idioma actual de la interfaz de usuario.
+func Current() Lang { // {{{
+	return current
+} // }}}
+
+// T traduce el mensaje `msg` al idioma actual, si recibe argumentos el mensaje se utiliza como formato
+// de `fmt.Sprintf`.
+func T(msg string, args ...any) string { // {{{
+	if c, ok := catalogs[current]; ok {
+		if t, ok := c[msg]; ok {
+			msg = t
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+
+	return msg
+} // }}}
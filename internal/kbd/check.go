@@ -0,0 +1,233 @@
+package kbd
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// alphabet son las letras que debe tener todo layout, las letras propias de un idioma como `ñ` no son
+// obligatorias.
+const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// Report son los problemas encontrados en el layout `Name` por `CheckData`.
+type Report struct {
+	Name     string
+	Problems []string
+}
+
+// CheckData revisa los layouts de un archivo con el formato de `layout.json` y devuelve los problemas de cada
+// uno en el orden del archivo. Además de los problemas de `Problems` informa los layouts repetidos y las
+// propiedades desconocidas; el error es solo para los archivos que no son JSON válido.
+func CheckData(data []byte) ([]Report, errors.E) { // {{{
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, e := dec.Token(); e != nil || t != json.Delim('{') {
+		return nil, jsonError(data, e, dec.InputOffset())
+	}
+
+	var reports []Report
+	seen := make(map[string]bool)
+	for dec.More() {
+		t, e := dec.Token()
+		if e != nil {
+			return nil, jsonError(data, e, dec.InputOffset())
+		}
+		name, _ := t.(string)
+		var raw json.RawMessage
+		if e := dec.Decode(&raw); e != nil {
+			return nil, jsonError(data, e, dec.InputOffset())
+		}
+
+		r := Report{Name: name}
+		if seen[name] {
+			r.Problems = append(r.Problems, i18n.T("El layout está repetido en el archivo, solo se usa el último"))
+		}
+		seen[name] = true
+		var k Keyboard
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.DisallowUnknownFields()
+		if e := d.Decode(&k); e != nil {
+			r.Problems = append(r.Problems, i18n.T("El layout no tiene un formato válido: %s", e.Error()))
+		} else {
+			r.Problems = append(r.Problems, k.Problems()...)
+		}
+		reports = append(reports, r)
+	}
+	if _, e := dec.Token(); e != nil {
+		return nil, jsonError(data, e, dec.InputOffset())
+	}
+
+	return reports, nil
+} // }}}
+
+// jsonError devuelve el error de un archivo de layouts que no es JSON válido con la línea donde está el
+// problema, `offset` es la posición leída cuando el error no la indica.
+func jsonError(data []byte, e error, offset int64) errors.E { // {{{
+	var syntax *json.SyntaxError
+	if errors.As(e, &syntax) {
+		offset = syntax.Offset
+	}
+	if e == nil {
+		e = errors.New(i18n.T("El archivo debe ser un objeto con los layouts por nombre"))
+	}
+	line := bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+
+	return errors.WithMessage(e, i18n.T("El archivo de layouts no tiene un formato válido en la línea %d", line))
+} // }}}
+
+// Problems devuelve los problemas del layout: el tipo de teclado desconocido, las filas que faltan, que
+// sobran o que no tienen las teclas del tipo de teclado, las teclas que no tienen exactamente dos caracteres
+// (se dibujarían en blanco), los caracteres repetidos, las letras del alfabeto que faltan, los caracteres de
+// AltGr sin tecla y las teclas muertas que no están en el layout.
+func (k *Keyboard) Problems() []string { // {{{
+	var list []string
+	known := slices.Contains(Types, k.Type)
+	if !known {
+		list = append(list, i18n.T(
+			"El tipo de teclado %q no es válido, acepta solo los valores %s",
+			k.Type,
+			"`"+strings.Join(Types, "`, `")+"`",
+		))
+	}
+	template := templateKeys(k.Type)
+	rows := []string{Row1, Row2, Row3, Row4, Thumb}
+
+	// Filas y teclas de la capa base
+	for _, row := range sortedRows(k.Keys) {
+		if !slices.Contains(rows, row) {
+			list = append(list, i18n.T("La fila %q no existe, las filas son %s", row, "`"+strings.Join(rows, "`, `")+"`"))
+		}
+	}
+	seen := make(map[rune]string)
+	repeated := make(map[string]bool)
+	for _, row := range rows {
+		keys, ok := k.Keys[row]
+		switch {
+		case !ok && row != Thumb:
+			list = append(list, i18n.T("Falta la fila %s", row))
+			continue
+		case !ok:
+			continue
+		case row == Thumb && known && !IsMatrix(k.Type):
+			list = append(list, i18n.T("La fila %s solo existe en los teclados matriciales y el layout es %s", row, strings.ToUpper(k.Type)))
+		case known && len(keys) != template[row]:
+			list = append(list, i18n.T(
+				"La fila %s tiene %d teclas y los teclados %s tienen %d",
+				row, len(keys), strings.ToUpper(k.Type), template[row],
+			))
+		}
+		for col, key := range keys {
+			chars := []rune(key)
+			if row == Thumb && key == "" {
+				// Las teclas del pulgar pueden quedar sin asignar
+				continue
+			}
+			if len(chars) != 2 {
+				list = append(list, i18n.T("La %s tiene %q, debe tener exactamente dos caracteres", keyName(row, col), key))
+				continue
+			}
+			for _, r := range chars {
+				if first, ok := seen[r]; ok {
+					// Una tecla con el mismo carácter dos veces se informa una sola vez
+					msg := i18n.T("El carácter %q está en la %s y en la %s", string(r), first, keyName(row, col))
+					if first == keyName(row, col) {
+						msg = i18n.T("La %s tiene dos veces el carácter %q", first, string(r))
+					}
+					if !repeated[msg] {
+						list = append(list, msg)
+					}
+					repeated[msg] = true
+					continue
+				}
+				seen[r] = keyName(row, col)
+			}
+		}
+	}
+	var missing []string
+	for _, r := range alphabet {
+		if _, ok := seen[r]; !ok {
+			missing = append(missing, string(r))
+		}
+	}
+	if len(missing) > 0 {
+		list = append(list, i18n.T("Faltan las letras %s", strings.Join(missing, " ")))
+	}
+
+	// Caracteres con AltGr y teclas muertas
+	for _, row := range sortedRows(k.AltGr) {
+		keys, ok := k.Keys[row]
+		if !ok {
+			list = append(list, i18n.T("La fila %s de AltGr no existe en el layout", row))
+			continue
+		}
+		if len(k.AltGr[row]) > len(keys) {
+			list = append(list, i18n.T("La fila %s de AltGr tiene %d teclas y la del layout %d", row, len(k.AltGr[row]), len(keys)))
+		}
+		for col, key := range k.AltGr[row] {
+			if len([]rune(key)) > 2 {
+				list = append(list, i18n.T("La %s de AltGr tiene %q, debe tener como máximo dos caracteres", keyName(row, col), key))
+			}
+		}
+	}
+	for _, d := range k.Dead {
+		if _, ok := deadKeys[d]; !ok {
+			list = append(list, i18n.T("La tecla muerta %q no se conoce, acepta solo %s", string(d), knownDead()))
+		}
+		if _, ok := k.press(d); !ok {
+			list = append(list, i18n.T("La tecla muerta %q no está en el layout", string(d)))
+		}
+	}
+
+	return list
+} // }}}
+
+// templateKeys devuelve la cantidad de teclas de cada fila en los teclados del tipo `typ`.
+func templateKeys(typ string) map[string]int { // {{{
+	names := []string{Row1, Row2, Row3, Row4, ""}
+	count := make(map[string]int)
+	for line, row := range geometry[typ] {
+		for _, s := range row {
+			name := s.row
+			if name == "" {
+				name = names[line]
+			}
+			if s.label == "" && name != "" {
+				count[name] += s.n
+			}
+		}
+	}
+
+	return count
+} // }}}
+
+// sortedRows devuelve los nombres de las filas de `keys` ordenados, para que los problemas salgan siempre en
+// el mismo orden.
+func sortedRows(keys map[string][]string) []string { // {{{
+	var rows []string
+	for row := range keys {
+		rows = append(rows, row)
+	}
+	slices.Sort(rows)
+
+	return rows
+} // }}}
+
+// keyName describe la tecla `col` de la fila `row` para los mensajes, las teclas se cuentan desde 1.
+func keyName(row string, col int) string { // {{{
+	return i18n.T("tecla %d de la fila %s", col+1, row)
+} // }}}
+
+// knownDead devuelve las teclas muertas de `deadKeys` para los mensajes.
+func knownDead() string { // {{{
+	var list []string
+	for d := range deadKeys {
+		list = append(list, string(d))
+	}
+	slices.Sort(list)
+
+	return strings.Join(list, " ")
+} // }}}
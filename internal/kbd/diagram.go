@@ -0,0 +1,173 @@
+package kbd
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+)
+
+// Medidas del diagrama SVG en píxeles.
+const (
+	svgUnit   = 54.0
+	svgGap    = 4.0
+	svgMargin = 16.0
+	svgHeader = 40.0
+	svgLegend = 44.0
+	svgNote   = 24.0
+)
+
+// Colores de las teclas fijas y del texto del diagrama SVG, no dependen del tema porque el diagrama se
+// muestra fuera de la terminal.
+const (
+	svgFixed = "#d0d0d0"
+	svgInk   = "#303030"
+)
+
+var reEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// DiagramFormats son los formatos de `Diagram` además del diagrama para la terminal.
+var DiagramFormats = []string{"svg", "html", "txt"}
+
+// Diagram devuelve el diagrama del layout `name` en el formato `format`, `ok` es falso si el formato no
+// existe.
+func Diagram(name string, k *Keyboard, format string) (string, bool) { // {{{
+	switch format {
+	case "svg":
+		return k.SVG(name), true
+	case "html":
+		return k.HTML(name), true
+	case "txt":
+		return k.Text(name), true
+	}
+
+	return "", false
+} // }}}
+
+// Text devuelve el diagrama del teclado sin secuencias de escape, para pegarlo donde no hay colores.
+func (k *Keyboard) Text(name string) string { // {{{
+	text := fmt.Sprintf("%s (%s)\n", name, k.title()) + reEscape.ReplaceAllString(k.Render(Full, nil), "")
+	if legend := k.DeadLegend(false); legend != "" {
+		text += legend + "\n"
+	}
+
+	return text
+} // }}}
+
+// SVG devuelve el diagrama vectorial del teclado con las mismas zonas de color de cada dedo que el diagrama
+// de la terminal y la leyenda de los dedos debajo, con la nota de las teclas muertas si el layout las tiene.
+func (k *Keyboard) SVG(name string) string { // {{{
+	width := Width*svgUnit + 2*svgMargin
+	height := svgHeader + k.Height()*svgUnit + svgLegend + 2*svgMargin
+	note := k.DeadLegend(true)
+	if note != "" {
+		height += svgNote
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif">`+"\n",
+		width, height, width, height,
+	))
+	sb.WriteString(`  <rect width="100%" height="100%" rx="12" fill="#ffffff"/>` + "\n")
+	sb.WriteString(fmt.Sprintf(
+		`  <text x="%g" y="%g" font-size="20" font-weight="bold" fill="%s">%s <tspan font-weight="normal">(%s)</tspan></text>`+"\n",
+		svgMargin, svgMargin+22, svgInk, html.EscapeString(name), k.title(),
+	))
+
+	top := svgMargin + svgHeader
+	for _, c := range k.Caps() {
+		fill, ink := svgFixed, svgInk
+		if c.Row != "" {
+			fill, ink = k.Finger(c.Row, c.Col).Colors()
+		}
+		x := svgMargin + c.X*svgUnit + svgGap/2
+		y := top + c.Y*svgUnit + svgGap/2
+		w := c.W*svgUnit - svgGap
+		h := c.H*svgUnit - svgGap
+		if c.Notch > 0 {
+			// El Enter ISO es más angosto en la fila de abajo
+			notch := c.Notch * svgUnit
+			sb.WriteString(fmt.Sprintf(
+				`  <path d="M%g %gh%gv%gh%gv%gh%gz" fill="%s" stroke="#808080"/>`+"\n",
+				x, y, w, h, -(w - notch), -(h - svgUnit + svgGap), -notch, fill,
+			))
+		} else {
+			sb.WriteString(fmt.Sprintf(
+				`  <rect x="%g" y="%g" width="%g" height="%g" rx="6" fill="%s" stroke="#808080"/>`+"\n",
+				x, y, w, h, fill,
+			))
+		}
+		if c.Row == "" {
+			sb.WriteString(fmt.Sprintf(
+				`  <text x="%g" y="%g" font-size="11" fill="%s">%s</text>`+"\n",
+				x+6, y+16, ink, html.EscapeString(c.Label),
+			))
+			continue
+		}
+		lower, upper := c.Legends(k)
+		sb.WriteString(fmt.Sprintf(
+			`  <text x="%g" y="%g" font-size="15" fill="%s"%s>%s</text>`+"\n",
+			x+8, y+19, ink, k.svgDead(upper), html.EscapeString(upper),
+		))
+		sb.WriteString(fmt.Sprintf(
+			`  <text x="%g" y="%g" font-size="15" fill="%s"%s>%s</text>`+"\n",
+			x+8, y+h-9, ink, k.svgDead(lower), html.EscapeString(lower),
+		))
+	}
+
+	// Leyenda de los dedos
+	legend := top + k.Height()*svgUnit + svgGap
+	fingers := k.UsedFingers()
+	step := Width * svgUnit / float64(len(fingers))
+	for i, f := range fingers {
+		fill, ink := f.Colors()
+		x := svgMargin + float64(i)*step
+		sb.WriteString(fmt.Sprintf(
+			`  <rect x="%g" y="%g" width="%g" height="28" rx="6" fill="%s"/>`+"\n",
+			x+svgGap/2, legend, step-svgGap, fill,
+		))
+		sb.WriteString(fmt.Sprintf(
+			`  <text x="%g" y="%g" font-size="13" text-anchor="middle" fill="%s">%s</text>`+"\n",
+			x+step/2, legend+19, ink, html.EscapeString(f.String()),
+		))
+	}
+	if note != "" {
+		sb.WriteString(fmt.Sprintf(
+			`  <text x="%g" y="%g" font-size="13" fill="%s">%s</text>`+"\n",
+			svgMargin, legend+28+svgNote-4, svgInk, html.EscapeString(note),
+		))
+	}
+	sb.WriteString("</svg>\n")
+
+	return sb.String()
+} // }}}
+
+// svgDead devuelve el atributo que subraya el carácter `s` si es una tecla muerta.
+func (k *Keyboard) svgDead(s string) string { // {{{
+	if !k.IsDead(s) {
+		return ""
+	}
+
+	return ` text-decoration="underline"`
+} // }}}
+
+// HTML devuelve una página con el diagrama SVG del teclado, no depende de archivos externos.
+func (k *Keyboard) HTML(name string) string { // {{{
+	title := html.EscapeString(name)
+	return strings.Join([]string{
+		"<!DOCTYPE html>",
+		`<html lang="` + string(i18n.Current()) + `">`,
+		"<head>",
+		`  <meta charset="utf-8">`,
+		"  <title>Thot: " + title + "</title>",
+		"  <style>body { font-family: sans-serif; margin: 2em; } svg { max-width: 100%; height: auto; }</style>",
+		"</head>",
+		"<body>",
+		k.SVG(name) + "</body>",
+		"</html>",
+		"",
+	}, "\n")
+} // }}}
@@ -0,0 +1,151 @@
+package kbd
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/theme"
+)
+
+// Finger es el dedo que pulsa una tecla en la mecanografía de diez dedos.
+type Finger int
+
+const (
+	LeftPinky Finger = iota
+	LeftRing
+	LeftMiddle
+	LeftIndex
+	RightIndex
+	RightMiddle
+	RightRing
+	RightPinky
+	LeftThumb
+	RightThumb
+)
+
+// Fingers son los dedos de las filas del teclado de izquierda a derecha, los pulgares solo pulsan teclas en
+// los layouts con teclas del pulgar.
+var Fingers = []Finger{LeftPinky, LeftRing, LeftMiddle, LeftIndex, RightIndex, RightMiddle, RightRing, RightPinky}
+
+// ThumbKeys es la cantidad de teclas del pulgar de cada mano.
+const ThumbKeys = 3
+
+// Left indica si el dedo es de la mano izquierda.
+func (f Finger) Left() bool { // {{{
+	return f <= LeftIndex || f == LeftThumb
+} // }}}
+
+func (f Finger) String() string { // {{{
+	switch f {
+	case LeftPinky, RightPinky:
+		return i18n.T("Meñique")
+	case LeftRing, RightRing:
+		return i18n.T("Anular")
+	case LeftMiddle, RightMiddle:
+		return i18n.T("Corazon")
+	case LeftThumb, RightThumb:
+		return i18n.T("Pulgar")
+	}
+
+	return i18n.T("Indice")
+} // }}}
+
+// Style devuelve el estilo del color del dedo, el mismo que usan las plantillas y la leyenda del teclado.
+func (f Finger) Style() lipgloss.Style { // {{{
+	switch f {
+	case LeftPinky, RightPinky:
+		return meniqueStyle
+	case LeftRing, RightRing:
+		return anularStyle
+	case LeftMiddle, RightMiddle:
+		return corazonStyle
+	case LeftIndex:
+		return indiceiStyle
+	case LeftThumb, RightThumb:
+		return pulgarStyle
+	}
+
+	return indicedStyle
+} // }}}
+
+// Colors devuelve el color de fondo y el del texto del dedo en hexadecimal, para los diagramas que no se
+// muestran en una terminal.
+func (f Finger) Colors() (string, string) { // {{{
+	st := f.Style()
+	return theme.Hex(st.GetBackground()), theme.Hex(st.GetForeground())
+} // }}}
+
+// Las columnas de cada fila asignadas a cada dedo, son las mismas zonas de color que usan las plantillas.
+var (
+	numberRow = []Finger{
+		LeftPinky, LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky,
+	}
+	upperRow = []Finger{
+		LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky, RightPinky,
+	}
+	homeRow = []Finger{
+		LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky,
+	}
+	lowerRow = []Finger{
+		LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky,
+	}
+	// En ISO la fila inferior tiene una tecla más a la izquierda, junto a Shift
+	isoLowerRow = append([]Finger{LeftPinky}, lowerRow...)
+	// En los teclados matriciales las columnas están alineadas y el dedo depende solo de la columna. La fila
+	// de números empieza en la primera columna y las demás después de Tab, Caps y Shift.
+	matrixColumns = []Finger{
+		LeftPinky, LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky, RightPinky,
+	}
+	matrixRows = map[string][]Finger{
+		Row1: matrixColumns, Row2: matrixColumns[1:], Row3: matrixColumns[1:], Row4: matrixColumns[1:],
+	}
+)
+
+var fingers = map[string]map[string][]Finger{
+	"ansi":     {Row1: numberRow, Row2: upperRow, Row3: homeRow, Row4: lowerRow},
+	"iso":      {Row1: numberRow, Row2: upperRow, Row3: homeRow, Row4: isoLowerRow},
+	"ortho":    matrixRows,
+	"split":    matrixRows,
+	"columnar": matrixRows,
+}
+
+// Finger devuelve el dedo que pulsa la tecla `col` de la fila `row`, las teclas que sobran al final de una
+// fila se asignan al meñique derecho. Las primeras `ThumbKeys` teclas del pulgar son del pulgar izquierdo.
+func (k *Keyboard) Finger(row string, col int) Finger { // {{{
+	if row == Thumb {
+		if col < ThumbKeys {
+			return LeftThumb
+		}
+		return RightThumb
+	}
+	list := fingers[k.Type][row]
+	if list == nil {
+		list = fingers["ansi"][row]
+	}
+	if col < 0 || col >= len(list) {
+		return RightPinky
+	}
+
+	return list[col]
+} // }}}
+
+// UsedFingers devuelve los dedos que pulsan teclas del layout de izquierda a derecha, los de `Fingers` y los
+// pulgares si el layout tiene teclas del pulgar.
+func (k *Keyboard) UsedFingers() []Finger { // {{{
+	if !k.HasThumbs() {
+		return Fingers
+	}
+
+	return []Finger{
+		LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftThumb, RightThumb, RightIndex, RightMiddle, RightRing, RightPinky,
+	}
+} // }}}
+
+// HasThumbs indica si el layout tiene la fila de teclas del pulgar, aunque sus teclas estén vacías.
+func (k *Keyboard) HasThumbs() bool { // {{{
+	return len(k.Keys[Thumb]) > 0
+} // }}}
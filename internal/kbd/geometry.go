@@ -0,0 +1,210 @@
+package kbd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Cap es una tecla del diagrama del teclado, la posición y el tamaño se miden en unidades de tecla (1u es el
+// ancho de una letra). Las teclas del layout tienen `Row` y `Col`; las teclas fijas como Tab o Shift tienen
+// `Label` y `Row` vacío.
+type Cap struct {
+	Row   string  `json:"row,omitempty"`
+	Col   int     `json:"col"`
+	Label string  `json:"label,omitempty"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	W     float64 `json:"w"`
+	H     float64 `json:"h"`
+	// Notch es lo que se recorta a la izquierda de la mitad inferior de la tecla, solo en el Enter ISO.
+	Notch float64 `json:"notch,omitempty"`
+}
+
+// Legends devuelve el carácter normal y el de Shift de la tecla en el layout `k`, espacios en las teclas
+// fijas y en las que el layout no define.
+func (c Cap) Legends(k *Keyboard) (string, string) { // {{{
+	if c.Row == "" || c.Col >= len(k.Keys[c.Row]) {
+		return " ", " "
+	}
+
+	return chars(k.Keys[c.Row][c.Col])
+} // }}}
+
+// span es una secuencia de `n` teclas del layout de ancho `w`, o una tecla fija si `label` no está vacío. Las
+// teclas son de la fila que corresponde a su línea salvo que `row` diga otra, y un span sin teclas ni
+// `label` es un espacio vacío de ancho `w`.
+type span struct {
+	label string
+	row   string
+	n     int
+	w     float64
+	h     float64
+	notch float64
+}
+
+var bottomRow = []span{
+	{label: "Ctrl", w: 1.25}, {label: "Win", w: 1.25}, {label: "Alt", w: 1.25}, {label: " ", w: 6.25},
+	{label: "AltGr", w: 1.25}, {label: "Win", w: 1.25}, {label: "Menu", w: 1.25}, {label: "Ctrl", w: 1.25},
+}
+
+// Las filas de los teclados matriciales, todas las teclas son de 1u y las columnas están alineadas. En
+// `splitRows` las manos están separadas por un espacio entre la sexta y la séptima columna.
+var (
+	orthoRows = [][]span{
+		{{n: 13, w: 1}, {label: "Backspace", w: 2}},
+		{{label: "Tab", w: 1}, {n: 13, w: 1}, {label: "Del", w: 1}},
+		{{label: "Caps", w: 1}, {n: 11, w: 1}, {label: "Enter", w: 3}},
+		{{label: "Shift", w: 1}, {n: 10, w: 1}, {label: "Shift", w: 4}},
+		{
+			{label: "Ctrl", w: 1}, {label: "Win", w: 1}, {label: "Alt", w: 1},
+			{row: Thumb, n: ThumbKeys, w: 1}, {row: Thumb, n: ThumbKeys, w: 1},
+			{label: "AltGr", w: 1}, {label: "Ctrl", w: 1},
+			{label: "←", w: 1}, {label: "↓", w: 1}, {label: "↑", w: 1}, {label: "→", w: 1},
+		},
+	}
+	splitRows = [][]span{
+		{{n: 6, w: 1}, {w: 1}, {n: 7, w: 1}, {label: "Bksp", w: 1}},
+		{{label: "Tab", w: 1}, {n: 5, w: 1}, {w: 1}, {n: 8, w: 1}},
+		{{label: "Caps", w: 1}, {n: 5, w: 1}, {w: 1}, {n: 6, w: 1}, {label: "Enter", w: 2}},
+		{{label: "Shift", w: 1}, {n: 5, w: 1}, {w: 1}, {n: 5, w: 1}, {label: "Shift", w: 3}},
+		{
+			{label: "Ctrl", w: 1}, {label: "Win", w: 1}, {label: "Alt", w: 1}, {row: Thumb, n: ThumbKeys, w: 1},
+			{w: 1},
+			{row: Thumb, n: ThumbKeys, w: 1}, {label: "AltGr", w: 1}, {label: "Win", w: 1}, {label: "Menu", w: 1},
+			{label: "Ctrl", w: 2},
+		},
+	}
+)
+
+// geometry son las filas de cada tipo de teclado, la quinta fila es la de la barra espaciadora o las teclas
+// del pulgar.
+var geometry = map[string][][]span{
+	"ansi": {
+		{{n: 13, w: 1}, {label: "Backspace", w: 2}},
+		{{label: "Tab", w: 1.5}, {n: 12, w: 1}, {n: 1, w: 1.5}},
+		{{label: "Caps", w: 1.75}, {n: 11, w: 1}, {label: "Enter", w: 2.25}},
+		{{label: "Shift", w: 2.25}, {n: 10, w: 1}, {label: "Shift", w: 2.75}},
+		bottomRow,
+	},
+	"iso": {
+		{{n: 13, w: 1}, {label: "Backspace", w: 2}},
+		{{label: "Tab", w: 1.5}, {n: 12, w: 1}, {label: "Enter", w: 1.5, h: 2, notch: 0.25}},
+		{{label: "Caps", w: 1.75}, {n: 12, w: 1}},
+		{{label: "Shift", w: 1.25}, {n: 11, w: 1}, {label: "Shift", w: 2.75}},
+		bottomRow,
+	},
+	"ortho":    orthoRows,
+	"split":    splitRows,
+	"columnar": splitRows,
+}
+
+// stagger es cuánto baja cada columna de teclas en unidades de tecla en los teclados con columnas
+// escalonadas, las columnas de los dedos más largos quedan más arriba.
+var stagger = map[string][]float64{
+	"columnar": {0.5, 0.5, 0.25, 0, 0.25, 0.25, 0, 0.25, 0.25, 0, 0.25, 0.5, 0.5, 0.5, 0.5},
+}
+
+// Types son los tipos de teclado que se pueden dibujar.
+var Types = []string{"ansi", "iso", "ortho", "split", "columnar"}
+
+// IsMatrix indica si el tipo de teclado `typ` tiene las columnas alineadas y teclas del pulgar.
+func IsMatrix(typ string) bool { // {{{
+	return slices.Contains([]string{"ortho", "split", "columnar"}, typ)
+} // }}}
+
+// Convert devuelve una copia del layout para el tipo de teclado `typ`. La fila inferior de ISO tiene una
+// tecla más al principio, por eso un layout ISO no se puede usar en otro tipo de teclado ni al revés, y las
+// teclas del pulgar solo existen en los teclados matriciales.
+func (k *Keyboard) Convert(typ string) (*Keyboard, errors.E) { // {{{
+	if !slices.Contains(Types, typ) {
+		return nil, errors.New(i18n.T(
+			"El tipo de teclado %q no es válido, acepta solo los valores %s",
+			typ,
+			"`"+strings.Join(Types, "`, `")+"`",
+		))
+	}
+	if (k.Type == "iso") != (typ == "iso") {
+		return nil, errors.New(i18n.T("Un layout %s no se puede usar en un teclado %s", strings.ToUpper(k.Type), strings.ToUpper(typ)))
+	}
+	c := k.Clone()
+	c.Type = typ
+	if !IsMatrix(typ) && slices.ContainsFunc(c.Keys[Thumb], func(key string) bool { return key != "" }) {
+		return nil, errors.New(i18n.T("El layout tiene teclas del pulgar y los teclados %s no las tienen", strings.ToUpper(typ)))
+	}
+	if !IsMatrix(typ) {
+		delete(c.Keys, Thumb)
+	}
+
+	return c, nil
+} // }}}
+
+// Width es el ancho en unidades de tecla de los teclados de `Caps`.
+const Width = 15.0
+
+// Caps devuelve las teclas del diagrama del teclado según su tipo, de izquierda a derecha y de arriba a
+// abajo. Las teclas que faltan en una fila del layout quedan en blanco, ver `Cap.Legends`; si el layout no
+// tiene teclas del pulgar, cada grupo de teclas del pulgar es una barra espaciadora.
+func (k *Keyboard) Caps() []Cap { // {{{
+	rows := geometry[k.Type]
+	if rows == nil {
+		rows = geometry["ansi"]
+	}
+	names := []string{Row1, Row2, Row3, Row4, ""}
+	thumbs := k.HasThumbs()
+
+	var caps []Cap
+	cols := make(map[string]int)
+	for line, row := range rows {
+		x := 0.0
+		top := func() float64 {
+			if offset := stagger[k.Type]; int(x) < len(offset) {
+				return float64(line) + offset[int(x)]
+			}
+			return float64(line)
+		}
+		for _, s := range row {
+			h := s.h
+			if h == 0 {
+				h = 1
+			}
+			name := s.row
+			if name == "" {
+				name = names[line]
+			}
+			switch {
+			case s.label != "":
+				caps = append(caps, Cap{Label: s.label, X: x, Y: top(), W: s.w, H: h, Notch: s.notch})
+				x += s.w
+				continue
+			case name == Thumb && !thumbs:
+				caps = append(caps, Cap{Label: " ", X: x, Y: top(), W: float64(s.n) * s.w, H: h})
+				x += float64(s.n) * s.w
+				continue
+			case s.n == 0:
+				x += s.w
+				continue
+			}
+			for range s.n {
+				caps = append(caps, Cap{Row: name, Col: cols[name], X: x, Y: top(), W: s.w, H: h})
+				x += s.w
+				cols[name]++
+			}
+		}
+	}
+
+	return caps
+} // }}}
+
+// Height devuelve el alto en unidades de tecla del diagrama del teclado, es mayor que cinco filas en los
+// teclados con columnas escalonadas.
+func (k *Keyboard) Height() float64 { // {{{
+	height := 0.0
+	for _, c := range k.Caps() {
+		height = max(height, c.Y+c.H)
+	}
+
+	return height
+} // }}}
@@ -0,0 +1,190 @@
+package kbd
+
+import (
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+)
+
+// Layers son las capas de caracteres del teclado que se pueden mostrar: `base` con los caracteres normales y
+// con Shift, y `altgr` con los de AltGr y AltGr+Shift.
+var Layers = []string{"base", "altgr"}
+
+// deadKeys son los caracteres que escribe cada tecla muerta seguida de otra tecla, en pares de carácter de
+// la tecla siguiente y carácter resultante. `'` y `"` son las teclas muertas del layout de Estados Unidos
+// internacional.
+var deadKeys = map[rune]string{
+	'´':  "aáeéiíoóuúyýAÁEÉIÍOÓUÚYÝ",
+	'\'': "aáeéiíoóuúyýAÁEÉIÍOÓUÚYÝ",
+	'`':  "aàeèiìoòuùAÀEÈIÌOÒUÙ",
+	'^':  "aâeêiîoôuûAÂEÊIÎOÔUÛ",
+	'¨':  "aäeëiïoöuüyÿAÄEËIÏOÖUÜ",
+	'"':  "aäeëiïoöuüyÿAÄEËIÏOÖUÜ",
+	'~':  "aãoõnñAÃOÕNÑ",
+}
+
+// Layer devuelve el layout con los caracteres de la capa `name` de `Layers` en `Keys`, para mostrarlo con
+// `Render` o `Diagram`. `ok` es falso si la capa no existe.
+func (k *Keyboard) Layer(name string) (*Keyboard, bool) { // {{{
+	switch name {
+	case "", "base":
+		return k, true
+	case "altgr":
+	default:
+		return nil, false
+	}
+
+	c := k.Clone()
+	c.layer = name
+	for row, keys := range k.Keys {
+		list := make([]string, len(keys))
+		for col := range list {
+			// Las teclas sin AltGr+Shift se completan con un espacio para que `chars` muestre su carácter
+			if col < len(k.AltGr[row]) {
+				if r := []rune(k.AltGr[row][col]); len(r) == 1 {
+					list[col] = string(r) + " "
+				} else {
+					list[col] = k.AltGr[row][col]
+				}
+			}
+		}
+		c.Keys[row] = list
+	}
+
+	return c, true
+} // }}}
+
+// title devuelve el tipo del teclado y su capa si no es la base, para los títulos de los diagramas.
+func (k *Keyboard) title() string { // {{{
+	if k.layer == "" {
+		return strings.ToUpper(k.Type)
+	}
+
+	return strings.ToUpper(k.Type) + ", AltGr"
+} // }}}
+
+// DeadLegend devuelve la nota con las teclas muertas del layout, vacía si no tiene. Con `underlined` explica
+// que están subrayadas en el diagrama, el texto sin secuencias de escape no las puede marcar.
+func (k *Keyboard) DeadLegend(underlined bool) string { // {{{
+	if k.Dead == "" {
+		return ""
+	}
+	dead := strings.Join(strings.Split(k.Dead, ""), " ")
+	if !underlined {
+		return i18n.T("Teclas muertas: %s", dead)
+	}
+
+	return i18n.T("Teclas muertas (subrayadas): %s", dead)
+} // }}}
+
+// IsDead indica si el carácter `s` de una tecla es una tecla muerta del layout.
+func (k *Keyboard) IsDead(s string) bool { // {{{
+	return s != "" && s != " " && strings.Contains(k.Dead, s)
+} // }}}
+
+// Swap intercambia la tecla `ca` de la fila `ra` con la tecla `cb` de la fila `rb`, junto con sus caracteres
+// de AltGr.
+func (k *Keyboard) Swap(ra string, ca int, rb string, cb int) { // {{{
+	k.Keys[ra][ca], k.Keys[rb][cb] = k.Keys[rb][cb], k.Keys[ra][ca]
+	if k.AltGr == nil {
+		return
+	}
+	for _, p := range []struct {
+		row string
+		col int
+	}{{ra, ca}, {rb, cb}} {
+		for len(k.AltGr[p.row]) <= p.col {
+			k.AltGr[p.row] = append(k.AltGr[p.row], "")
+		}
+	}
+	k.AltGr[ra][ca], k.AltGr[rb][cb] = k.AltGr[rb][cb], k.AltGr[ra][ca]
+} // }}}
+
+// Combo devuelve la combinación de teclas que escribe el carácter `r` en el layout, p.e. `AltGr + 2` para `@`
+// en el layout español o `´ → e` para `é`. Una tecla muerta se escribe pulsándola seguida de espacio. `ok` es
+// falso si el layout no puede escribir el carácter.
+func (k *Keyboard) Combo(r rune) (string, bool) { // {{{
+	combo, ok := k.press(r)
+	if ok && k.IsDead(string(r)) {
+		return combo + " → " + i18n.T("Espacio"), true
+	}
+	if ok {
+		return combo, true
+	}
+
+	for _, d := range k.Dead {
+		pairs := []rune(deadKeys[d])
+		for i := 0; i+1 < len(pairs); i += 2 {
+			if pairs[i+1] != r {
+				continue
+			}
+			dead, okDead := k.press(d)
+			base, okBase := k.press(pairs[i])
+			if okDead && okBase {
+				return dead + " → " + base, true
+			}
+		}
+	}
+
+	return "", false
+} // }}}
+
+// press devuelve las teclas que se pulsan a la vez para escribir el carácter `r`, con la menor cantidad de
+// modificadores posible. La tecla se nombra con su carácter normal.
+func (k *Keyboard) press(r rune) (string, bool) { // {{{
+	levels := []struct {
+		keys      map[string][]string
+		level     int
+		modifiers string
+	}{
+		{k.Keys, 0, ""},
+		{k.Keys, 1, "Shift + "},
+		{k.AltGr, 0, "AltGr + "},
+		{k.AltGr, 1, "AltGr + Shift + "},
+	}
+	for _, l := range levels {
+		for _, row := range []string{Row1, Row2, Row3, Row4, Thumb} {
+			for col, key := range l.keys[row] {
+				chars := []rune(key)
+				if l.level >= len(chars) || chars[l.level] != r || col >= len(k.Keys[row]) {
+					continue
+				}
+				if name := []rune(k.Keys[row][col]); len(name) > 0 {
+					return l.modifiers + string(name[0]), true
+				}
+			}
+		}
+	}
+
+	return "", false
+} // }}}
+
+// composed devuelve los caracteres que se escriben con una tecla muerta de las filas `rows` seguida de una
+// tecla de `keys`, p.e. `é` si están `´` y `e`.
+func (k *Keyboard) composed(rows []string, keys []rune) []rune { // {{{
+	set := make(map[rune]bool, len(keys))
+	for _, r := range keys {
+		set[r] = true
+	}
+	sb := strings.Builder{}
+	for _, row := range rows {
+		sb.WriteString(strings.Join(k.Keys[row], ""))
+		sb.WriteString(strings.Join(k.AltGr[row], ""))
+	}
+	chars := sb.String()
+
+	var list []rune
+	for _, d := range k.Dead {
+		if !strings.ContainsRune(chars, d) {
+			continue
+		}
+		pairs := []rune(deadKeys[d])
+		for i := 0; i+1 < len(pairs); i += 2 {
+			if set[pairs[i]] {
+				list = append(list, pairs[i+1])
+			}
+		}
+	}
+
+	return list
+} // }}}
@@ -0,0 +1,98 @@
+package kbd
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Metrics resume qué tan cómodo es escribir una lista de palabras con un layout, todas las proporciones van
+// de 0 a 1.
+type Metrics struct {
+	// HomeRow es la proporción de pulsaciones en la fila base.
+	HomeRow float64
+	// SameFinger es la proporción de pares de letras consecutivas distintas que se pulsan con el mismo dedo.
+	SameFinger float64
+	// Alternation es la proporción de pares de letras consecutivas que alternan de mano.
+	Alternation float64
+	// Load es la proporción de pulsaciones de cada dedo, se indexa con `Finger`.
+	Load [RightThumb + 1]float64
+	// Missing son las letras de las palabras que no están en el layout, ordenadas por frecuencia.
+	Missing []rune
+}
+
+// position es el lugar de un carácter en el teclado.
+type position struct {
+	row    string
+	finger Finger
+}
+
+// Metrics calcula las métricas del layout para la lista de palabras `list`, las mayúsculas se cuentan
+// como su minúscula.
+func (k *Keyboard) Metrics(list []string) Metrics { // {{{
+	where := make(map[rune]position)
+	for _, row := range []string{Row1, Row2, Row3, Row4, Thumb} {
+		for col, key := range k.Keys[row] {
+			for _, c := range key {
+				c = unicode.ToLower(c)
+				if _, ok := where[c]; !ok {
+					where[c] = position{row: row, finger: k.Finger(row, col)}
+				}
+			}
+		}
+	}
+
+	var m Metrics
+	var total, home, pairs, same, alternate int
+	var load [RightThumb + 1]int
+	missing := make(map[rune]int)
+	for _, word := range list {
+		prev, last, hasPrev := position{}, rune(0), false
+		for _, c := range word {
+			c = unicode.ToLower(c)
+			p, ok := where[c]
+			if !ok {
+				missing[c]++
+				hasPrev = false
+				continue
+			}
+			total++
+			load[p.finger]++
+			if p.row == Row3 {
+				home++
+			}
+			if hasPrev {
+				pairs++
+				if p.finger == prev.finger && c != last {
+					same++
+				}
+				if p.finger.Left() != prev.finger.Left() {
+					alternate++
+				}
+			}
+			prev, last, hasPrev = p, c, true
+		}
+	}
+
+	if total > 0 {
+		m.HomeRow = float64(home) / float64(total)
+		for i := range load {
+			m.Load[i] = float64(load[i]) / float64(total)
+		}
+	}
+	if pairs > 0 {
+		m.SameFinger = float64(same) / float64(pairs)
+		m.Alternation = float64(alternate) / float64(pairs)
+	}
+	for c := range missing {
+		m.Missing = append(m.Missing, c)
+	}
+	sort.Slice(m.Missing, func(i, j int) bool {
+		a, b := m.Missing[i], m.Missing[j]
+		if missing[a] != missing[b] {
+			return missing[a] > missing[b]
+		}
+		return a < b
+	})
+
+	return m
+} // }}}
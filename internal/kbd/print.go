@@ -4,23 +4,22 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/lipgloss"
-	"github.com/wrodriguez/thot/internal/util"
-)
-
-const (
-	Menique = "\x1b[38;5;227m"
-	Anular  = "\x1b[38;5;69m"
-	Corazon = "\x1b[38;5;70m"
-	Indicei = "\x1b[38;5;161m"
-	Indiced = "\x1b[38;5;172m"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/theme"
+	"github.com/wrodriguez/thot/internal/words"
 )
 
 var defStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("192"))
 
+var boxStyle = lipgloss.NewStyle().
+	Padding(0, 1).
+	BorderStyle(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("27"))
+
 var (
 	meniqueStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("16")).
@@ -37,6 +36,9 @@ var (
 	indicedStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("16")).
 			Background(lipgloss.Color("172"))
+	pulgarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("16")).
+			Background(lipgloss.Color("140"))
 )
 
 const (
@@ -44,39 +46,49 @@ const (
 	Row2 = "row2"
 	Row3 = "row3"
 	Row4 = "row4"
+	// Thumb son las teclas del pulgar de los teclados matriciales, primero las de la mano izquierda y luego las
+	// de la derecha, ver `ThumbKeys`.
+	Thumb = "thumb"
 )
 
-const (
-	Sup = "\x1e"
-	Inf = "\x1f"
-)
-
-var ansi map[string]string = make(map[string]string)
-var iso map[string]string = make(map[string]string)
-var miniAnsi map[string]string = make(map[string]string)
-var miniIso map[string]string = make(map[string]string)
-
 //go:embed layout.json
 var blayouts []byte
 var layouts map[string]Keyboard
 
-const rowBottom = `
-╔═══════╗╔═══════╗╔════════╗╔════════════════════════════════════════════╗╔═══════╗╔═══════╗╔═══════╗
-║░░░░░░░║║░░░░░░░║║░░░░░░░░║║░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░║║░░░░░░░║║░░░░░░░║║░░░░░░░║
-║░░░░░░░║║░░░░░░░║║░░░░░░░░║║░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░║║░░░░░░░║║░░░░░░░║║░░░░░░░║
-╚═══════╝╚═══════╝╚════════╝╚════════════════════════════════════════════╝╚═══════╝╚═══════╝╚═══════╝`
-
-const miniRowBottom = `╔════╗╔════╗╔════╗╔═══════════════════╗╔════╗╔════╗╔════╗
-║░░░░║║░░░░║║░░░░║║░░░░░░░░░░░░░░░░░░░║║░░░░║║░░░░║║░░░░║
-╚════╝╚════╝╚════╝╚═══════════════════╝╚════╝╚════╝╚════╝`
-
-var reLetter = regexp.MustCompile(`[a-zA-ZñÑ]`)
+// loadErr es el error al leer `layout.json`, se devuelve en `LoadUserLayouts` en lugar de entrar en pánico al
+// iniciar para que `CheckData` pueda informar el problema.
+var loadErr error
 
 type Keyboard struct {
 	Type string              `json:"type"`
 	Keys map[string][]string `json:"keys"`
+	// AltGr son los caracteres con AltGr y con AltGr+Shift de cada tecla, en las mismas filas y columnas que
+	// `Keys`. Una tecla sin caracteres con AltGr es una cadena vacía y una con un solo carácter no tiene
+	// AltGr+Shift.
+	AltGr map[string][]string `json:"altgr,omitempty"`
+	// Dead son los caracteres de las teclas muertas, que no escriben nada y acentúan el carácter de la tecla
+	// siguiente como `´` y `e` en `é`.
+	Dead string `json:"dead,omitempty"`
+	// layer es la capa de `Layer` que tiene el teclado en `Keys`, vacía en la capa base
+	layer string
 }
 
+// Clone devuelve una copia del teclado que se puede modificar sin afectar al original.
+func (k *Keyboard) Clone() *Keyboard { // {{{
+	c := &Keyboard{Type: k.Type, Keys: make(map[string][]string, len(k.Keys)), Dead: k.Dead, layer: k.layer}
+	for row, keys := range k.Keys {
+		c.Keys[row] = append([]string(nil), keys...)
+	}
+	if k.AltGr != nil {
+		c.AltGr = make(map[string][]string, len(k.AltGr))
+		for row, keys := range k.AltGr {
+			c.AltGr[row] = append([]string(nil), keys...)
+		}
+	}
+
+	return c
+} // }}}
+
 func (k *Keyboard) String() string { // {{{
 	return fmt.Sprintf(
 		"Type: %s\n, Keys:\n\t%q\n\t%q\n\t%q\n\t%q",
@@ -89,393 +101,24 @@ func (k *Keyboard) String() string { // {{{
 } // }}}
 
 func init() { // {{{
-	ansi["row1"] = fmt.Sprintf(
-		"%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮╭─────╮\x1b[0m╔════════╗\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   ││ \x1e   │\x1b[0m║░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   ││ \x1f   │\x1b[0m║░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"╰%s─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯╰─────╯\x1b[0m╚════════╝\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-	ansi["row2"] = fmt.Sprintf(
-		"╔════════╗%s╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮╭─────╮╭─────╮\x1b[0m\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"║░░░░░░░░║%s│ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   ││ \x1e   ││ \x1e   │\x1b[0m\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"║░░░░░░░░║%s│ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   ││ \x1f   ││ \x1f   │\x1b[0m\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"╚════════╝%s╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯╰─────╯╰─────╯\x1b[0m\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-	ansi["row3"] = fmt.Sprintf(
-		"╔══════════╗%s╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮\x1b[0m╔══════════╗\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"║░░░░░░░░░░║%s│ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │\x1b[0m║░░░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"║░░░░░░░░░░║%s│ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │\x1b[0m║░░░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"╚══════════╝%s╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯\x1b[0m╚══════════╝\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-	ansi["row4"] = fmt.Sprintf(
-		"╔═════════════╗%s╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮\x1b[0m╔══════════════╗\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"║░░░░░░░░░░░░░║%s│ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   │\x1b[0m║░░░░░░░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"║░░░░░░░░░░░░░║%s│ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   │\x1b[0m║░░░░░░░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"╚═════════════╝%s╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯\x1b[0m╚══════════════╝",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-
-	iso["row1"] = fmt.Sprintf(
-		"%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮╭─────╮\x1b[0m╔════════╗\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   ││ \x1e   │\x1b[0m║░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   ││ \x1f   │\x1b[0m║░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯╰─────╯\x1b[0m╚════════╝\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-	iso["row2"] = fmt.Sprintf(
-		"╔═══════╗%s╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮╭─────╮\x1b[0m╔══════╗\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"║░░░░░░░║%s│ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   ││ \x1e   │\x1b[0m║░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"║░░░░░░░║%s│ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   ││ \x1f   │\x1b[0m║░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"╚═══════╝%s╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯╰─────╯\x1b[0m╚═╗░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-	iso["row3"] = fmt.Sprintf(
-		"╔═════════╗%s╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮╭─────╮\x1b[0m║░░░░║\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"║░░░░░░░░░║%s│ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   ││ \x1e   │\x1b[0m║░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"║░░░░░░░░░║%s│ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   ││ \x1f   │\x1b[0m║░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"╚═════════╝%s╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯╰─────╯\x1b[0m╚════╝\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-	iso["row4"] = fmt.Sprintf(
-		"╔═══════╗%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮╭─────╮%s╭─────╮╭─────╮%s╭─────╮%s╭─────╮%s╭─────╮\x1b[0m╔═════════════╗\n",
-		Menique,
-		Anular,
-		Corazon,
-		Indicei,
-		Indiced,
-		Corazon,
-		Anular,
-		Menique,
-	) +
-		fmt.Sprintf(
-			"║░░░░░░░║%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   ││ \x1e   │%s│ \x1e   │%s│ \x1e   │%s│ \x1e   │\x1b[0m║░░░░░░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"║░░░░░░░║%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   ││ \x1f   │%s│ \x1f   │%s│ \x1f   │%s│ \x1f   │\x1b[0m║░░░░░░░░░░░░░║\n",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		) +
-		fmt.Sprintf(
-			"╚═══════╝%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯╰─────╯%s╰─────╯╰─────╯%s╰─────╯%s╰─────╯%s╰─────╯\x1b[0m╚═════════════╝",
-			Menique,
-			Anular,
-			Corazon,
-			Indicei,
-			Indiced,
-			Corazon,
-			Anular,
-			Menique,
-		)
-
-		// Mini Keyboard
-
-	miniAnsi[Row1] = "╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╔═══╗\n" +
-		"│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│║░░░║\n" +
-		"╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╚═══╝\n"
-	miniAnsi[Row2] = "╔═══╗╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮\n" +
-		"║░░░║│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│\n" +
-		"╚═══╝╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯\n"
-	miniAnsi[Row3] = "╔═════╗╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╔════╗\n" +
-		"║░░░░░║│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│║░░░░║\n" +
-		"╚═════╝╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╚════╝\n"
-	miniAnsi[Row4] = "╔═══════╗╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╔══════╗\n" +
-		"║░░░░░░░║│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│║░░░░░░║\n" +
-		"╚═══════╝╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╚══════╝\n"
-
-	miniIso[Row1] = "╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╔═══╗\n" +
-		"│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│║░░░║\n" +
-		"╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╚═══╝\n"
-	miniIso[Row2] = "╔══╗╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╔═══╗\n" +
-		"║░░║│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│║░░░║\n" +
-		"╚══╝╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯║░░░║\n"
-	miniIso[Row3] = "╔═══════╗╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮║░░║\n" +
-		"║░░░░░░░║│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│║░░║\n" +
-		"╚═══════╝╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╚══╝\n"
-	miniIso[Row4] = "╔═══════╗╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╭──╮╔══════╗\n" +
-		"║░░░░░░░║│\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f││\x1e\x1f│║░░░░░░║\n" +
-		"╚═══════╝╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╰──╯╚══════╝\n"
-
+	theme.Register("kbd.default", &defStyle)
+	theme.Register("kbd.box", &boxStyle)
+	theme.Register("kbd.menique", &meniqueStyle)
+	theme.Register("kbd.anular", &anularStyle)
+	theme.Register("kbd.corazon", &corazonStyle)
+	theme.Register("kbd.indicei", &indiceiStyle)
+	theme.Register("kbd.indiced", &indicedStyle)
+	theme.Register("kbd.pulgar", &pulgarStyle)
 	if err := json.Unmarshal(blayouts, &layouts); err != nil {
-		panic(err)
+		layouts, loadErr = make(map[string]Keyboard), err
 	}
 } // }}}
 
+// Bundled devuelve el contenido de `layout.json`, los layouts incluidos en Thot.
+func Bundled() []byte { // {{{
+	return blayouts
+} // }}}
+
 func FindLayout(name string) *Keyboard { // {{{
 	if layout, ok := layouts[name]; ok {
 		return &layout
@@ -496,14 +139,20 @@ func ListLayouts() []string { // {{{
 	return names
 } // }}}
 
+// GetKeys devuelve las letras de las filas `rows` en el orden en que aparecen en el teclado, solo las que
+// tienen mayúscula y minúscula para descartar símbolos como `º` o `ª`. Las letras con AltGr van después de
+// las de la capa base.
 func (k *Keyboard) GetKeys(rows ...string) string { // {{{
 	sb := strings.Builder{}
-	for _, row := range rows {
-		if r, ok := k.Keys[row]; ok {
-			as := strings.Split(strings.Join(r, ""), "")
-			for _, s := range as {
-				if reLetter.MatchString(s) { // TODO: Revisar el regex
-					sb.WriteString(s)
+	for _, layer := range []map[string][]string{k.Keys, k.AltGr} {
+		for _, row := range rows {
+			if r, ok := layer[row]; ok {
+				for _, key := range r {
+					for _, c := range key {
+						if unicode.IsLower(c) || unicode.IsUpper(c) {
+							sb.WriteRune(c)
+						}
+					}
 				}
 			}
 		}
@@ -512,89 +161,76 @@ func (k *Keyboard) GetKeys(rows ...string) string { // {{{
 	return sb.String()
 } // }}}
 
-func PrintKeyboard(name string, k *Keyboard) { // {{{
-	box := lipgloss.NewStyle().
-		Padding(0, 1).
-		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("27"))
-	sbi := strings.Builder{}
-	_ = box
+// KeySet devuelve el conjunto de letras de las filas `rows` para filtrar las palabras de una sesión, incluye
+// las letras acentuadas que se escriben con las teclas muertas de esas filas.
+func (k *Keyboard) KeySet(rows ...string) words.KeySet { // {{{
+	keys := []rune(k.GetKeys(rows...))
+	return words.NewKeySet(append(keys, k.composed(rows, keys)...)...)
+} // }}}
 
+// PrintKeyboard muestra el diagrama del layout `name` en un recuadro con su nombre y tipo, en el tamaño más
+// grande que cabe en `width` columnas.
+func PrintKeyboard(name string, k *Keyboard, width int) { // {{{
+	// El recuadro usa dos columnas de borde y dos de relleno
+	size := FitSize(width - 4)
+	sbi := strings.Builder{}
 	sbi.WriteString(
 		defStyle.Render(
-			"󰌓 Nombre: ",
+			i18n.T("󰌓 Nombre: "),
 		) + name + " | " + defStyle.Render(
-			"󰌓 Tipo: ",
-		) + strings.ToUpper(
-			k.Type,
-		),
+			i18n.T("󰌓 Tipo: "),
+		) + k.title(),
 	)
-	// fmt.Println(box.Render(sbi.String()))
 
 	sbk := strings.Builder{}
-	template := util.IF(k.Type == "ansi", ansi, iso)
-	for _, row := range []string{"row1", "row2", "row3", "row4"} {
-		sbk.WriteString(replace(template[row], k.Keys[row]))
+	sbk.WriteString(k.Render(size, nil))
+	sbk.WriteString(k.FingerLegend(size.Width()))
+	if legend := k.DeadLegend(true); legend != "" {
+		sbk.WriteString(legend + "\n")
 	}
-	sbk.WriteString(rowBottom + "\033[0m\n")
-
-	sbk.WriteString(fmt.Sprintf(
-		"%s%s%s%s 󰹆                            󰹇  %s%s%s%s\n",
-		meniqueStyle.Render(" Meñique "),
-		anularStyle.Render(" Anular "),
-		corazonStyle.Render(" Corazon "),
-		indiceiStyle.Render(" Indice "),
-		indicedStyle.Render(" Indice "),
-		corazonStyle.Render(" Corazon "),
-		anularStyle.Render(" Anular "),
-		meniqueStyle.Render(" Meñique "),
-	))
 
-	fmt.Println(box.Render(lipgloss.JoinVertical(lipgloss.Left, sbi.String(), sbk.String())))
+	fmt.Println(boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sbi.String(), sbk.String())))
 } // }}}
 
-func PrintMiniKeyboard(name string, k *Keyboard) { // {{{
-	box := lipgloss.NewStyle().
-		Padding(0, 1).
-		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("27"))
-	sbi := strings.Builder{}
-	sbi.WriteString(
-		defStyle.Render(
-			"󰌓 Nombre: ",
-		) + name + " | " + defStyle.Render(
-			"󰌓 Tipo: ",
-		) + strings.ToUpper(
-			k.Type,
-		),
-	)
-	sbk := strings.Builder{}
-	template := util.IF(k.Type == "ansi", miniAnsi, miniIso)
-	for _, row := range []string{"row1", "row2", "row3", "row4"} {
-		sbk.WriteString(replace(template[row], k.Keys[row]))
+// FingerLegend devuelve la leyenda con el color de cada dedo del layout que se muestra debajo del teclado, con
+// el ancho `width` del diagrama. Si con los pulgares no cabe se muestra sin ellos, y si tampoco cabe devuelve
+// una cadena vacía.
+func (k *Keyboard) FingerLegend(width int) string { // {{{
+	if legend := fingerLegend(k.UsedFingers(), width); legend != "" || !k.HasThumbs() {
+		return legend
 	}
-	sbk.WriteString(miniRowBottom + "\033[0m\n")
 
-	fmt.Println(box.Render(lipgloss.JoinVertical(lipgloss.Left, sbi.String(), sbk.String())))
+	return fingerLegend(Fingers, width)
+} // }}}
+
+func fingerLegend(fingers []Finger, width int) string { // {{{
+	var left, right string
+	for _, f := range fingers {
+		label := f.Style().Render(" " + f.String() + " ")
+		if f.Left() {
+			left += label
+		} else {
+			right += label
+		}
+	}
+	gap := width - lipgloss.Width(left) - lipgloss.Width(right) - 4
+	if gap < 1 {
+		return ""
+	}
 
+	return left + " 󰹆" + strings.Repeat(" ", gap) + "󰹇 " + right + "\n"
 } // }}}
 
+// Mark decora el carácter `s` de la tecla `col` de la fila `row` antes de dibujarlo, sirve para resaltar
+// teclas en el diagrama.
+type Mark func(row string, col int, s string) string
+
 func chars(s string) (string, string) { // {{{
 	sp := strings.Split(s, "")
 	if len(sp) == 2 {
 		return sp[0], sp[1]
 	}
 
-	return "", ""
-} // }}}
-
-func replace(tpl string, data []string) string { // {{{
-	str := tpl
-	for _, v := range data {
-		i, s := chars(v)
-		str = strings.Replace(str, Sup, s, 1)
-		str = strings.Replace(str, Inf, i, 1)
-	}
-
-	return str
+	// Las teclas vacías o incompletas se muestran en blanco para no deformar el diagrama
+	return " ", " "
 } // }}}
@@ -0,0 +1,207 @@
+package kbd
+
+import (
+	"math"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/theme"
+)
+
+// Size es el tamaño del diagrama del teclado en la terminal.
+type Size int
+
+const (
+	// Full dibuja cada tecla con 7 columnas y los dos caracteres en líneas separadas.
+	Full Size = iota
+	// Compact dibuja cada tecla con 5 columnas y los dos caracteres en líneas separadas.
+	Compact
+	// Mini dibuja cada tecla con 4 columnas y los dos caracteres en la misma línea.
+	Mini
+)
+
+// scale son las columnas por unidad de tecla y las líneas por fila de cada tamaño.
+var scale = map[Size]struct{ cols, lines int }{
+	Full:    {7, 4},
+	Compact: {5, 4},
+	Mini:    {4, 3},
+}
+
+// Width devuelve el ancho en columnas del diagrama del teclado en el tamaño `s`.
+func (s Size) Width() int { // {{{
+	return int(math.Round(Width * float64(scale[s].cols)))
+} // }}}
+
+// FitSize devuelve el tamaño más grande cuyo diagrama cabe en `width` columnas, si ninguno cabe devuelve
+// `Mini`.
+func FitSize(width int) Size { // {{{
+	for _, s := range []Size{Full, Compact} {
+		if s.Width() <= width {
+			return s
+		}
+	}
+
+	return Mini
+} // }}}
+
+// cell es un carácter del diagrama con el color de su tecla, `text` puede tener secuencias de escape de una
+// marca.
+type cell struct {
+	text  string
+	color string
+}
+
+// canvas es el diagrama en construcción, una matriz de celdas por línea y columna.
+type canvas [][]cell
+
+func newCanvas(lines, cols int) canvas { // {{{
+	c := make(canvas, lines)
+	for i := range c {
+		c[i] = make([]cell, cols)
+		for j := range c[i] {
+			c[i][j].text = " "
+		}
+	}
+
+	return c
+} // }}}
+
+func (c canvas) set(line, col int, text, color string) { // {{{
+	if line >= 0 && line < len(c) && col >= 0 && col < len(c[line]) {
+		c[line][col] = cell{text: text, color: color}
+	}
+} // }}}
+
+// String une las celdas del diagrama, el color se escribe solo cuando cambia.
+func (c canvas) String() string { // {{{
+	sb := strings.Builder{}
+	for _, line := range c {
+		color := ""
+		for _, cl := range line {
+			if cl.color != color {
+				sb.WriteString("\x1b[0m" + cl.color)
+				color = cl.color
+			}
+			sb.WriteString(cl.text)
+		}
+		if color != "" {
+			sb.WriteString("\x1b[0m")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+} // }}}
+
+// box son los caracteres del borde de una tecla según las direcciones a las que se une: arriba, abajo,
+// izquierda y derecha.
+type box struct {
+	h, v, tl, tr, bl, br, fill string
+}
+
+var (
+	// keyBox es el borde de las teclas del layout.
+	keyBox = box{h: "─", v: "│", tl: "╭", tr: "╮", bl: "╰", br: "╯", fill: " "}
+	// fixedBox es el borde de las teclas fijas como Tab o Shift.
+	fixedBox = box{h: "═", v: "║", tl: "╔", tr: "╗", bl: "╚", br: "╝", fill: "░"}
+)
+
+// Render devuelve el diagrama del teclado sin recuadro ni leyenda en el tamaño `size`, las teclas se dibujan
+// a partir de `Caps` con el color de su dedo y los caracteres de las teclas muertas subrayados. `mark` puede
+// ser nil.
+func (k *Keyboard) Render(size Size, mark Mark) string { // {{{
+	sc := scale[size]
+	col := func(x float64) int { return int(math.Round(x * float64(sc.cols))) }
+	line := func(y float64) int { return int(math.Round(y * float64(sc.lines))) }
+
+	cv := newCanvas(line(k.Height()), size.Width())
+	for _, c := range k.Caps() {
+		b, color := fixedBox, ""
+		if c.Row != "" {
+			b, color = keyBox, theme.Color(k.Finger(c.Row, c.Col).Style().GetBackground())
+		}
+		x0, x1 := col(c.X), col(c.X+c.W)-1
+		y0, y1 := line(c.Y), line(c.Y+c.H)-1
+		inside := func(line, x int) bool {
+			if line < y0 || line > y1 || x < x0 || x > x1 {
+				return false
+			}
+			// El Enter ISO es más angosto en la fila de abajo
+			return c.Notch == 0 || line < y0+sc.lines || x >= col(c.X+c.Notch)
+		}
+		draw(cv, b, color, y0, y1, x0, x1, inside)
+
+		if c.Row == "" {
+			continue
+		}
+		lower, upper := c.Legends(k)
+		lower, upper = k.deadMark(lower), k.deadMark(upper)
+		if mark != nil {
+			lower, upper = mark(c.Row, c.Col, lower), mark(c.Row, c.Col, upper)
+		}
+		if size == Mini {
+			cv.set(y0+1, x0+1, upper, color)
+			cv.set(y0+1, x0+2, lower, color)
+			continue
+		}
+		cv.set(y0+1, x0+2, upper, color)
+		cv.set(y0+2, x0+2, lower, color)
+	}
+
+	return cv.String()
+} // }}}
+
+// deadMark subraya el carácter `s` si es una tecla muerta, sin cambiar el color de la tecla.
+func (k *Keyboard) deadMark(s string) string { // {{{
+	if !k.IsDead(s) {
+		return s
+	}
+
+	return "\x1b[4m" + s + "\x1b[24m"
+} // }}}
+
+// draw dibuja la tecla formada por las celdas de `inside` dentro del rectángulo de líneas `y0`-`y1` y columnas
+// `x0`-`x1`. Una celda es borde si alguna celda vecina está fuera de la tecla, y su carácter depende de las
+// celdas de borde vecinas con las que se une.
+func draw(cv canvas, b box, color string, y0, y1, x0, x1 int, inside func(line, x int) bool) { // {{{
+	border := func(line, x int) bool {
+		if !inside(line, x) {
+			return false
+		}
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if !inside(line+dy, x+dx) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for line := y0; line <= y1; line++ {
+		for x := x0; x <= x1; x++ {
+			if !inside(line, x) {
+				continue
+			}
+			if !border(line, x) {
+				cv.set(line, x, b.fill, color)
+				continue
+			}
+			up, down := border(line-1, x), border(line+1, x)
+			left, right := border(line, x-1), border(line, x+1)
+			ch := b.h
+			switch {
+			case up && down:
+				ch = b.v
+			case down && right:
+				ch = b.tl
+			case down && left:
+				ch = b.tr
+			case up && right:
+				ch = b.bl
+			case up && left:
+				ch = b.br
+			}
+			cv.set(line, x, ch, color)
+		}
+	}
+} // }}}
@@ -0,0 +1,138 @@
+package kbd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/paths"
+	"gitlab.com/tozd/go/errors"
+)
+
+// UserFile es el archivo con los layouts creados por el usuario, tiene el mismo formato que `layout.json`.
+const UserFile = "layouts.json"
+
+var reName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// user guarda los nombres de los layouts del usuario para distinguirlos de los incluidos en Thot.
+var user = make(map[string]bool)
+
+// UserPath devuelve la ruta del archivo de layouts del usuario, es compartido por todos los perfiles.
+func UserPath() (string, errors.E) { // {{{
+	dir, err := paths.Dir(paths.Config)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, UserFile), nil
+} // }}}
+
+// LoadUserLayouts agrega los layouts del usuario a los incluidos en Thot, si el archivo no existe no hace
+// nada. Un layout del usuario no reemplaza a uno incluido con el mismo nombre. También devuelve el error de
+// los layouts incluidos en Thot si no se pudieron leer.
+func LoadUserLayouts() errors.E { // {{{
+	if loadErr != nil {
+		return errors.WithMessage(loadErr, i18n.T("Los layouts incluidos en Thot no tienen un formato válido"))
+	}
+	list, err := readUser()
+	if err != nil {
+		return err
+	}
+	for name, k := range list {
+		if _, ok := layouts[name]; ok && !user[name] {
+			continue
+		}
+		layouts[name] = k
+		user[name] = true
+	}
+
+	return nil
+} // }}}
+
+// IsUserLayout indica si el layout `name` fue creado por el usuario.
+func IsUserLayout(name string) bool { // {{{
+	return user[name]
+} // }}}
+
+// CheckUserName verifica que `name` se pueda usar para guardar un layout del usuario: solo puede tener
+// letras, números, `_` y `-`, y no puede ser el nombre de un layout incluido en Thot.
+func CheckUserName(name string) errors.E { // {{{
+	if !reName.MatchString(name) {
+		return errors.New(i18n.T("El nombre %q no es válido, solo puede tener letras, números, `_` y `-`", name))
+	}
+	if _, ok := layouts[name]; ok && !user[name] {
+		return errors.New(i18n.T("El layout %q viene incluido en Thot, se debe guardar con otro nombre", name))
+	}
+
+	return nil
+} // }}}
+
+// SaveUserLayout guarda el layout `k` con el nombre `name` en el archivo de layouts del usuario, si ya existe
+// un layout del usuario con ese nombre lo reemplaza.
+func SaveUserLayout(name string, k *Keyboard) errors.E { // {{{
+	if err := CheckUserName(name); err != nil {
+		return err
+	}
+	path, err := UserPath()
+	if err != nil {
+		return err
+	}
+	list, err := readUser()
+	if err != nil {
+		return err
+	}
+	list[name] = *k
+
+	// Sin escapar `&`, `<` y `>` para que el archivo se pueda editar a mano
+	buf := bytes.Buffer{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if e := enc.Encode(list); e != nil {
+		return errors.WithMessage(e, i18n.T("No se pudo generar el archivo de layouts"))
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return errors.WithDetails(
+			errors.WithMessage(err, i18n.T("No se pudo guardar el archivo de layouts")),
+			"path",
+			path,
+		)
+	}
+	layouts[name] = *k
+	user[name] = true
+
+	return nil
+} // }}}
+
+// readUser lee el archivo de layouts del usuario, si no existe devuelve una lista vacía.
+func readUser() (map[string]Keyboard, errors.E) { // {{{
+	list := make(map[string]Keyboard)
+	path, err := UserPath()
+	if err != nil {
+		return list, err
+	}
+	data, e := os.ReadFile(path)
+	if os.IsNotExist(e) {
+		return list, nil
+	}
+	if e != nil {
+		return list, errors.WithDetails(
+			errors.WithMessage(e, i18n.T("No se pudo leer el archivo de layouts")),
+			"path",
+			path,
+		)
+	}
+	if e := json.Unmarshal(data, &list); e != nil {
+		return list, errors.WithDetails(
+			errors.WithMessage(e, i18n.T("El archivo de layouts no tiene un formato válido")),
+			"path",
+			path,
+		)
+	}
+
+	return list, nil
+} // }}}
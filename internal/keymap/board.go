@@ -0,0 +1,279 @@
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Slot es una tecla física de una placa: la tecla `Col` de la fila `Row` del layout o, si `Fixed` no está
+// vacío, una tecla que no depende del layout como `TAB` o `SPC`. Las teclas del pulgar tienen las dos, se
+// usa la tecla del layout si la tiene y si no la fija.
+type Slot struct {
+	Row   string
+	Col   int
+	Fixed string
+}
+
+// Board es la plantilla de un teclado físico. Las placas con `Type` solo aceptan layouts de ese tipo y sus
+// columnas son las del layout; en las placas matriciales (`Type` vacío) las columnas son las de ANSI y en
+// los layouts ISO la fila inferior se desplaza una tecla para saltar la tecla junto a Shift.
+type Board struct {
+	Name string
+	// Macro es el nombre de la macro de QMK que ordena las teclas de la placa.
+	Macro string
+	Type  string
+	// Layers son las capas de la placa, cada capa es una lista de filas y la capa 1 se activa con `MO1`.
+	Layers [][][]Slot
+}
+
+// Teclas fijas de las placas, se traducen al nombre de cada firmware.
+const (
+	keyNone  = "NO"
+	keyTrans = "TRNS"
+	keyLayer = "MO1"
+)
+
+// key es la tecla `col` de la fila `row`, fixed crea una tecla fija, thumb una tecla del pulgar que sin
+// tecla en el layout es la tecla fija `name` y keys una secuencia de teclas.
+func key(row string, col int) Slot    { return Slot{Row: row, Col: col} }
+func fixed(name string) Slot          { return Slot{Fixed: name} }
+func thumb(col int, name string) Slot { return Slot{Row: kbd.Thumb, Col: col, Fixed: name} }
+
+func keys(row string, from, to int) []Slot { // {{{
+	list := make([]Slot, 0, to-from+1)
+	for col := from; col <= to; col++ {
+		list = append(list, key(row, col))
+	}
+
+	return list
+} // }}}
+
+func join(parts ...any) []Slot { // {{{
+	var list []Slot
+	for _, p := range parts {
+		switch p := p.(type) {
+		case Slot:
+			list = append(list, p)
+		case []Slot:
+			list = append(list, p...)
+		case string:
+			list = append(list, fixed(p))
+		}
+	}
+
+	return list
+} // }}}
+
+func repeat(name string, n int) []Slot { // {{{
+	list := make([]Slot, n)
+	for i := range list {
+		list[i] = fixed(name)
+	}
+
+	return list
+} // }}}
+
+var modsRow = join("LCTL", "LGUI", "LALT", "SPC", "RALT", "RGUI", "APP", "RCTL")
+
+var boards = map[string]Board{
+	"60_ansi": {
+		Name:  "60_ansi",
+		Macro: "LAYOUT_60_ansi",
+		Type:  "ansi",
+		Layers: [][][]Slot{{
+			join(keys(kbd.Row1, 0, 12), "BSPC"),
+			join("TAB", keys(kbd.Row2, 0, 12)),
+			join("CAPS", keys(kbd.Row3, 0, 10), "ENT"),
+			join("LSFT", keys(kbd.Row4, 0, 9), "RSFT"),
+			modsRow,
+		}},
+	},
+	"60_iso": {
+		Name:  "60_iso",
+		Macro: "LAYOUT_60_iso",
+		Type:  "iso",
+		Layers: [][][]Slot{{
+			join(keys(kbd.Row1, 0, 12), "BSPC"),
+			join("TAB", keys(kbd.Row2, 0, 11)),
+			join("CAPS", keys(kbd.Row3, 0, 11), "ENT"),
+			join("LSFT", keys(kbd.Row4, 0, 10), "RSFT"),
+			modsRow,
+		}},
+	},
+	"ortho_4x12": {
+		Name:  "ortho_4x12",
+		Macro: "LAYOUT_ortho_4x12",
+		Layers: [][][]Slot{
+			{
+				join("TAB", keys(kbd.Row2, 0, 9), "BSPC"),
+				join("ESC", keys(kbd.Row3, 0, 10)),
+				join("LSFT", keys(kbd.Row4, 0, 9), "ENT"),
+				join(
+					"LCTL", "LGUI", "LALT", keyLayer, thumb(1, "SPC"), thumb(2, "SPC"), thumb(3, "SPC"), thumb(4, "SPC"),
+					keyLayer, "RALT", "RGUI", "RCTL",
+				),
+			},
+			{
+				join(keys(kbd.Row1, 0, 11)),
+				join(keyTrans, key(kbd.Row1, 12), keys(kbd.Row2, 10, 12), repeat(keyTrans, 7)),
+				repeat(keyTrans, 12),
+				repeat(keyTrans, 12),
+			},
+		},
+	},
+	"split_3x5_3": {
+		Name:  "split_3x5_3",
+		Macro: "LAYOUT_split_3x5_3",
+		Layers: [][][]Slot{
+			{
+				join(keys(kbd.Row2, 0, 9)),
+				join(keys(kbd.Row3, 0, 9)),
+				join(keys(kbd.Row4, 0, 9)),
+				join(thumb(0, "TAB"), thumb(1, "SPC"), thumb(2, "LSFT"), thumb(3, "ENT"), thumb(4, "BSPC"), keyLayer),
+			},
+			{
+				join(keys(kbd.Row1, 1, 10)),
+				join(key(kbd.Row1, 0), key(kbd.Row1, 11), key(kbd.Row1, 12), keys(kbd.Row2, 10, 12), key(kbd.Row3, 10), "ESC", keyTrans, keyTrans),
+				join("LCTL", "LGUI", "LALT", repeat(keyTrans, 4), "RALT", "RGUI", "RCTL"),
+				repeat(keyTrans, 6),
+			},
+		},
+	},
+}
+
+// Boards devuelve los nombres de las placas disponibles ordenados alfabéticamente.
+func Boards() []string { // {{{
+	names := make([]string, 0, len(boards))
+	for name := range boards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+} // }}}
+
+// defaultBoards son las placas de cada tipo de teclado cuando no se elige una.
+var defaultBoards = map[string]string{
+	"ansi":     "60_ansi",
+	"iso":      "60_iso",
+	"ortho":    "ortho_4x12",
+	"split":    "split_3x5_3",
+	"columnar": "split_3x5_3",
+}
+
+// findBoard busca la placa `name`, si está vacío elige la placa del tipo del layout. Los layouts de teclados
+// matriciales tienen las mismas filas que ANSI y se pueden usar en las placas ANSI.
+func findBoard(name string, k *kbd.Keyboard) (Board, errors.E) { // {{{
+	if name == "" {
+		name = defaultBoards[k.Type]
+	}
+	b, ok := boards[name]
+	if !ok {
+		return b, errors.New(i18n.T(
+			"La placa %q no es válida, acepta solo los valores %s",
+			name,
+			"`"+strings.Join(Boards(), "`, `")+"`",
+		))
+	}
+	if b.Type != "" && (b.Type == "iso") != (k.Type == "iso") {
+		return b, errors.New(i18n.T("La placa %q es para layouts %s y el layout es %s", name, strings.ToUpper(b.Type), strings.ToUpper(k.Type)))
+	}
+
+	return b, nil
+} // }}}
+
+// usKey es una tecla del layout de Estados Unidos: su código y el carácter que produce con Shift. Los
+// firmware envían la posición de la tecla, el sistema operativo debe usar ese layout.
+type usKey struct {
+	code  string
+	shift rune
+}
+
+// usKeys son las teclas del layout de Estados Unidos según el carácter que producen sin Shift.
+var usKeys = map[rune]usKey{
+	'`': {"GRV", '~'}, '1': {"1", '!'}, '2': {"2", '@'}, '3': {"3", '#'}, '4': {"4", '$'}, '5': {"5", '%'},
+	'6': {"6", '^'}, '7': {"7", '&'}, '8': {"8", '*'}, '9': {"9", '('}, '0': {"0", ')'}, '-': {"MINS", '_'},
+	'=': {"EQL", '+'}, '[': {"LBRC", '{'}, ']': {"RBRC", '}'}, '\\': {"BSLS", '|'}, ';': {"SCLN", ':'},
+	'\'': {"QUOT", '"'}, ',': {"COMM", '<'}, '.': {"DOT", '>'}, '/': {"SLSH", '?'},
+}
+
+// resolve devuelve los códigos de cada capa de la placa para el layout `k` con los nombres de QMK sin el
+// prefijo `KC_`, y los avisos de las teclas que no se pueden representar: caracteres que no existen en el
+// layout de Estados Unidos, teclas cuyo Shift produce otro carácter y teclas que no tienen lugar en la placa.
+func resolve(b Board, k *kbd.Keyboard) ([][][]string, []string) { // {{{
+	var warnings []string
+	placed := make(map[Slot]bool)
+	layers := make([][][]string, len(b.Layers))
+	for l, layer := range b.Layers {
+		for _, row := range layer {
+			line := make([]string, 0, len(row))
+			for _, s := range row {
+				col := s.Col
+				if b.Type == "" && k.Type == "iso" && s.Row == kbd.Row4 {
+					col++
+				}
+				list := k.Keys[s.Row]
+				if s.Fixed != "" && (s.Row == "" || col >= len(list) || list[col] == "") {
+					line = append(line, s.Fixed)
+					continue
+				}
+				if col >= len(list) {
+					line = append(line, keyNone)
+					continue
+				}
+				placed[Slot{Row: s.Row, Col: col}] = true
+				code, warning := usCode(list[col])
+				if warning != "" {
+					warnings = append(warnings, warning)
+				}
+				line = append(line, code)
+			}
+			layers[l] = append(layers[l], line)
+		}
+	}
+
+	for _, row := range append(rows, kbd.Thumb) {
+		for col, key := range k.Keys[row] {
+			if key != "" && !placed[Slot{Row: row, Col: col}] {
+				warnings = append(warnings, i18n.T("La tecla %s no tiene lugar en la placa", label(key)))
+			}
+		}
+	}
+
+	return layers, warnings
+} // }}}
+
+// usCode devuelve el código de la tecla del layout de Estados Unidos que produce el carácter sin Shift de
+// `key`, o `NO` y un aviso si no existe.
+func usCode(key string) (string, string) { // {{{
+	lower, upper, ok := levels(key)
+	if !ok {
+		return keyNone, ""
+	}
+	if lower < unicode.MaxASCII && unicode.IsLetter(lower) {
+		if unicode.ToUpper(lower) != upper {
+			return string(unicode.ToUpper(lower)), i18n.T("Con Shift la tecla %s produce %q en lugar de %q", label(key), unicode.ToUpper(lower), upper)
+		}
+		return string(unicode.ToUpper(lower)), ""
+	}
+	us, ok := usKeys[lower]
+	if !ok {
+		return keyNone, i18n.T("La tecla %s no existe en el layout de Estados Unidos", label(key))
+	}
+	if us.shift != upper {
+		return us.code, i18n.T("Con Shift la tecla %s produce %q en lugar de %q", label(key), us.shift, upper)
+	}
+
+	return us.code, ""
+} // }}}
+
+// label muestra los caracteres de una tecla, p.e. `[q Q]`.
+func label(key string) string { // {{{
+	return fmt.Sprintf("[%s]", strings.Join(strings.Split(key, ""), " "))
+} // }}}
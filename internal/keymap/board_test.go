@@ -0,0 +1,252 @@
+package keymap
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+)
+
+// layer devuelve las filas de una capa escritas como en `resolve`, una fila por cadena.
+func layer(rows ...string) [][]string { // {{{
+	list := make([][]string, len(rows))
+	for i, row := range rows {
+		list[i] = strings.Fields(row)
+	}
+
+	return list
+} // }}}
+
+// withKeys devuelve una copia del layout `name` con las filas de `keys` reemplazadas.
+func withKeys(name, typ string, keys map[string][]string) *kbd.Keyboard { // {{{
+	k := kbd.FindLayout(name).Clone()
+	if typ != "" {
+		k.Type = typ
+	}
+	for row, list := range keys {
+		k.Keys[row] = list
+	}
+
+	return k
+} // }}}
+
+func TestResolve(t *testing.T) { // {{{
+	noPlace := func(key string) string { return i18n.T("La tecla %s no tiene lugar en la placa", label(key)) }
+	qwertyISO := withKeys("qwerty", "iso", map[string][]string{
+		kbd.Row2: {"qQ", "wW", "eE", "rR", "tT", "yY", "uU", "iI", "oO", "pP", "[{", "]}"},
+		kbd.Row3: {"aA", "sS", "dD", "fF", "gG", "hH", "jJ", "kK", "lL", ";:", "'\"", "\\|"},
+		kbd.Row4: {"-_", "zZ", "xX", "cC", "vV", "bB", "nN", "mM", ",<", ".>", "/?"},
+	})
+
+	tests := []struct {
+		name     string
+		board    string
+		k        *kbd.Keyboard
+		layers   [][][]string
+		warnings []string
+	}{
+		{
+			name:  "qwerty en 60_ansi",
+			board: "60_ansi",
+			k:     kbd.FindLayout("qwerty"),
+			layers: [][][]string{layer(
+				"GRV 1 2 3 4 5 6 7 8 9 0 MINS EQL BSPC",
+				"TAB Q W E R T Y U I O P LBRC RBRC BSLS",
+				"CAPS A S D F G H J K L SCLN QUOT ENT",
+				"LSFT Z X C V B N M COMM DOT SLSH RSFT",
+				"LCTL LGUI LALT SPC RALT RGUI APP RCTL",
+			)},
+		},
+		{
+			name:  "ISO en 60_iso",
+			board: "60_iso",
+			k:     qwertyISO,
+			layers: [][][]string{layer(
+				"GRV 1 2 3 4 5 6 7 8 9 0 MINS EQL BSPC",
+				"TAB Q W E R T Y U I O P LBRC RBRC",
+				"CAPS A S D F G H J K L SCLN QUOT BSLS ENT",
+				"LSFT MINS Z X C V B N M COMM DOT SLSH RSFT",
+				"LCTL LGUI LALT SPC RALT RGUI APP RCTL",
+			)},
+		},
+		{
+			name:  "colemak_dh_matrix en 60_ansi",
+			board: "60_ansi",
+			k:     kbd.FindLayout("colemak_dh_matrix"),
+			layers: [][][]string{layer(
+				"GRV 1 2 3 4 5 6 7 8 9 0 MINS EQL BSPC",
+				"TAB Q W F P B J L U Y SCLN LBRC RBRC BSLS",
+				"CAPS A R S T G M N E I O QUOT ENT",
+				"LSFT Z X C D V K H COMM DOT SLSH RSFT",
+				"LCTL LGUI LALT SPC RALT RGUI APP RCTL",
+			)},
+		},
+		{
+			name:  "colemak_dh_matrix en ortho_4x12",
+			board: "ortho_4x12",
+			k:     kbd.FindLayout("colemak_dh_matrix"),
+			layers: [][][]string{
+				layer(
+					"TAB Q W F P B J L U Y SCLN BSPC",
+					"ESC A R S T G M N E I O QUOT",
+					"LSFT Z X C D V K H COMM DOT SLSH ENT",
+					"LCTL LGUI LALT MO1 SPC SPC SPC SPC MO1 RALT RGUI RCTL",
+				),
+				layer(
+					"GRV 1 2 3 4 5 6 7 8 9 0 MINS",
+					"TRNS EQL LBRC RBRC BSLS TRNS TRNS TRNS TRNS TRNS TRNS TRNS",
+					"TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS",
+					"TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS",
+				),
+			},
+		},
+		{
+			name:  "colemak_dh_matrix en split_3x5_3",
+			board: "split_3x5_3",
+			k:     kbd.FindLayout("colemak_dh_matrix"),
+			layers: [][][]string{
+				layer(
+					"Q W F P B J L U Y SCLN",
+					"A R S T G M N E I O",
+					"Z X C D V K H COMM DOT SLSH",
+					"TAB SPC LSFT ENT BSPC MO1",
+				),
+				layer(
+					"1 2 3 4 5 6 7 8 9 0",
+					"GRV MINS EQL LBRC RBRC BSLS QUOT ESC TRNS TRNS",
+					"LCTL LGUI LALT TRNS TRNS TRNS TRNS RALT RGUI RCTL",
+					"TRNS TRNS TRNS TRNS TRNS TRNS",
+				),
+			},
+		},
+		{
+			// Las teclas del pulgar del layout reemplazan a las fijas, las vacías las conservan
+			name:  "teclas del pulgar en split_3x5_3",
+			board: "split_3x5_3",
+			k:     withKeys("colemak_dh_matrix", "", map[string][]string{kbd.Thumb: {"", "", "", "-_"}}),
+			layers: [][][]string{
+				layer(
+					"Q W F P B J L U Y SCLN",
+					"A R S T G M N E I O",
+					"Z X C D V K H COMM DOT SLSH",
+					"TAB SPC LSFT MINS BSPC MO1",
+				),
+				layer(
+					"1 2 3 4 5 6 7 8 9 0",
+					"GRV MINS EQL LBRC RBRC BSLS QUOT ESC TRNS TRNS",
+					"LCTL LGUI LALT TRNS TRNS TRNS TRNS RALT RGUI RCTL",
+					"TRNS TRNS TRNS TRNS TRNS TRNS",
+				),
+			},
+		},
+		{
+			// Una fila más larga que la placa avisa de las teclas que sobran, una más corta deja teclas sin asignar
+			name:  "fila más larga que ortho_4x12",
+			board: "ortho_4x12",
+			k: withKeys("colemak_dh_matrix", "", map[string][]string{
+				kbd.Row3: {"aA", "rR", "sS", "tT", "gG", "mM", "nN", "eE", "iI", "oO", "'\"", "\\|"},
+				kbd.Row4: {"zZ", "xX", "cC", "dD", "vV", "kK", "hH", ",<"},
+			}),
+			layers: [][][]string{
+				layer(
+					"TAB Q W F P B J L U Y SCLN BSPC",
+					"ESC A R S T G M N E I O QUOT",
+					"LSFT Z X C D V K H COMM NO NO ENT",
+					"LCTL LGUI LALT MO1 SPC SPC SPC SPC MO1 RALT RGUI RCTL",
+				),
+				layer(
+					"GRV 1 2 3 4 5 6 7 8 9 0 MINS",
+					"TRNS EQL LBRC RBRC BSLS TRNS TRNS TRNS TRNS TRNS TRNS TRNS",
+					"TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS",
+					"TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS TRNS",
+				),
+			},
+			warnings: []string{noPlace("\\|")},
+		},
+		{
+			name:  "fila más larga que 60_ansi",
+			board: "60_ansi",
+			k: withKeys("qwerty", "", map[string][]string{
+				kbd.Row4: {"zZ", "xX", "cC", "vV", "bB", "nN", "mM", ",<", ".>", "/?", "-_"},
+			}),
+			layers: [][][]string{layer(
+				"GRV 1 2 3 4 5 6 7 8 9 0 MINS EQL BSPC",
+				"TAB Q W E R T Y U I O P LBRC RBRC BSLS",
+				"CAPS A S D F G H J K L SCLN QUOT ENT",
+				"LSFT Z X C V B N M COMM DOT SLSH RSFT",
+				"LCTL LGUI LALT SPC RALT RGUI APP RCTL",
+			)},
+			warnings: []string{noPlace("-_")},
+		},
+		{
+			// En las placas matriciales la fila inferior de ISO salta la tecla junto a Shift
+			name:  "ISO en split_3x5_3",
+			board: "split_3x5_3",
+			k:     qwertyISO,
+			layers: [][][]string{
+				layer(
+					"Q W E R T Y U I O P",
+					"A S D F G H J K L SCLN",
+					"Z X C V B N M COMM DOT SLSH",
+					"TAB SPC LSFT ENT BSPC MO1",
+				),
+				layer(
+					"1 2 3 4 5 6 7 8 9 0",
+					"GRV MINS EQL LBRC RBRC NO QUOT ESC TRNS TRNS",
+					"LCTL LGUI LALT TRNS TRNS TRNS TRNS RALT RGUI RCTL",
+					"TRNS TRNS TRNS TRNS TRNS TRNS",
+				),
+			},
+			warnings: []string{noPlace("\\|"), noPlace("-_")},
+		},
+	}
+	for _, tt := range tests {
+		b, err := findBoard(tt.board, tt.k)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		layers, warnings := resolve(b, tt.k)
+		if len(layers) != len(tt.layers) {
+			t.Errorf("%s: tiene %d capas, se esperaban %d", tt.name, len(layers), len(tt.layers))
+			continue
+		}
+		for l := range layers {
+			if !slices.EqualFunc(layers[l], tt.layers[l], slices.Equal) {
+				t.Errorf("%s: la capa %d es\n%q\nse esperaba\n%q", tt.name, l, layers[l], tt.layers[l])
+			}
+		}
+		if !slices.Equal(warnings, tt.warnings) {
+			t.Errorf("%s: avisa %q, se esperaba %q", tt.name, warnings, tt.warnings)
+		}
+	}
+} // }}}
+
+func TestFindBoard(t *testing.T) { // {{{
+	tests := []struct {
+		board  string
+		layout string
+		want   string
+		err    bool
+	}{
+		{"", "qwerty", "60_ansi", false},
+		{"", "spanish_qwerty", "60_iso", false},
+		{"", "colemak_dh_matrix", "ortho_4x12", false},
+		{"split_3x5_3", "spanish_qwerty", "split_3x5_3", false},
+		{"60_iso", "qwerty", "", true},
+		{"60_ansi", "spanish_qwerty", "", true},
+		{"planck", "qwerty", "", true},
+	}
+	for _, tt := range tests {
+		b, err := findBoard(tt.board, kbd.FindLayout(tt.layout))
+		if (err != nil) != tt.err {
+			t.Errorf("findBoard(%q, %s) devolvió el error %v", tt.board, tt.layout, err)
+			continue
+		}
+		if err == nil && b.Name != tt.want {
+			t.Errorf("findBoard(%q, %s) = %s, se esperaba %s", tt.board, tt.layout, b.Name, tt.want)
+		}
+	}
+} // }}}
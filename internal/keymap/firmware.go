@@ -0,0 +1,249 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+// zmkNames son los nombres de ZMK de las teclas cuyo nombre es distinto en QMK.
+var zmkNames = map[string]string{
+	"GRV": "GRAVE", "MINS": "MINUS", "EQL": "EQUAL", "LBRC": "LBKT", "RBRC": "RBKT", "BSLS": "BSLH",
+	"SCLN": "SEMI", "QUOT": "SQT", "COMM": "COMMA", "SLSH": "FSLH", "ENT": "RET", "SPC": "SPACE",
+	"LSFT": "LSHFT", "RSFT": "RSHFT", "LCTL": "LCTRL", "RCTL": "RCTRL", "APP": "K_APP",
+	"1": "N1", "2": "N2", "3": "N3", "4": "N4", "5": "N5", "6": "N6", "7": "N7", "8": "N8", "9": "N9", "0": "N0",
+}
+
+// kmonadNames son los nombres de KMonad de las teclas que no son una letra o un número.
+var kmonadNames = map[string]string{
+	"GRV": "grv", "MINS": "-", "EQL": "=", "LBRC": "[", "RBRC": "]", "BSLS": "\\", "SCLN": ";", "QUOT": "'",
+	"COMM": ",", "DOT": ".", "SLSH": "/", "ESC": "esc", "TAB": "tab", "BSPC": "bspc", "ENT": "ret",
+	"CAPS": "caps", "SPC": "spc", "LSFT": "lsft", "RSFT": "rsft", "LCTL": "lctl", "RCTL": "rctl",
+	"LGUI": "lmet", "RGUI": "rmet", "LALT": "lalt", "RALT": "ralt", "APP": "cmp", keyNone: "XX", keyTrans: "_",
+}
+
+// kmonadSource son las teclas físicas de un teclado de Estados Unidos en cada posición del layout, KMonad
+// reasigna las teclas de un teclado normal en lugar de programar su firmware.
+var kmonadSource = map[string]map[string][]string{
+	"ansi": {
+		kbd.Row1: strings.Fields("grv 1 2 3 4 5 6 7 8 9 0 - ="),
+		kbd.Row2: strings.Fields(`q w e r t y u i o p [ ] \`),
+		kbd.Row3: strings.Fields("a s d f g h j k l ; '"),
+		kbd.Row4: strings.Fields("z x c v b n m , . /"),
+	},
+	"iso": {
+		kbd.Row1: strings.Fields("grv 1 2 3 4 5 6 7 8 9 0 - ="),
+		kbd.Row2: strings.Fields("q w e r t y u i o p [ ]"),
+		kbd.Row3: strings.Fields(`a s d f g h j k l ; ' \`),
+		kbd.Row4: strings.Fields("102d z x c v b n m , . /"),
+	},
+}
+
+func init() { // {{{
+	register(Format{
+		Name:    "qmk",
+		File:    func(string) string { return "keymap.c" },
+		Export:  QMK,
+		Boards:  true,
+		Install: qmkInstall,
+	})
+	register(Format{
+		Name:    "zmk",
+		File:    func(name string) string { return "thot_" + name + ".keymap" },
+		Export:  ZMK,
+		Boards:  true,
+		Install: zmkInstall,
+	})
+	register(Format{
+		Name:    "kmonad",
+		File:    func(name string) string { return "thot_" + name + ".kbd" },
+		Export:  KMonad,
+		Boards:  true,
+		Install: kmonadInstall,
+	})
+} // }}}
+
+// QMK genera el archivo `keymap.c` de QMK del layout `name` para la placa de `opts`.
+func QMK(name string, k *kbd.Keyboard, opts Options) ([]byte, []string, errors.E) { // {{{
+	b, err := findBoard(opts.Board, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	layers, warnings := resolve(b, k)
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("// Layout %q generado por Thot para la placa %s\n", name, b.Name))
+	sb.WriteString("#include QMK_KEYBOARD_H\n\n")
+	sb.WriteString("const uint16_t PROGMEM keymaps[][MATRIX_ROWS][MATRIX_COLS] = {\n")
+	for l, layer := range layers {
+		sb.WriteString(fmt.Sprintf("    [%d] = %s(\n", l, b.Macro))
+		for r, row := range layer {
+			codes := make([]string, len(row))
+			for i, code := range row {
+				codes[i] = qmkName(code)
+			}
+			sep := ","
+			if r == len(layer)-1 {
+				sep = ""
+			}
+			sb.WriteString("        " + strings.Join(codes, ", ") + sep + "\n")
+		}
+		sb.WriteString("    ),\n")
+	}
+	sb.WriteString("};\n")
+
+	return []byte(sb.String()), warnings, nil
+} // }}}
+
+func qmkName(code string) string { // {{{
+	if code == keyLayer {
+		return "MO(1)"
+	}
+
+	return "KC_" + code
+} // }}}
+
+// ZMK genera el archivo `.keymap` de ZMK del layout `name` para la placa de `opts`.
+func ZMK(name string, k *kbd.Keyboard, opts Options) ([]byte, []string, errors.E) { // {{{
+	b, err := findBoard(opts.Board, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	layers, warnings := resolve(b, k)
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("// Layout %q generado por Thot para la placa %s\n", name, b.Name))
+	sb.WriteString("#include <behaviors.dtsi>\n")
+	sb.WriteString("#include <dt-bindings/zmk/keys.h>\n\n")
+	sb.WriteString("/ {\n")
+	sb.WriteString("    keymap {\n")
+	sb.WriteString("        compatible = \"zmk,keymap\";\n")
+	for l, layer := range layers {
+		sb.WriteString(fmt.Sprintf("\n        layer_%d {\n", l))
+		sb.WriteString(fmt.Sprintf("            display-name = \"%s\";\n", zmkLayerName(name, l)))
+		sb.WriteString("            bindings = <\n")
+		for _, row := range layer {
+			codes := make([]string, len(row))
+			for i, code := range row {
+				codes[i] = zmkName(code)
+			}
+			sb.WriteString("                " + strings.Join(codes, " ") + "\n")
+		}
+		sb.WriteString("            >;\n")
+		sb.WriteString("        };\n")
+	}
+	sb.WriteString("    };\n")
+	sb.WriteString("};\n")
+
+	return []byte(sb.String()), warnings, nil
+} // }}}
+
+func zmkName(code string) string { // {{{
+	switch code {
+	case keyNone:
+		return "&none"
+	case keyTrans:
+		return "&trans"
+	case keyLayer:
+		return "&mo 1"
+	}
+	if n, ok := zmkNames[code]; ok {
+		code = n
+	}
+
+	return "&kp " + code
+} // }}}
+
+// zmkLayerName devuelve el nombre de la capa `l` que se muestra en las pantallas de ZMK.
+func zmkLayerName(name string, l int) string { // {{{
+	if l == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s %d", name, l)
+} // }}}
+
+// KMonad genera el archivo `.kbd` de KMonad del layout `name`. KMonad reasigna un teclado ANSI o ISO normal,
+// por eso solo admite las placas de 60%.
+func KMonad(name string, k *kbd.Keyboard, opts Options) ([]byte, []string, errors.E) { // {{{
+	b, err := findBoard(opts.Board, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b.Type == "" {
+		return nil, nil, errors.New(i18n.T("KMonad solo admite las placas `60_ansi` y `60_iso`"))
+	}
+	layers, warnings := resolve(b, k)
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf(";; Layout %q generado por Thot\n\n", name))
+	sb.WriteString("(defcfg\n")
+	sb.WriteString("  input  (device-file \"/dev/input/by-path/platform-i8042-serio-0-event-kbd\")\n")
+	sb.WriteString(fmt.Sprintf("  output (uinput-sink \"thot %s\")\n", name))
+	sb.WriteString("  fallthrough true)\n\n")
+
+	source := kmonadSource[b.Type]
+	sb.WriteString("(defsrc\n")
+	for _, row := range b.Layers[0] {
+		names := make([]string, len(row))
+		for i, s := range row {
+			if s.Fixed != "" {
+				names[i] = kmonadName(s.Fixed)
+				continue
+			}
+			names[i] = source[s.Row][s.Col]
+		}
+		sb.WriteString("  " + strings.Join(names, " ") + "\n")
+	}
+	sb.WriteString(")\n\n")
+
+	sb.WriteString("(deflayer thot\n")
+	for _, row := range layers[0] {
+		names := make([]string, len(row))
+		for i, code := range row {
+			names[i] = kmonadName(code)
+		}
+		sb.WriteString("  " + strings.Join(names, " ") + "\n")
+	}
+	sb.WriteString(")\n")
+
+	return []byte(sb.String()), warnings, nil
+} // }}}
+
+func kmonadName(code string) string { // {{{
+	if n, ok := kmonadNames[code]; ok {
+		return n
+	}
+
+	return strings.ToLower(code)
+} // }}}
+
+func qmkInstall(name, path string) string { // {{{
+	return strings.Join([]string{
+		i18n.T("En QMK:"),
+		i18n.T("  1. Copiar %s a `keyboards/<teclado>/keymaps/thot/keymap.c`", path),
+		"  2. qmk compile -kb <teclado> -km thot",
+		i18n.T("  3. Configurar el sistema operativo con el layout de Estados Unidos"),
+	}, "\n")
+} // }}}
+
+func zmkInstall(name, path string) string { // {{{
+	return strings.Join([]string{
+		i18n.T("En ZMK:"),
+		i18n.T("  1. Reemplazar `config/<placa>.keymap` del repositorio zmk-config con %s", path),
+		i18n.T("  2. Subir el cambio para que GitHub Actions compile el firmware y grabarlo en el teclado"),
+		i18n.T("  3. Configurar el sistema operativo con el layout de Estados Unidos"),
+	}, "\n")
+} // }}}
+
+func kmonadInstall(name, path string) string { // {{{
+	return strings.Join([]string{
+		i18n.T("En KMonad:"),
+		i18n.T("  1. Cambiar `device-file` en %s por el teclado a reasignar (`ls /dev/input/by-id`)", path),
+		fmt.Sprintf("  2. kmonad %s", path),
+		i18n.T("  3. Configurar el sistema operativo con el layout de Estados Unidos"),
+	}, "\n")
+} // }}}
@@ -0,0 +1,157 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wrodriguez/thot/internal/kbd"
+)
+
+func TestFirmware(t *testing.T) { // {{{
+	tests := []struct {
+		format string
+		board  string
+		layout string
+		// lines son líneas completas que debe tener el archivo, en orden
+		lines []string
+		err   bool
+	}{
+		{
+			format: "qmk", board: "60_ansi", layout: "qwerty",
+			lines: []string{
+				"    [0] = LAYOUT_60_ansi(",
+				"        KC_GRV, KC_1, KC_2, KC_3, KC_4, KC_5, KC_6, KC_7, KC_8, KC_9, KC_0, KC_MINS, KC_EQL, KC_BSPC,",
+				"        KC_TAB, KC_Q, KC_W, KC_E, KC_R, KC_T, KC_Y, KC_U, KC_I, KC_O, KC_P, KC_LBRC, KC_RBRC, KC_BSLS,",
+				"        KC_LCTL, KC_LGUI, KC_LALT, KC_SPC, KC_RALT, KC_RGUI, KC_APP, KC_RCTL",
+				"    ),",
+				"};",
+			},
+		},
+		{
+			format: "qmk", board: "60_iso", layout: "spanish_qwerty",
+			lines: []string{
+				"    [0] = LAYOUT_60_iso(",
+				"        KC_CAPS, KC_A, KC_S, KC_D, KC_F, KC_G, KC_H, KC_J, KC_K, KC_L, KC_NO, KC_NO, KC_NO, KC_ENT,",
+				"        KC_LSFT, KC_NO, KC_Z, KC_X, KC_C, KC_V, KC_B, KC_N, KC_M, KC_COMM, KC_DOT, KC_MINS, KC_RSFT,",
+			},
+		},
+		{
+			format: "qmk", board: "ortho_4x12", layout: "colemak_dh_matrix",
+			lines: []string{
+				"    [0] = LAYOUT_ortho_4x12(",
+				"        KC_TAB, KC_Q, KC_W, KC_F, KC_P, KC_B, KC_J, KC_L, KC_U, KC_Y, KC_SCLN, KC_BSPC,",
+				"        KC_LCTL, KC_LGUI, KC_LALT, MO(1), KC_SPC, KC_SPC, KC_SPC, KC_SPC, MO(1), KC_RALT, KC_RGUI, KC_RCTL",
+				"    [1] = LAYOUT_ortho_4x12(",
+				"        KC_GRV, KC_1, KC_2, KC_3, KC_4, KC_5, KC_6, KC_7, KC_8, KC_9, KC_0, KC_MINS,",
+				"        KC_TRNS, KC_EQL, KC_LBRC, KC_RBRC, KC_BSLS, KC_TRNS, KC_TRNS, KC_TRNS, KC_TRNS, KC_TRNS, KC_TRNS, KC_TRNS,",
+			},
+		},
+		{
+			format: "qmk", board: "split_3x5_3", layout: "colemak_dh_matrix",
+			lines: []string{
+				"    [0] = LAYOUT_split_3x5_3(",
+				"        KC_Z, KC_X, KC_C, KC_D, KC_V, KC_K, KC_H, KC_COMM, KC_DOT, KC_SLSH,",
+				"        KC_TAB, KC_SPC, KC_LSFT, KC_ENT, KC_BSPC, MO(1)",
+				"    [1] = LAYOUT_split_3x5_3(",
+				"        KC_GRV, KC_MINS, KC_EQL, KC_LBRC, KC_RBRC, KC_BSLS, KC_QUOT, KC_ESC, KC_TRNS, KC_TRNS,",
+			},
+		},
+		{
+			format: "qmk", board: "60_iso", layout: "qwerty",
+			err: true,
+		},
+		{
+			format: "zmk", board: "60_ansi", layout: "colemak_dh_matrix",
+			lines: []string{
+				"        layer_0 {",
+				`            display-name = "colemak_dh_matrix";`,
+				"                &kp GRAVE &kp N1 &kp N2 &kp N3 &kp N4 &kp N5 &kp N6 &kp N7 &kp N8 &kp N9 &kp N0 &kp MINUS &kp EQUAL &kp BSPC",
+				"                &kp CAPS &kp A &kp R &kp S &kp T &kp G &kp M &kp N &kp E &kp I &kp O &kp SQT &kp RET",
+				"                &kp LCTRL &kp LGUI &kp LALT &kp SPACE &kp RALT &kp RGUI &kp K_APP &kp RCTRL",
+			},
+		},
+		{
+			format: "zmk", board: "60_iso", layout: "spanish_qwerty",
+			lines: []string{
+				"                &none &kp N1 &kp N2 &kp N3 &kp N4 &kp N5 &kp N6 &kp N7 &kp N8 &kp N9 &kp N0 &kp SQT &none &kp BSPC",
+				"                &kp LSHFT &none &kp Z &kp X &kp C &kp V &kp B &kp N &kp M &kp COMMA &kp DOT &kp MINUS &kp RSHFT",
+			},
+		},
+		{
+			format: "zmk", board: "ortho_4x12", layout: "colemak_dh_matrix",
+			lines: []string{
+				"                &kp LSHFT &kp Z &kp X &kp C &kp D &kp V &kp K &kp H &kp COMMA &kp DOT &kp FSLH &kp RET",
+				"                &kp LCTRL &kp LGUI &kp LALT &mo 1 &kp SPACE &kp SPACE &kp SPACE &kp SPACE &mo 1 &kp RALT &kp RGUI &kp RCTRL",
+				"        layer_1 {",
+				`            display-name = "colemak_dh_matrix 1";`,
+				"                &kp GRAVE &kp N1 &kp N2 &kp N3 &kp N4 &kp N5 &kp N6 &kp N7 &kp N8 &kp N9 &kp N0 &kp MINUS",
+				"                &trans &kp EQUAL &kp LBKT &kp RBKT &kp BSLH &trans &trans &trans &trans &trans &trans &trans",
+			},
+		},
+		{
+			format: "zmk", board: "split_3x5_3", layout: "colemak_dh_matrix",
+			lines: []string{
+				"                &kp Q &kp W &kp F &kp P &kp B &kp J &kp L &kp U &kp Y &kp SEMI",
+				"                &kp TAB &kp SPACE &kp LSHFT &kp RET &kp BSPC &mo 1",
+				"                &kp N1 &kp N2 &kp N3 &kp N4 &kp N5 &kp N6 &kp N7 &kp N8 &kp N9 &kp N0",
+				"                &kp LCTRL &kp LGUI &kp LALT &trans &trans &trans &trans &kp RALT &kp RGUI &kp RCTRL",
+			},
+		},
+		{
+			format: "kmonad", board: "60_ansi", layout: "colemak_dh_matrix",
+			lines: []string{
+				"(defsrc",
+				`  tab q w e r t y u i o p [ ] \`,
+				"  caps a s d f g h j k l ; ' ret",
+				"(deflayer thot",
+				`  tab q w f p b j l u y ; [ ] \`,
+				"  caps a r s t g m n e i o ' ret",
+				"  lctl lmet lalt spc ralt rmet cmp rctl",
+			},
+		},
+		{
+			format: "kmonad", board: "60_iso", layout: "spanish_qwerty",
+			lines: []string{
+				"(defsrc",
+				`  caps a s d f g h j k l ; ' \ ret`,
+				"  lsft 102d z x c v b n m , . / rsft",
+				"(deflayer thot",
+				"  XX 1 2 3 4 5 6 7 8 9 0 ' XX bspc",
+				"  lsft XX z x c v b n m , . - rsft",
+			},
+		},
+		{
+			format: "kmonad", board: "ortho_4x12", layout: "colemak_dh_matrix",
+			err: true,
+		},
+		{
+			format: "kmonad", board: "split_3x5_3", layout: "colemak_dh_matrix",
+			err: true,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.format + "(" + tt.layout + ", " + tt.board + ")"
+		f, err := Find(tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _, err := Export(f, tt.layout, kbd.FindLayout(tt.layout), Options{Board: tt.board})
+		if (err != nil) != tt.err {
+			t.Errorf("%s devolvió el error %v", name, err)
+			continue
+		}
+		lines := strings.Split(string(data), "\n")
+		next := 0
+		for _, want := range tt.lines {
+			i := next
+			for i < len(lines) && lines[i] != want {
+				i++
+			}
+			if i == len(lines) {
+				t.Errorf("%s no tiene la línea %q después de la línea %d", name, want, next)
+				break
+			}
+			next = i + 1
+		}
+	}
+} // }}}
@@ -0,0 +1,41 @@
+package keymap
+
+import (
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+func init() { // {{{
+	importers["grid"] = ParseGrid
+} // }}}
+
+// ParseGrid lee el texto en cuadrícula que usan los analizadores de layouts: una fila de teclas por línea
+// separadas por espacios, p.e. `q w f p b  j l u y ;`. Cada tecla es un carácter o el carácter normal seguido
+// del de Shift, las líneas vacías y las que empiezan con `#` o `//` se ignoran.
+func ParseGrid(data []byte) (*kbd.Keyboard, []string, errors.E) { // {{{
+	var found [][]string
+	var unknown []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r", ""), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		keys, bad := cells(strings.Fields(line))
+		unknown = append(unknown, bad...)
+		if len(keys) > 0 {
+			found = append(found, keys)
+		}
+	}
+	if len(found) == 0 {
+		return nil, unknown, errors.New(i18n.T("El archivo no define ninguna tecla"))
+	}
+	k, err := rowsOf(found)
+	if err != nil {
+		return nil, unknown, err
+	}
+
+	return k, unknown, nil
+} // }}}
@@ -0,0 +1,163 @@
+package keymap
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Parser lee un layout de un archivo, además del teclado devuelve las teclas que no se pudieron asignar.
+type Parser func(data []byte) (*kbd.Keyboard, []string, errors.E)
+
+// importers son los formatos que solo se pueden importar, los formatos de exportación con `Parse` también
+// se pueden importar.
+var importers = make(map[string]Parser)
+
+// extensions son los formatos que se eligen según la extensión del archivo cuando no se indica `--format`.
+var extensions = map[string]string{
+	".json":      "kle",
+	".txt":       "grid",
+	".xkb":       "xkb",
+	".klc":       "klc",
+	".keylayout": "keylayout",
+}
+
+// sizes es la cantidad de teclas de cada fila según el tipo de teclado.
+var sizes = map[string]map[string]int{
+	"ansi": {kbd.Row1: 13, kbd.Row2: 13, kbd.Row3: 11, kbd.Row4: 10},
+	"iso":  {kbd.Row1: 13, kbd.Row2: 12, kbd.Row3: 12, kbd.Row4: 11},
+}
+
+// Importers devuelve los nombres de los formatos que se pueden importar ordenados alfabéticamente.
+func Importers() []string { // {{{
+	names := make([]string, 0, len(importers)+len(formats))
+	for name := range importers {
+		names = append(names, name)
+	}
+	for name, f := range formats {
+		if f.Parse != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+} // }}}
+
+// Import lee el layout del archivo `path` en el formato `format`, si está vacío lo elige según la extensión
+// del archivo. Las filas incompletas se completan con teclas vacías y, si falta la fila de números, se usa
+// la del layout de Estados Unidos. Devuelve además las teclas que no se pudieron asignar.
+func Import(format, path string, data []byte) (*kbd.Keyboard, []string, errors.E) { // {{{
+	if format == "" {
+		format = extensions[strings.ToLower(filepath.Ext(path))]
+	}
+	parse, ok := importers[format]
+	if f, found := formats[format]; found && f.Parse != nil {
+		parse, ok = f.Parse, true
+	}
+	if !ok {
+		if format == "" {
+			return nil, nil, errors.New(i18n.T(
+				"No se puede saber el formato del archivo, se debe indicar con `--format` uno de los valores %s",
+				"`"+strings.Join(Importers(), "`, `")+"`",
+			))
+		}
+		return nil, nil, errors.New(i18n.T(
+			"El formato %q no se puede importar, acepta solo los valores %s",
+			format,
+			"`"+strings.Join(Importers(), "`, `")+"`",
+		))
+	}
+
+	k, unknown, err := parse(data)
+	if err != nil {
+		return nil, nil, errors.WithDetails(err, "format", format)
+	}
+	unknown = append(unknown, fill(k)...)
+
+	return k, unknown, nil
+} // }}}
+
+// fill completa las filas de `k` según su tipo: las teclas que sobran se devuelven como no asignadas, las
+// que faltan quedan vacías y sin fila de números se usan los números del layout de Estados Unidos.
+func fill(k *kbd.Keyboard) []string { // {{{
+	if _, ok := sizes[k.Type]; !ok {
+		k.Type = "ansi"
+	}
+	if len(k.Keys[kbd.Row1]) == 0 {
+		k.Keys[kbd.Row1] = []string{"", "1!", "2@", "3#", "4$", "5%", "6^", "7&", "8*", "9(", "0)"}
+	}
+
+	var left []string
+	for _, row := range rows {
+		size := sizes[k.Type][row]
+		keys := k.Keys[row]
+		for _, key := range keys[min(len(keys), size):] {
+			left = append(left, label(key))
+		}
+		keys = keys[:min(len(keys), size)]
+		for len(keys) < size {
+			keys = append(keys, "")
+		}
+		k.Keys[row] = keys
+	}
+
+	return left
+} // }}}
+
+// shifted devuelve la tecla con el carácter `r` y el que produce con Shift: la mayúscula en las letras, el
+// del layout de Estados Unidos en sus símbolos y el mismo carácter en el resto.
+func shifted(r rune) string { // {{{
+	upper := unicode.ToUpper(r)
+	if us, ok := usKeys[r]; ok {
+		upper = us.shift
+	}
+
+	return string([]rune{r, upper})
+} // }}}
+
+// cells convierte una fila de caracteres en teclas: una celda de un carácter usa `shifted` y una de dos
+// caracteres es el carácter normal y el de Shift. Las demás celdas se devuelven como no asignadas.
+func cells(list []string) ([]string, []string) { // {{{
+	var keys, unknown []string
+	for _, cell := range list {
+		r := []rune(cell)
+		switch {
+		case len(r) == 1:
+			keys = append(keys, shifted(r[0]))
+		case len(r) == 2:
+			keys = append(keys, cell)
+		default:
+			unknown = append(unknown, fmt.Sprintf("%q", cell))
+		}
+	}
+
+	return keys, unknown
+} // }}}
+
+// rowsOf asigna las filas de caracteres encontradas en un archivo a las filas de Thot: con cuatro filas la
+// primera es la de números, con tres falta la de números. El tipo es ISO si la fila inferior tiene la tecla
+// junto a Shift izquierdo.
+func rowsOf(found [][]string) (*kbd.Keyboard, errors.E) { // {{{
+	if len(found) < 3 || len(found) > 4 {
+		return nil, errors.WithDetails(
+			errors.New(i18n.T("El archivo debe tener 3 o 4 filas de teclas")),
+			"rows", len(found),
+		)
+	}
+	k := &kbd.Keyboard{Type: "ansi", Keys: make(map[string][]string)}
+	for i, row := range rows[4-len(found):] {
+		k.Keys[row] = found[i]
+	}
+	if len(k.Keys[kbd.Row4]) > sizes["ansi"][kbd.Row4] {
+		k.Type = "iso"
+	}
+
+	return k, nil
+} // }}}
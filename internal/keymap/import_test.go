@@ -0,0 +1,107 @@
+package keymap
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/wrodriguez/thot/internal/kbd"
+)
+
+// usNumbers es la fila de números que agrega `fill` a los archivos sin ella.
+var usNumbers = []string{"", "1!", "2@", "3#", "4$", "5%", "6^", "7&", "8*", "9(", "0)", "", ""}
+
+func TestImport(t *testing.T) { // {{{
+	tests := []struct {
+		file   string
+		format string
+		want   *kbd.Keyboard
+		// unknown son las teclas no asignadas, incluye las que sobran en las filas
+		unknown []string
+	}{
+		{
+			// JSON descargado con teclas de función, flechas y bloque de navegación que no son filas de Thot
+			file: "kle_ansi.json",
+			want: kbd.FindLayout("qwerty"),
+		},
+		{
+			// Texto de `Raw data` sin la fila de números, con leyendas en HTML y la tecla junto a Shift
+			file:   "kle_raw.txt",
+			format: "kle",
+			want: &kbd.Keyboard{Type: "iso", Keys: map[string][]string{
+				kbd.Row1: usNumbers,
+				kbd.Row2: {"qQ", "wW", "fF", "pP", "bB", "jJ", "lL", "uU", "yY", ";:", "[{", "]}"},
+				kbd.Row3: {"aA", "rR", "sS", "tT", "gG", "mM", "nN", "eE", "iI", "oO", "'\"", "\\|"},
+				kbd.Row4: {"<>", "zZ", "xX", "cC", "dD", "vV", "kK", "hH", ",<", ".>", "/?"},
+			}},
+			unknown: []string{`"Compose"`},
+		},
+		{
+			// Cuadrícula con CRLF, comentarios y una celda que no es una tecla
+			file: "grid_colemak_dh.txt",
+			want: &kbd.Keyboard{Type: "ansi", Keys: map[string][]string{
+				kbd.Row1: usNumbers,
+				kbd.Row2: {"qQ", "wW", "fF", "pP", "bB", "jJ", "lL", "uU", "yY", ";:", "", "", ""},
+				kbd.Row3: {"aA", "rR", "sS", "tT", "gG", "mM", "nN", "eE", "iI", "oO", "'\""},
+				kbd.Row4: {"zZ", "xX", "cC", "dD", "vV", "kK", "hH", ",<", ".>", "/?"},
+			}},
+			unknown: []string{`"ESC"`},
+		},
+		{
+			// Cuadrícula ISO con una tecla de más en la fila de números
+			file: "grid_iso.txt",
+			want: &kbd.Keyboard{Type: "iso", Keys: map[string][]string{
+				kbd.Row1: {"`~", "1!", "2@", "3#", "4$", "5%", "6^", "7&", "8*", "9(", "0)", "-_", "=+"},
+				kbd.Row2: {"qQ", "wW", "eE", "rR", "tT", "yY", "uU", "iI", "oO", "pP", "[{", "]}"},
+				kbd.Row3: {"aA", "sS", "dD", "fF", "gG", "hH", "jJ", "kK", "lL", ";:", "'\"", "#~"},
+				kbd.Row4: {"\\|", "zZ", "xX", "cC", "vV", "bB", "nN", "mM", ",<", ".>", "/?"},
+			}},
+			unknown: []string{"[€ €]"},
+		},
+	}
+	for _, tt := range tests {
+		path := filepath.Join("testdata", tt.file)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, unknown, e := Import(tt.format, path, data)
+		if e != nil {
+			t.Errorf("Import(%s): %s", tt.file, e)
+			continue
+		}
+		if k.Type != tt.want.Type {
+			t.Errorf("Import(%s) tiene el tipo %q, se esperaba %q", tt.file, k.Type, tt.want.Type)
+		}
+		for _, row := range rows {
+			if !slices.Equal(k.Keys[row], tt.want.Keys[row]) {
+				t.Errorf("Import(%s) fila %s = %q, se esperaba %q", tt.file, row, k.Keys[row], tt.want.Keys[row])
+			}
+		}
+		if !slices.Equal(unknown, tt.unknown) {
+			t.Errorf("Import(%s) no asignó %q, se esperaba %q", tt.file, unknown, tt.unknown)
+		}
+	}
+} // }}}
+
+func TestImportErrors(t *testing.T) { // {{{
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"vacio.txt", "", "# solo comentarios\n"},
+		{"dos_filas.txt", "", "q w e r\na s d f\n"},
+		{"cinco_filas.txt", "", "1 2 3\nq w e\na s d\nz x c\n, . /\n"},
+		{"roto.json", "", `[["Q", "W"`},
+		{"sin_caracteres.json", "", `[["Esc", "F1", "F2"], ["Ctrl", "Alt", ""]]`},
+		{"layout", "", "q w e\na s d\nz x c\n"},
+		{"layout.txt", "pdf", "q w e\na s d\nz x c\n"},
+	}
+	for _, tt := range tests {
+		if _, _, err := Import(tt.format, tt.name, []byte(tt.data)); err == nil {
+			t.Errorf("Import(%s) no devolvió un error", tt.name)
+		}
+	}
+} // }}}
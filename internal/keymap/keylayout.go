@@ -0,0 +1,317 @@
+package keymap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+// macCodes son los códigos de tecla de macOS de cada fila. En los teclados ISO de Apple la tecla sobre Tab
+// es `10` y la que está junto a Shift izquierdo es `50`.
+var macCodes = map[string]map[string][]string{
+	"ansi": {
+		kbd.Row1: {"50", "18", "19", "20", "21", "23", "22", "26", "28", "25", "29", "27", "24"},
+		kbd.Row2: {"12", "13", "14", "15", "17", "16", "32", "34", "31", "35", "33", "30", "42"},
+		kbd.Row3: {"0", "1", "2", "3", "5", "4", "38", "40", "37", "41", "39"},
+		kbd.Row4: {"6", "7", "8", "9", "11", "45", "46", "43", "47", "44"},
+	},
+	"iso": {
+		kbd.Row1: {"10", "18", "19", "20", "21", "23", "22", "26", "28", "25", "29", "27", "24"},
+		kbd.Row2: {"12", "13", "14", "15", "17", "16", "32", "34", "31", "35", "33", "30"},
+		kbd.Row3: {"0", "1", "2", "3", "5", "4", "38", "40", "37", "41", "39", "42"},
+		kbd.Row4: {"50", "6", "7", "8", "9", "11", "45", "46", "43", "47", "44"},
+	},
+}
+
+// Índices de los mapas de teclas del archivo generado.
+const (
+	macBase        = 0
+	macShift       = 1
+	macCaps        = 2
+	macOption      = 3
+	macOptionShift = 4
+)
+
+// macIgnored son la barra espaciadora y las teclas del teclado numérico, no forman parte de las filas de Thot.
+var macIgnored = map[string]bool{
+	"49": true, "65": true, "67": true, "69": true, "75": true, "78": true, "81": true, "82": true, "83": true,
+	"84": true, "85": true, "86": true, "87": true, "88": true, "89": true, "91": true, "92": true,
+}
+
+const macDoctype = `<!DOCTYPE keyboard SYSTEM "file://localhost/System/Library/DTDs/KeyboardLayout.dtd">`
+
+type macKeyboard struct {
+	XMLName     xml.Name         `xml:"keyboard"`
+	Group       int              `xml:"group,attr"`
+	ID          int              `xml:"id,attr"`
+	Name        string           `xml:"name,attr"`
+	MaxOut      int              `xml:"maxout,attr"`
+	Layouts     []macLayout      `xml:"layouts>layout"`
+	ModifierMap []macModifierMap `xml:"modifierMap"`
+	KeyMapSet   []macKeyMapSet   `xml:"keyMapSet"`
+}
+
+type macLayout struct {
+	First     int    `xml:"first,attr"`
+	Last      int    `xml:"last,attr"`
+	MapSet    string `xml:"mapSet,attr"`
+	Modifiers string `xml:"modifiers,attr"`
+}
+
+type macModifierMap struct {
+	ID           string         `xml:"id,attr"`
+	DefaultIndex int            `xml:"defaultIndex,attr"`
+	Select       []macKeySelect `xml:"keyMapSelect"`
+}
+
+type macKeySelect struct {
+	MapIndex  int           `xml:"mapIndex,attr"`
+	Modifiers []macModifier `xml:"modifier"`
+}
+
+type macModifier struct {
+	Keys string `xml:"keys,attr"`
+}
+
+type macKeyMapSet struct {
+	ID      string      `xml:"id,attr"`
+	KeyMaps []macKeyMap `xml:"keyMap"`
+}
+
+type macKeyMap struct {
+	Index int      `xml:"index,attr"`
+	Keys  []macKey `xml:"key"`
+}
+
+type macKey struct {
+	Code   int    `xml:"code,attr"`
+	Output string `xml:"output,attr"`
+}
+
+func init() { // {{{
+	register(Format{
+		Name:    "keylayout",
+		File:    func(name string) string { return "thot_" + name + ".keylayout" },
+		Export:  Keylayout,
+		Parse:   ParseKeylayout,
+		AltGr:   true,
+		Install: keylayoutInstall,
+	})
+} // }}}
+
+// Keylayout genera el archivo `.keylayout` de macOS del layout `name` con tres mapas de teclas: sin
+// modificadores, con Shift y con Bloq Mayús. Si el layout tiene caracteres con AltGr agrega los mapas de
+// Option y Option+Shift, que es como macOS interpreta AltGr.
+func Keylayout(name string, k *kbd.Keyboard, _ Options) ([]byte, []string, errors.E) { // {{{
+	table := codes(macCodes, k.Type)
+	maps := []macKeyMap{{Index: macBase}, {Index: macShift}, {Index: macCaps}}
+	selects := []macKeySelect{
+		{MapIndex: macBase, Modifiers: []macModifier{{Keys: ""}}},
+		{MapIndex: macShift, Modifiers: []macModifier{{Keys: "anyShift caps?"}}},
+		{MapIndex: macCaps, Modifiers: []macModifier{{Keys: "caps"}}},
+	}
+	if len(k.AltGr) > 0 {
+		maps = append(maps, macKeyMap{Index: macOption}, macKeyMap{Index: macOptionShift})
+		selects = append(selects,
+			macKeySelect{MapIndex: macOption, Modifiers: []macModifier{{Keys: "anyOption caps?"}}},
+			macKeySelect{MapIndex: macOptionShift, Modifiers: []macModifier{{Keys: "anyShift anyOption caps?"}}},
+		)
+	}
+	for _, row := range rows {
+		if len(k.Keys[row]) > len(table[row]) {
+			return nil, nil, errors.WithDetails(
+				errors.New(i18n.T("La fila tiene más teclas que el teclado")),
+				"row", row,
+				"keys", len(k.Keys[row]),
+				"max", len(table[row]),
+			)
+		}
+		for col, key := range k.Keys[row] {
+			lower, upper, ok := levels(key)
+			if !ok {
+				continue
+			}
+			code, _ := strconv.Atoi(table[row][col])
+			capsLock := lower
+			if caps(lower, upper) {
+				capsLock = upper
+			}
+			maps[macBase].Keys = append(maps[macBase].Keys, macKey{Code: code, Output: string(lower)})
+			maps[macShift].Keys = append(maps[macShift].Keys, macKey{Code: code, Output: string(upper)})
+			maps[macCaps].Keys = append(maps[macCaps].Keys, macKey{Code: code, Output: string(capsLock)})
+			// Las teclas sin carácter con AltGr no escriben nada con Option
+			for i, r := range altgr(k, row, col) {
+				maps[macOption+i].Keys = append(maps[macOption+i].Keys, macKey{Code: code, Output: string(r)})
+			}
+		}
+	}
+	for i := range maps {
+		keys := maps[i].Keys
+		sort.Slice(keys, func(a, b int) bool { return keys[a].Code < keys[b].Code })
+	}
+
+	// macOS necesita un identificador negativo y distinto para cada layout instalado
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	doc := macKeyboard{
+		Group:   126,
+		ID:      -int(h.Sum32()%30000) - 2,
+		Name:    fmt.Sprintf("Thot (%s)", name),
+		MaxOut:  1,
+		Layouts: []macLayout{{First: 0, Last: 17, MapSet: "thot", Modifiers: "modifiers"}},
+		ModifierMap: []macModifierMap{{
+			ID:           "modifiers",
+			DefaultIndex: macBase,
+			Select:       selects,
+		}},
+		KeyMapSet: []macKeyMapSet{{ID: "thot", KeyMaps: maps}},
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString(xml.Header + macDoctype + "\n")
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "\t")
+	if err := enc.Encode(doc); err != nil {
+		return nil, nil, errors.WithMessage(err, i18n.T("No se pudo generar el archivo de macOS"))
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil, nil
+} // }}}
+
+// ParseKeylayout lee un archivo `.keylayout` y verifica su estructura: el mapa de modificadores y el conjunto
+// de mapas de teclas que usa el layout deben existir y cada índice elegido debe tener su mapa. Las teclas se
+// toman de los mapas sin modificadores y con Shift, y los caracteres con AltGr de los mapas con Option y con
+// Option+Shift. Devuelve además los códigos que no se pudieron asignar.
+func ParseKeylayout(data []byte) (*kbd.Keyboard, []string, errors.E) { // {{{
+	var doc macKeyboard
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, errors.WithMessage(err, i18n.T("El archivo no es un `.keylayout` válido"))
+	}
+	invalid := func(reason string) errors.E {
+		return errors.WithDetails(errors.New(i18n.T("El archivo no es un `.keylayout` válido")), "reason", reason)
+	}
+	if doc.Name == "" {
+		return nil, nil, invalid("keyboard@name")
+	}
+	if len(doc.Layouts) == 0 {
+		return nil, nil, invalid("layouts")
+	}
+
+	layout := doc.Layouts[0]
+	var mods *macModifierMap
+	for i := range doc.ModifierMap {
+		if doc.ModifierMap[i].ID == layout.Modifiers {
+			mods = &doc.ModifierMap[i]
+		}
+	}
+	if mods == nil {
+		return nil, nil, invalid("modifierMap " + layout.Modifiers)
+	}
+	var set *macKeyMapSet
+	for i := range doc.KeyMapSet {
+		if doc.KeyMapSet[i].ID == layout.MapSet {
+			set = &doc.KeyMapSet[i]
+		}
+	}
+	if set == nil {
+		return nil, nil, invalid("keyMapSet " + layout.MapSet)
+	}
+	maps := make(map[int]macKeyMap)
+	for _, m := range set.KeyMaps {
+		maps[m.Index] = m
+	}
+	shift, option, optionShift := -1, -1, -1
+	for _, s := range mods.Select {
+		if _, ok := maps[s.MapIndex]; !ok {
+			return nil, nil, invalid(fmt.Sprintf("keyMap %d", s.MapIndex))
+		}
+		for _, m := range s.Modifiers {
+			keys := strings.ToLower(m.Keys)
+			if strings.Contains(keys, "command") || strings.Contains(keys, "control") {
+				continue
+			}
+			withShift := strings.Contains(keys, "shift")
+			withOption := strings.Contains(keys, "option")
+			switch {
+			case shift < 0 && withShift && !withOption:
+				shift = s.MapIndex
+			case option < 0 && withOption && !withShift:
+				option = s.MapIndex
+			case optionShift < 0 && withOption && withShift:
+				optionShift = s.MapIndex
+			}
+		}
+	}
+	if _, ok := maps[mods.DefaultIndex]; !ok {
+		return nil, nil, invalid(fmt.Sprintf("keyMap %d", mods.DefaultIndex))
+	}
+
+	var unknown []string
+	found := make(map[string]string)
+	upper := make(map[int]string)
+	if shift >= 0 {
+		for _, key := range maps[shift].Keys {
+			upper[key.Code] = key.Output
+		}
+	}
+	for _, key := range maps[mods.DefaultIndex].Keys {
+		lower := []rune(key.Output)
+		if len(lower) != 1 || !unicode.IsPrint(lower[0]) {
+			continue
+		}
+		u := []rune(upper[key.Code])
+		if len(u) != 1 {
+			u = []rune{unicode.ToUpper(lower[0])}
+		}
+		found[strconv.Itoa(key.Code)] = string([]rune{lower[0], u[0]})
+	}
+	if len(found) == 0 {
+		return nil, unknown, errors.New(i18n.T("El archivo no define ninguna tecla"))
+	}
+
+	// Un carácter con Option+Shift solo se conserva si la tecla también tiene uno con Option
+	foundAltGr := make(map[string]string)
+	for _, index := range []int{option, optionShift} {
+		if index < 0 {
+			break
+		}
+		for _, key := range maps[index].Keys {
+			r := []rune(key.Output)
+			code := strconv.Itoa(key.Code)
+			if _, ok := found[code]; !ok || len(r) != 1 || !unicode.IsPrint(r[0]) {
+				continue
+			}
+			if index == option || foundAltGr[code] != "" {
+				foundAltGr[code] += string(r)
+			}
+		}
+	}
+
+	k := &kbd.Keyboard{Type: "ansi", Keys: make(map[string][]string)}
+	if _, ok := found["10"]; ok {
+		k.Type = "iso"
+	}
+	unknown = append(unknown, assign(k, codes(macCodes, k.Type), found, macIgnored)...)
+	assignAltGr(k, codes(macCodes, k.Type), foundAltGr)
+	sort.Strings(unknown)
+
+	return k, unknown, nil
+} // }}}
+
+func keylayoutInstall(name, path string) string { // {{{
+	return strings.Join([]string{
+		i18n.T("En macOS:"),
+		fmt.Sprintf("  cp %s ~/Library/Keyboard\\ Layouts/", path),
+		i18n.T("  Cerrar la sesión y agregar `Thot (%s)` en `Ajustes del Sistema > Teclado > Fuentes de entrada`, en la categoría `Otros`", name),
+	}, "\n")
+} // }}}
@@ -0,0 +1,267 @@
+// Package keymap convierte los layouts de Thot en los formatos de teclado de los sistemas operativos.
+package keymap
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+var rows = []string{kbd.Row1, kbd.Row2, kbd.Row3, kbd.Row4}
+
+// Format es un formato de exportación.
+type Format struct {
+	// Name es el nombre con el que se elige el formato en `--format`.
+	Name string
+	// File devuelve el nombre de archivo recomendado para el layout `name`.
+	File func(name string) string
+	// Export genera el archivo del layout `name`, además devuelve avisos sobre las teclas que no se pudieron
+	// representar exactamente.
+	Export func(name string, k *kbd.Keyboard, opts Options) ([]byte, []string, errors.E)
+	// Parse lee un archivo generado por `Export`, se usa para verificar la exportación. Además del teclado
+	// devuelve las teclas o símbolos que no se pudieron interpretar. Es nil en los formatos que no se
+	// pueden leer de vuelta.
+	Parse func(data []byte) (*kbd.Keyboard, []string, errors.E)
+	// Boards indica si el formato es para el firmware de un teclado programable y necesita una placa.
+	Boards bool
+	// AltGr indica si el formato exporta los caracteres con AltGr y AltGr+Shift, `Export` también los verifica.
+	AltGr bool
+	// Install devuelve las instrucciones para instalar el archivo `path` del layout `name`.
+	Install func(name, path string) string
+}
+
+// Options son las opciones de exportación.
+type Options struct {
+	// Board es la placa de los formatos de firmware, vacía para elegirla según el tipo del layout.
+	Board string
+}
+
+var formats = make(map[string]Format)
+
+// register agrega un formato, cada formato se registra en el `init` de su archivo.
+func register(f Format) { // {{{
+	formats[f.Name] = f
+} // }}}
+
+// Formats devuelve los nombres de los formatos disponibles ordenados alfabéticamente.
+func Formats() []string { // {{{
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+} // }}}
+
+// Find busca el formato `name`.
+func Find(name string) (Format, errors.E) { // {{{
+	f, ok := formats[name]
+	if !ok {
+		return f, errors.New(i18n.T(
+			"El formato %q no es válido, acepta solo los valores %s",
+			name,
+			"`"+strings.Join(Formats(), "`, `")+"`",
+		))
+	}
+
+	return f, nil
+} // }}}
+
+// Export genera el archivo del layout `name` en el formato `f` y lo verifica leyéndolo de nuevo, si el
+// resultado no tiene las mismas teclas devuelve un error con la primera diferencia. Además devuelve los
+// avisos de la exportación.
+func Export(f Format, name string, k *kbd.Keyboard, opts Options) ([]byte, []string, errors.E) { // {{{
+	if opts.Board != "" && !f.Boards {
+		return nil, nil, errors.New(i18n.T("El formato %q no usa placas, `--board` solo se usa con %s", f.Name, boardFormats()))
+	}
+	data, warnings, err := f.Export(name, k, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !f.Boards {
+		// Los sistemas operativos no tienen códigos para las teclas del pulgar de los teclados matriciales
+		for _, key := range k.Keys[kbd.Thumb] {
+			if key != "" {
+				warnings = append(warnings, i18n.T("La tecla del pulgar %s no existe en el formato %q", label(key), f.Name))
+			}
+		}
+	}
+	switch {
+	case !f.AltGr && (len(k.AltGr) > 0 || k.Dead != ""):
+		warnings = append(warnings, i18n.T("Los caracteres con AltGr y las teclas muertas no se exportan en el formato %q", f.Name))
+	case k.Dead != "":
+		warnings = append(warnings, i18n.T("Las teclas muertas no se exportan en el formato %q", f.Name))
+	}
+	if f.Parse == nil {
+		return data, warnings, nil
+	}
+
+	parsed, unknown, err := f.Parse(data)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, i18n.T("No se pudo leer el archivo generado"))
+	}
+	if len(unknown) > 0 {
+		return nil, nil, errors.WithDetails(
+			errors.New(i18n.T("El archivo generado tiene símbolos que no se pudieron leer")),
+			"symbols",
+			unknown,
+		)
+	}
+	for _, row := range rows {
+		want := k.Keys[row]
+		got := parsed.Keys[row]
+		for col := range max(len(want), len(got)) {
+			var a, b string
+			if col < len(want) {
+				a = normalize(want[col])
+			}
+			if col < len(got) {
+				b = normalize(got[col])
+			}
+			if a != b {
+				return nil, nil, errors.WithDetails(
+					errors.New(i18n.T("El archivo generado no coincide con el layout")),
+					"row", row,
+					"col", col,
+					"want", a,
+					"got", b,
+				)
+			}
+			if !f.AltGr {
+				continue
+			}
+			if a, b := string(altgr(k, row, col)), string(altgr(parsed, row, col)); a != b {
+				return nil, nil, errors.WithDetails(
+					errors.New(i18n.T("El archivo generado no coincide con el layout")),
+					"row", row,
+					"col", col,
+					"altgr", true,
+					"want", a,
+					"got", b,
+				)
+			}
+		}
+	}
+
+	return data, warnings, nil
+} // }}}
+
+// boardFormats devuelve los nombres de los formatos que usan placas.
+func boardFormats() string { // {{{
+	var names []string
+	for _, name := range Formats() {
+		if formats[name].Boards {
+			names = append(names, "`"+name+"`")
+		}
+	}
+
+	return strings.Join(names, ", ")
+} // }}}
+
+// levels devuelve el carácter normal y el de Shift de una tecla, una tecla con un solo carácter lo usa en
+// ambos niveles. `ok` es falso si la tecla está vacía.
+func levels(key string) (lower, upper rune, ok bool) { // {{{
+	r := []rune(key)
+	switch len(r) {
+	case 0:
+		return 0, 0, false
+	case 1:
+		return r[0], r[0], true
+	}
+
+	return r[0], r[1], true
+} // }}}
+
+// caps indica si Bloq Mayús afecta a la tecla, solo en las letras cuyo segundo nivel es su mayúscula.
+func caps(lower, upper rune) bool { // {{{
+	return unicode.IsLetter(lower) && unicode.ToUpper(lower) == upper && lower != upper
+} // }}}
+
+// normalize devuelve la tecla como la escriben los formatos de exportación, siempre con dos caracteres.
+func normalize(key string) string { // {{{
+	lower, upper, ok := levels(key)
+	if !ok {
+		return ""
+	}
+
+	return string([]rune{lower, upper})
+} // }}}
+
+// altgr devuelve los caracteres con AltGr y con AltGr+Shift de la tecla, nada si la tecla está vacía porque
+// los formatos solo escriben las teclas que tienen caracteres sin AltGr.
+func altgr(k *kbd.Keyboard, row string, col int) []rune { // {{{
+	if col >= len(k.Keys[row]) || col >= len(k.AltGr[row]) || k.Keys[row][col] == "" {
+		return nil
+	}
+	r := []rune(k.AltGr[row][col])
+	if len(r) > 2 {
+		r = r[:2]
+	}
+
+	return r
+} // }}}
+
+// codes devuelve los códigos de tecla de cada fila según el tipo de teclado `ansi` o `iso`.
+func codes(table map[string]map[string][]string, typ string) map[string][]string { // {{{
+	if c, ok := table[typ]; ok {
+		return c
+	}
+
+	return table["ansi"]
+} // }}}
+
+// assign agrega a las filas de `k` las teclas de `found` en el orden de los códigos de `table`, los códigos
+// que faltan en `found` quedan como teclas vacías salvo al final de la fila. Devuelve los códigos de `found` que no están en ninguna fila salvo los
+// de `ignored`.
+func assign(k *kbd.Keyboard, table map[string][]string, found map[string]string, ignored map[string]bool) []string { // {{{
+	used := make(map[string]bool)
+	for _, row := range rows {
+		keys := []string{}
+		last := 0
+		for _, code := range table[row] {
+			key, ok := found[code]
+			if ok {
+				used[code] = true
+			}
+			keys = append(keys, key)
+			if key != "" {
+				last = len(keys)
+			}
+		}
+		k.Keys[row] = keys[:last]
+	}
+
+	var left []string
+	for code := range found {
+		if !used[code] && !ignored[code] {
+			left = append(left, code)
+		}
+	}
+
+	return left
+} // }}}
+
+// assignAltGr agrega a `k` los caracteres con AltGr de `found` como `assign`, sin las filas que no tienen
+// ninguno.
+func assignAltGr(k *kbd.Keyboard, table map[string][]string, found map[string]string) { // {{{
+	if len(found) == 0 {
+		return
+	}
+	layer := &kbd.Keyboard{Keys: make(map[string][]string)}
+	// Los códigos que no están en las filas ya los informa `assign` con los caracteres sin AltGr
+	_ = assign(layer, table, found, nil)
+	for row, keys := range layer.Keys {
+		if len(keys) == 0 {
+			continue
+		}
+		if k.AltGr == nil {
+			k.AltGr = make(map[string][]string)
+		}
+		k.AltGr[row] = keys
+	}
+} // }}}
@@ -0,0 +1,403 @@
+package keymap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/wrodriguez/thot/internal/kbd"
+)
+
+// update regenera los archivos de `testdata` con `go test ./internal/keymap -update`, se deben revisar los
+// cambios antes de guardarlos.
+var update = flag.Bool("update", false, "regenera los archivos de referencia de testdata")
+
+// goldenLayouts son los layouts con archivo de referencia: ANSI, ISO con `ñ` y teclas muertas, con los
+// símbolos en otras teclas y matricial.
+var goldenLayouts = []string{"qwerty", "dvorak", "colemak_dh", "spanish_qwerty", "latam_dvorak", "azerty", "colemak_dh_matrix"}
+
+// sameRows informa las filas de `got` que no tienen las teclas de `want` como las escriben los formatos de
+// exportación.
+func sameRows(t *testing.T, label string, want, got *kbd.Keyboard) { // {{{
+	t.Helper()
+	for _, row := range rows {
+		var keys []string
+		for _, key := range want.Keys[row] {
+			keys = append(keys, normalize(key))
+		}
+		// `assign` no agrega las teclas vacías del final de la fila
+		for len(keys) > 0 && keys[len(keys)-1] == "" {
+			keys = keys[:len(keys)-1]
+		}
+		if !slices.Equal(got.Keys[row], keys) && len(got.Keys[row])+len(keys) > 0 {
+			t.Errorf("%s fila %s = %q, se esperaba %q", label, row, got.Keys[row], keys)
+		}
+	}
+} // }}}
+
+func TestGolden(t *testing.T) { // {{{
+	for _, format := range []string{"klc", "keylayout"} {
+		f, err := Find(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range goldenLayouts {
+			k := kbd.FindLayout(name)
+			data, _, err := Export(f, name, k, Options{})
+			if err != nil {
+				t.Errorf("Export(%s, %s): %s", format, name, err)
+				continue
+			}
+			path := filepath.Join("testdata", name+"."+format)
+			if *update {
+				if err := os.WriteFile(path, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, e := os.ReadFile(path)
+			if e != nil {
+				t.Errorf("no se pudo leer el archivo de referencia: %s", e)
+				continue
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("Export(%s, %s) no coincide con %s, se puede regenerar con -update", format, name, path)
+			}
+
+			parsed, unknown, err := f.Parse(want)
+			if err != nil {
+				t.Errorf("Parse(%s): %s", path, err)
+				continue
+			}
+			if len(unknown) > 0 {
+				t.Errorf("Parse(%s) no pudo leer %v", path, unknown)
+			}
+			sameRows(t, "Parse("+path+")", k, parsed)
+		}
+	}
+} // }}}
+
+// klcSections son las secciones que escribe `KLC` en el orden que las espera MSKLC.
+var klcSections = []string{
+	"KBD", "COPYRIGHT", "COMPANY", "LOCALENAME", "LOCALEID", "VERSION", "SHIFTSTATE", "LAYOUT",
+	"DESCRIPTIONS", "LANGUAGENAMES", "ENDKBD",
+}
+
+var (
+	reKLCShortName = regexp.MustCompile(`^[a-zA-Z0-9]{1,8}$`)
+	reKLCScanCode  = regexp.MustCompile(`^[0-9a-f]{2}$`)
+	reKLCVK        = regexp.MustCompile(`^[A-Z0-9_]+$`)
+)
+
+// checkKLC verifica la estructura de un archivo de MSKLC sin usar `ParseKLC`: UTF-16 con BOM y CRLF, las
+// secciones en orden y en `LAYOUT` una columna por cada estado de `SHIFTSTATE`. Devuelve las columnas de cada
+// scan code según el estado.
+func checkKLC(t *testing.T, label string, data []byte) map[string]map[string]string { // {{{
+	t.Helper()
+	if len(data) < 2 || len(data)%2 != 0 || data[0] != 0xff || data[1] != 0xfe {
+		t.Errorf("%s no está en UTF-16LE con BOM", label)
+		return nil
+	}
+	units := make([]uint16, 0, len(data)/2-1)
+	for i := 2; i < len(data); i += 2 {
+		units = append(units, binary.LittleEndian.Uint16(data[i:]))
+	}
+	text := string(utf16.Decode(units))
+	if strings.Count(text, "\n") != strings.Count(text, "\r\n") {
+		t.Errorf("%s tiene líneas que no terminan en CRLF", label)
+	}
+
+	var sections, states []string
+	layout := make(map[string]map[string]string)
+	vks := make(map[string]bool)
+	section := ""
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if slices.Contains(klcSections, fields[0]) {
+			section = fields[0]
+			sections = append(sections, section)
+		}
+		switch section {
+		case "KBD":
+			if len(fields) < 3 || !reKLCShortName.MatchString(fields[1]) || !strings.HasPrefix(fields[2], `"`) {
+				t.Errorf("%s tiene la línea KBD %q", label, line)
+			}
+		case "SHIFTSTATE":
+			if fields[0] == section {
+				continue
+			}
+			if s, err := strconv.Atoi(fields[0]); err != nil || s < 0 || s > 15 || slices.Contains(states, fields[0]) {
+				t.Errorf("%s tiene el estado %q", label, fields[0])
+			}
+			states = append(states, fields[0])
+		case "LAYOUT":
+			if fields[0] == section {
+				continue
+			}
+			// Scan code, tecla virtual, Bloq Mayús y un carácter por estado
+			if len(fields) != 3+len(states) {
+				t.Errorf("%s tiene %d columnas en %q, se esperaban %d", label, len(fields), line, 3+len(states))
+				continue
+			}
+			sc := fields[0]
+			if !reKLCScanCode.MatchString(sc) || layout[sc] != nil {
+				t.Errorf("%s tiene el scan code %q", label, sc)
+			}
+			if !reKLCVK.MatchString(fields[1]) || vks[fields[1]] {
+				t.Errorf("%s tiene la tecla virtual %q", label, fields[1])
+			}
+			vks[fields[1]] = true
+			if !slices.Contains([]string{"0", "1", "4", "5", "SGCap"}, fields[2]) {
+				t.Errorf("%s tiene el valor de Bloq Mayús %q", label, fields[2])
+			}
+			layout[sc] = make(map[string]string)
+			for i, state := range states {
+				c := fields[3+i]
+				if _, ok := parseKLCChar(c); !ok && c != "-1" {
+					t.Errorf("%s tiene el carácter %q en %s", label, c, sc)
+				}
+				layout[sc][state] = c
+			}
+		}
+	}
+	if !slices.Equal(sections, klcSections) {
+		t.Errorf("%s tiene las secciones %v, se esperaba %v", label, sections, klcSections)
+	}
+	if !slices.Contains(states, "0") || !slices.Contains(states, "1") {
+		t.Errorf("%s no tiene los estados 0 y 1 en SHIFTSTATE: %v", label, states)
+	}
+	if len(layout) == 0 {
+		t.Errorf("%s no tiene teclas en LAYOUT", label)
+	}
+
+	return layout
+} // }}}
+
+// macElements son los hijos que permite KeyboardLayout.dtd para cada elemento que escribe `Keylayout`, y sus
+// atributos obligatorios.
+var macElements = map[string]struct {
+	children []string
+	attrs    []string
+}{
+	"keyboard":     {[]string{"layouts", "modifierMap", "keyMapSet", "actions", "terminators"}, []string{"group", "id", "name"}},
+	"layouts":      {[]string{"layout"}, nil},
+	"layout":       {nil, []string{"first", "last", "modifiers", "mapSet"}},
+	"modifierMap":  {[]string{"keyMapSelect"}, []string{"id", "defaultIndex"}},
+	"keyMapSelect": {[]string{"modifier"}, []string{"mapIndex"}},
+	"modifier":     {nil, []string{"keys"}},
+	"keyMapSet":    {[]string{"keyMap"}, []string{"id"}},
+	"keyMap":       {[]string{"key"}, []string{"index"}},
+	"key":          {nil, []string{"code"}},
+}
+
+// macModifierKeys son los modificadores que acepta el atributo `keys`, cada uno puede terminar en `?`.
+var macModifierKeys = []string{
+	"shift", "rightShift", "anyShift", "option", "rightOption", "anyOption", "control", "rightControl",
+	"anyControl", "command", "caps",
+}
+
+// checkKeylayout verifica un archivo `.keylayout` contra KeyboardLayout.dtd sin usar `ParseKeylayout`: el
+// DOCTYPE, los elementos y sus atributos, y que los índices de los mapas de teclas existan, sean únicos y
+// consecutivos. Devuelve la salida de cada código de tecla según los modificadores que eligen su mapa.
+func checkKeylayout(t *testing.T, label string, data []byte) map[string]map[int]string { // {{{
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte(xml.Header+`<!DOCTYPE keyboard SYSTEM "file://localhost/System/Library/DTDs/KeyboardLayout.dtd">`)) {
+		t.Errorf("%s no empieza con la declaración XML y el DOCTYPE de KeyboardLayout.dtd", label)
+	}
+
+	// Los elementos y el orden de los hijos de `keyboard`: layouts, modifierMap+ y keyMapSet+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack, top []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Errorf("%s no es XML válido: %s", label, err)
+			return nil
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			name := tok.Name.Local
+			element, ok := macElements[name]
+			switch {
+			case !ok:
+				t.Errorf("%s tiene el elemento desconocido %q", label, name)
+			case len(stack) == 0 && name != "keyboard":
+				t.Errorf("%s tiene la raíz %q", label, name)
+			case len(stack) > 0 && !slices.Contains(macElements[stack[len(stack)-1]].children, name):
+				t.Errorf("%s tiene %q dentro de %q", label, name, stack[len(stack)-1])
+			}
+			for _, attr := range element.attrs {
+				if !slices.ContainsFunc(tok.Attr, func(a xml.Attr) bool { return a.Name.Local == attr }) {
+					t.Errorf("%s tiene un %q sin el atributo %q", label, name, attr)
+				}
+			}
+			if len(stack) == 1 {
+				top = append(top, name)
+			}
+			stack = append(stack, name)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	top = slices.Compact(top)
+	if !slices.Equal(top, []string{"layouts", "modifierMap", "keyMapSet"}) {
+		t.Errorf("%s tiene los elementos %v en keyboard, se esperaba [layouts modifierMap keyMapSet]", label, top)
+	}
+
+	var doc macKeyboard
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Errorf("%s no es XML válido: %s", label, err)
+		return nil
+	}
+	if doc.ID >= 0 || doc.MaxOut < 1 {
+		t.Errorf("%s tiene id=%d y maxout=%d", label, doc.ID, doc.MaxOut)
+	}
+	if len(doc.Layouts) == 0 || len(doc.ModifierMap) != 1 || len(doc.KeyMapSet) != 1 {
+		t.Errorf("%s no tiene un layout con un modifierMap y un keyMapSet", label)
+		return nil
+	}
+	mods, set := doc.ModifierMap[0], doc.KeyMapSet[0]
+	for _, layout := range doc.Layouts {
+		if layout.First > layout.Last || layout.Modifiers != mods.ID || layout.MapSet != set.ID {
+			t.Errorf("%s tiene el layout %+v", label, layout)
+		}
+	}
+
+	maps := make(map[int]macKeyMap)
+	for i, m := range set.KeyMaps {
+		if m.Index != i {
+			t.Errorf("%s tiene el keyMap %d en la posición %d, los índices deben ser únicos y consecutivos", label, m.Index, i)
+		}
+		maps[m.Index] = m
+		codes := make(map[int]bool)
+		for _, key := range m.Keys {
+			if key.Code < 0 || key.Code > 127 || codes[key.Code] {
+				t.Errorf("%s tiene el código %d en el keyMap %d", label, key.Code, m.Index)
+			}
+			codes[key.Code] = true
+			if n := len([]rune(key.Output)); n == 0 || n > doc.MaxOut {
+				t.Errorf("%s tiene la salida %q en el código %d del keyMap %d", label, key.Output, key.Code, m.Index)
+			}
+		}
+	}
+	if _, ok := maps[mods.DefaultIndex]; !ok {
+		t.Errorf("%s tiene defaultIndex=%d sin keyMap", label, mods.DefaultIndex)
+	}
+
+	outputs := make(map[string]map[int]string)
+	for _, s := range mods.Select {
+		m, ok := maps[s.MapIndex]
+		if !ok {
+			t.Errorf("%s tiene un keyMapSelect con mapIndex=%d sin keyMap", label, s.MapIndex)
+			continue
+		}
+		for _, modifier := range s.Modifiers {
+			for _, key := range strings.Fields(modifier.Keys) {
+				if !slices.Contains(macModifierKeys, strings.TrimSuffix(key, "?")) {
+					t.Errorf("%s tiene el modificador %q", label, key)
+				}
+			}
+			if outputs[modifier.Keys] != nil {
+				t.Errorf("%s elige dos veces un keyMap con %q", label, modifier.Keys)
+			}
+			outputs[modifier.Keys] = make(map[int]string)
+			for _, key := range m.Keys {
+				outputs[modifier.Keys][key.Code] = key.Output
+			}
+		}
+	}
+
+	return outputs
+} // }}}
+
+func TestStructure(t *testing.T) { // {{{
+	names := kbd.ListLayouts()
+	sort.Strings(names)
+	for _, format := range []string{"klc", "keylayout"} {
+		f, err := Find(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range names {
+			data, _, err := Export(f, name, kbd.FindLayout(name), Options{})
+			if err != nil {
+				t.Errorf("Export(%s, %s): %s", format, name, err)
+				continue
+			}
+			label := format + "(" + name + ")"
+			if format == "klc" {
+				checkKLC(t, label, data)
+			} else {
+				checkKeylayout(t, label, data)
+			}
+		}
+	}
+} // }}}
+
+func TestAltGr(t *testing.T) { // {{{
+	k := kbd.FindLayout("spanish_qwerty")
+	klc, _ := Find("klc")
+	keylayout, _ := Find("keylayout")
+
+	data, warnings, err := Export(klc, "spanish_qwerty", k, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w, "AltGr") {
+			t.Errorf("klc avisa que no exporta AltGr: %s", w)
+		}
+	}
+	// AltGr es Ctrl+Alt en el estado 6 y AltGr+Shift el 7, `3` no tiene AltGr+Shift
+	layout := checkKLC(t, "klc(spanish_qwerty)", data)
+	for sc, want := range map[string][2]string{"03": {"0040", "-1"}, "12": {"20ac", "-1"}, "1a": {"005b", "-1"}, "05": {"007e", "-1"}, "10": {"-1", "-1"}} {
+		if got := [2]string{layout[sc]["6"], layout[sc]["7"]}; got != want {
+			t.Errorf("klc(spanish_qwerty) tiene %q con AltGr en %s, se esperaba %q", got, sc, want)
+		}
+	}
+
+	data, _, err = Export(keylayout, "spanish_qwerty", k, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// En macOS AltGr es Option, los códigos 19, 14 y 33 son `2`, `e` y `'`
+	outputs := checkKeylayout(t, "keylayout(spanish_qwerty)", data)
+	for code, want := range map[int]string{19: "@", 14: "€", 33: "[", 12: ""} {
+		if got := outputs["anyOption caps?"][code]; got != want {
+			t.Errorf("keylayout(spanish_qwerty) escribe %q con Option en %d, se esperaba %q", got, code, want)
+		}
+	}
+	if len(outputs["anyShift anyOption caps?"]) != 0 {
+		t.Errorf("keylayout(spanish_qwerty) escribe %v con Option+Shift", outputs["anyShift anyOption caps?"])
+	}
+
+	for _, f := range []Format{klc, keylayout} {
+		data, _, _ := f.Export("spanish_qwerty", k, Options{})
+		parsed, _, err := f.Parse(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parsed.AltGr[kbd.Row1]; !slices.Equal(got, k.AltGr[kbd.Row1]) {
+			t.Errorf("Parse(%s(spanish_qwerty)) tiene AltGr %q en %s, se esperaba %q", f.Name, got, kbd.Row1, k.AltGr[kbd.Row1])
+		}
+	}
+} // }}}
@@ -0,0 +1,129 @@
+package keymap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keysyms son los nombres de X11 de los caracteres que no son letras ni números ASCII, los demás se
+// escriben como `U` más su código Unicode.
+var keysyms = map[rune]string{
+	' ':  "space",
+	'!':  "exclam",
+	'"':  "quotedbl",
+	'#':  "numbersign",
+	'$':  "dollar",
+	'%':  "percent",
+	'&':  "ampersand",
+	'\'': "apostrophe",
+	'(':  "parenleft",
+	')':  "parenright",
+	'*':  "asterisk",
+	'+':  "plus",
+	',':  "comma",
+	'-':  "minus",
+	'.':  "period",
+	'/':  "slash",
+	':':  "colon",
+	';':  "semicolon",
+	'<':  "less",
+	'=':  "equal",
+	'>':  "greater",
+	'?':  "question",
+	'@':  "at",
+	'[':  "bracketleft",
+	'\\': "backslash",
+	']':  "bracketright",
+	'^':  "asciicircum",
+	'_':  "underscore",
+	'`':  "grave",
+	'{':  "braceleft",
+	'|':  "bar",
+	'}':  "braceright",
+	'~':  "asciitilde",
+	'¡':  "exclamdown",
+	'£':  "sterling",
+	'§':  "section",
+	'¨':  "diaeresis",
+	'ª':  "ordfeminine",
+	'¬':  "notsign",
+	'°':  "degree",
+	'´':  "acute",
+	'µ':  "mu",
+	'·':  "periodcentered",
+	'º':  "masculine",
+	'¿':  "questiondown",
+	'ç':  "ccedilla",
+	'Ç':  "Ccedilla",
+	'ñ':  "ntilde",
+	'Ñ':  "Ntilde",
+	'à':  "agrave",
+	'á':  "aacute",
+	'è':  "egrave",
+	'é':  "eacute",
+	'í':  "iacute",
+	'ó':  "oacute",
+	'ù':  "ugrave",
+	'ú':  "uacute",
+	'ü':  "udiaeresis",
+	'€':  "EuroSign",
+}
+
+// deadKeys son las teclas muertas cuyo nombre no coincide con el del carácter sin combinar.
+var deadKeys = map[string]rune{
+	"dead_circumflex": '^',
+	"dead_tilde":      '~',
+	"dead_cedilla":    '¸',
+	"dead_abovering":  '°',
+}
+
+var runes = make(map[string]rune, len(keysyms))
+
+func init() { // {{{
+	for r, name := range keysyms {
+		runes[name] = r
+	}
+} // }}}
+
+// keysym devuelve el nombre X11 del carácter `r`.
+func keysym(r rune) string { // {{{
+	if name, ok := keysyms[r]; ok {
+		return name
+	}
+	if r < 0x80 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+		return string(r)
+	}
+
+	return fmt.Sprintf("U%04X", r)
+} // }}}
+
+// parseKeysym es la inversa de `keysym`, también acepta los nombres de teclas muertas (`dead_acute`) como su
+// carácter sin combinar. `ok` es falso si el nombre no se conoce.
+func parseKeysym(name string) (rune, bool) { // {{{
+	if r, ok := runes[name]; ok {
+		return r, true
+	}
+	if r, ok := deadKeys[name]; ok {
+		return r, true
+	}
+	if r, ok := runes[strings.TrimPrefix(name, "dead_")]; ok {
+		return r, true
+	}
+	if r := []rune(name); len(r) == 1 {
+		return r[0], true
+	}
+	if len(name) > 1 && name[0] == 'U' {
+		if code, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return rune(code), true
+		}
+	}
+	if len(name) > 2 && strings.HasPrefix(name, "0x") {
+		// Los keysyms numéricos de Unicode son 0x01000000 más el código
+		if code, err := strconv.ParseUint(name[2:], 16, 32); err == nil && code >= 0x01000000 {
+			return rune(code - 0x01000000), true
+		}
+	}
+
+	return 0, false
+} // }}}
@@ -0,0 +1,384 @@
+package keymap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+// klcCodes son los scan codes de Windows de cada fila, en ISO la tecla junto a Enter es `2b` y la que está
+// junto a Shift izquierdo es `56`.
+var klcCodes = map[string]map[string][]string{
+	"ansi": {
+		kbd.Row1: {"29", "02", "03", "04", "05", "06", "07", "08", "09", "0a", "0b", "0c", "0d"},
+		kbd.Row2: {"10", "11", "12", "13", "14", "15", "16", "17", "18", "19", "1a", "1b", "2b"},
+		kbd.Row3: {"1e", "1f", "20", "21", "22", "23", "24", "25", "26", "27", "28"},
+		kbd.Row4: {"2c", "2d", "2e", "2f", "30", "31", "32", "33", "34", "35"},
+	},
+	"iso": {
+		kbd.Row1: {"29", "02", "03", "04", "05", "06", "07", "08", "09", "0a", "0b", "0c", "0d"},
+		kbd.Row2: {"10", "11", "12", "13", "14", "15", "16", "17", "18", "19", "1a", "1b"},
+		kbd.Row3: {"1e", "1f", "20", "21", "22", "23", "24", "25", "26", "27", "28", "2b"},
+		kbd.Row4: {"56", "2c", "2d", "2e", "2f", "30", "31", "32", "33", "34", "35"},
+	},
+}
+
+// usVK son las teclas virtuales del layout de Estados Unidos para cada scan code.
+var usVK = map[string]string{
+	"29": "OEM_3", "02": "1", "03": "2", "04": "3", "05": "4", "06": "5", "07": "6", "08": "7", "09": "8",
+	"0a": "9", "0b": "0", "0c": "OEM_MINUS", "0d": "OEM_PLUS",
+	"10": "Q", "11": "W", "12": "E", "13": "R", "14": "T", "15": "Y", "16": "U", "17": "I", "18": "O",
+	"19": "P", "1a": "OEM_4", "1b": "OEM_6", "2b": "OEM_5",
+	"1e": "A", "1f": "S", "20": "D", "21": "F", "22": "G", "23": "H", "24": "J", "25": "K", "26": "L",
+	"27": "OEM_1", "28": "OEM_7",
+	"2c": "Z", "2d": "X", "2e": "C", "2f": "V", "30": "B", "31": "N", "32": "M", "33": "OEM_COMMA",
+	"34": "OEM_PERIOD", "35": "OEM_2", "56": "OEM_102",
+}
+
+// usChars son los caracteres sin Shift del layout de Estados Unidos para cada tecla virtual, sirven para que
+// la tecla virtual siga al carácter y los atajos como Ctrl+C funcionen en cualquier posición.
+var usChars = map[rune]string{
+	'`': "OEM_3", '-': "OEM_MINUS", '=': "OEM_PLUS", '[': "OEM_4", ']': "OEM_6", '\\': "OEM_5",
+	';': "OEM_1", '\'': "OEM_7", ',': "OEM_COMMA", '.': "OEM_PERIOD", '/': "OEM_2",
+}
+
+var reKLCName = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+func init() { // {{{
+	register(Format{
+		Name:    "klc",
+		File:    func(name string) string { return klcName(name) + ".klc" },
+		Export:  KLC,
+		Parse:   ParseKLC,
+		AltGr:   true,
+		Install: klcInstall,
+	})
+} // }}}
+
+// klcName devuelve el nombre corto del layout, Windows acepta hasta 8 caracteres alfanuméricos.
+func klcName(name string) string { // {{{
+	short := reKLCName.ReplaceAllString(name, "")
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	if short == "" {
+		short = "thot"
+	}
+
+	return short
+} // }}}
+
+// KLC genera el archivo fuente de Microsoft Keyboard Layout Creator del layout `name`, en UTF-16 con BOM y
+// fin de línea CRLF como lo espera MSKLC. Si el layout tiene caracteres con AltGr agrega las columnas de
+// Ctrl+Alt y Shift+Ctrl+Alt, que es como Windows interpreta AltGr.
+func KLC(name string, k *kbd.Keyboard, _ Options) ([]byte, []string, errors.E) { // {{{
+	table := codes(klcCodes, k.Type)
+	vks, err := klcVKs(k, table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := []string{
+		fmt.Sprintf("KBD\t%s\t\"Thot (%s)\"", klcName(name), name),
+		"",
+		"COPYRIGHT\t\"Thot\"",
+		"",
+		"COMPANY\t\"Thot\"",
+		"",
+		"LOCALENAME\t\"en-US\"",
+		"",
+		"LOCALEID\t\"00000409\"",
+		"",
+		"VERSION\t1.0",
+		"",
+		"SHIFTSTATE",
+		"",
+		"0\t//Column 4",
+		"1\t//Column 5 : Shft",
+	}
+	withAltGr := len(k.AltGr) > 0
+	if withAltGr {
+		lines = append(lines,
+			"6\t//Column 6 : Ctrl Alt",
+			"7\t//Column 7 : Shft Ctrl Alt",
+		)
+	}
+	header, rule := "//SC\tVK_\t\tCap\t0\t1", "//--\t----\t\t----\t----\t----"
+	if withAltGr {
+		header, rule = header+"\t6\t7", rule+"\t----\t----"
+	}
+	lines = append(lines,
+		"",
+		"LAYOUT\t\t;an extra '@' at the end is a dead key",
+		"",
+		header,
+		rule,
+		"",
+	)
+	for _, row := range rows {
+		for col, key := range k.Keys[row] {
+			lower, upper, ok := levels(key)
+			if !ok {
+				continue
+			}
+			sc := table[row][col]
+			capsLock := 0
+			if caps(lower, upper) {
+				capsLock = 1
+			}
+			columns := []string{klcChar(lower), klcChar(upper)}
+			chars := []string{string(lower), string(upper)}
+			if withAltGr {
+				// `-1` es un nivel sin carácter
+				alt := []string{"-1", "-1"}
+				for i, r := range altgr(k, row, col) {
+					alt[i] = klcChar(r)
+					chars = append(chars, string(r))
+				}
+				columns = append(columns, alt...)
+			}
+			lines = append(lines, fmt.Sprintf(
+				"%s\t%s\t\t%d\t%s\t// %s",
+				sc,
+				vks[sc],
+				capsLock,
+				strings.Join(columns, "\t"),
+				strings.Join(chars, ", "),
+			))
+		}
+	}
+	lines = append(lines,
+		"",
+		"DESCRIPTIONS",
+		"",
+		fmt.Sprintf("0409\tThot (%s)", name),
+		"",
+		"LANGUAGENAMES",
+		"",
+		"0409\tEnglish (United States)",
+		"",
+		"ENDKBD",
+		"",
+	)
+
+	text := utf16.Encode([]rune(strings.Join(lines, "\r\n")))
+	buf := bytes.Buffer{}
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(0xfeff))
+	_ = binary.Write(&buf, binary.LittleEndian, text)
+
+	return buf.Bytes(), nil, nil
+} // }}}
+
+// klcVKs asigna la tecla virtual de cada scan code: primero la que produce el mismo carácter en el layout
+// de Estados Unidos y luego, a las teclas restantes, las teclas virtuales que quedaron libres.
+func klcVKs(k *kbd.Keyboard, table map[string][]string) (map[string]string, errors.E) { // {{{
+	vks := make(map[string]string)
+	used := make(map[string]bool)
+	var pending []string
+	for _, row := range rows {
+		if len(k.Keys[row]) > len(table[row]) {
+			return nil, errors.WithDetails(
+				errors.New(i18n.T("La fila tiene más teclas que el teclado")),
+				"row", row,
+				"keys", len(k.Keys[row]),
+				"max", len(table[row]),
+			)
+		}
+		for col, key := range k.Keys[row] {
+			sc := table[row][col]
+			lower, _, ok := levels(key)
+			if !ok {
+				continue
+			}
+			vk := usChars[lower]
+			if c := unicode.ToUpper(lower); c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+				vk = string(c)
+			}
+			if vk == "" || used[vk] {
+				pending = append(pending, sc)
+				continue
+			}
+			vks[sc] = vk
+			used[vk] = true
+		}
+	}
+
+	var free []string
+	for _, sc := range pending {
+		if vk := usVK[sc]; !used[vk] {
+			vks[sc] = vk
+			used[vk] = true
+			continue
+		}
+		free = append(free, sc)
+	}
+	if len(free) > 0 {
+		var left []string
+		for _, vk := range usVK {
+			if !used[vk] {
+				left = append(left, vk)
+			}
+		}
+		sort.Strings(left)
+		for i, sc := range free {
+			vks[sc] = left[i]
+		}
+	}
+
+	return vks, nil
+} // }}}
+
+// klcChar escribe un carácter como lo espera MSKLC: las letras y números ASCII tal cual y el resto como su
+// código Unicode en hexadecimal.
+func klcChar(r rune) string { // {{{
+	if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return string(r)
+	}
+
+	return fmt.Sprintf("%04x", r)
+} // }}}
+
+// ParseKLC lee la sección `LAYOUT` de un archivo de MSKLC en UTF-16 o UTF-8, devuelve además las teclas y
+// caracteres que no se pudieron asignar. Las columnas de cada nivel se toman de la sección `SHIFTSTATE`, los
+// estados `6` y `7` son AltGr y AltGr+Shift. Si el archivo define el scan code `56` el teclado es ISO.
+func ParseKLC(data []byte) (*kbd.Keyboard, []string, errors.E) { // {{{
+	text := decodeUTF16(data)
+
+	var unknown []string
+	found := make(map[string]string)
+	foundAltGr := make(map[string]string)
+	// columns es la columna de `LAYOUT` de cada estado de `SHIFTSTATE`, las tres primeras son el scan code,
+	// la tecla virtual y Bloq Mayús
+	columns := make(map[string]int)
+	inShiftState := false
+	inLayout := false
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "SHIFTSTATE":
+			inShiftState = true
+			continue
+		case "LAYOUT":
+			inShiftState, inLayout = false, true
+			continue
+		case "DEADKEY", "KEYNAME", "KEYNAME_EXT", "KEYNAME_DEAD", "DESCRIPTIONS", "LANGUAGENAMES", "LIGATURE", "ENDKBD":
+			inShiftState, inLayout = false, false
+		}
+		if inShiftState {
+			columns[fields[0]] = 3 + len(columns)
+			continue
+		}
+		if !inLayout {
+			continue
+		}
+		// Sin `SHIFTSTATE` se asume la de `KLC`
+		base, shift := 3, 4
+		if c, ok := columns["0"]; ok {
+			base = c
+		}
+		if c, ok := columns["1"]; ok {
+			shift = c
+		}
+		if len(fields) <= max(base, shift) {
+			continue
+		}
+		sc := strings.ToLower(fields[0])
+		lower, ok1 := parseKLCChar(fields[base])
+		upper, ok2 := parseKLCChar(fields[shift])
+		if !ok1 || !ok2 {
+			unknown = append(unknown, fmt.Sprintf("%s %s %s", sc, fields[base], fields[shift]))
+			continue
+		}
+		found[sc] = string([]rune{lower, upper})
+
+		var alt []rune
+		for _, state := range []string{"6", "7"} {
+			c, ok := columns[state]
+			if !ok || c >= len(fields) || fields[c] == "-1" {
+				break
+			}
+			r, ok := parseKLCChar(fields[c])
+			if !ok {
+				unknown = append(unknown, fmt.Sprintf("%s %s", sc, fields[c]))
+				break
+			}
+			alt = append(alt, r)
+		}
+		if len(alt) > 0 {
+			foundAltGr[sc] = string(alt)
+		}
+	}
+	if len(found) == 0 {
+		return nil, unknown, errors.New(i18n.T("El archivo no define ninguna tecla"))
+	}
+
+	k := &kbd.Keyboard{Type: "ansi", Keys: make(map[string][]string)}
+	if _, ok := found["56"]; ok {
+		k.Type = "iso"
+	}
+	// El scan code `39` es la barra espaciadora
+	unknown = append(unknown, assign(k, codes(klcCodes, k.Type), found, map[string]bool{"39": true})...)
+	assignAltGr(k, codes(klcCodes, k.Type), foundAltGr)
+	sort.Strings(unknown)
+
+	return k, unknown, nil
+} // }}}
+
+// parseKLCChar es la inversa de `klcChar`, ignora la marca `@` de las teclas muertas.
+func parseKLCChar(s string) (rune, bool) { // {{{
+	s = strings.TrimSuffix(s, "@")
+	if r := []rune(s); len(r) == 1 {
+		return r[0], true
+	}
+	if code, err := strconv.ParseUint(s, 16, 32); err == nil && len(s) == 4 {
+		return rune(code), true
+	}
+
+	return 0, false
+} // }}}
+
+// decodeUTF16 convierte a texto un archivo en UTF-16 con BOM, sin BOM lo trata como UTF-8.
+func decodeUTF16(data []byte) string { // {{{
+	var order binary.ByteOrder
+	switch {
+	case len(data) >= 2 && data[0] == 0xff && data[1] == 0xfe:
+		order = binary.LittleEndian
+	case len(data) >= 2 && data[0] == 0xfe && data[1] == 0xff:
+		order = binary.BigEndian
+	default:
+		return strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r", "")
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for i := 2; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+
+	return strings.ReplaceAll(string(utf16.Decode(units)), "\r", "")
+} // }}}
+
+func klcInstall(name, path string) string { // {{{
+	return strings.Join([]string{
+		i18n.T("En Windows:"),
+		i18n.T("  1. Abrir %s con Microsoft Keyboard Layout Creator 1.4", path),
+		i18n.T("  2. Elegir `Project > Build DLL and Setup Package`"),
+		i18n.T("  3. Ejecutar el `setup.exe` generado y reiniciar la sesión"),
+		i18n.T("  4. Agregar `Thot (%s)` en `Configuración > Hora e idioma > Idioma`", name),
+	}, "\n")
+} // }}}
@@ -0,0 +1,187 @@
+package keymap
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+// kleMinKeys es la cantidad mínima de caracteres que debe tener una fila para ser una fila de Thot, las
+// filas de teclas de función o de la barra espaciadora no tienen caracteres.
+const kleMinKeys = 4
+
+// kleGap es la separación a partir de la cual las teclas siguientes de la fila son de otro bloque, como las
+// flechas o el teclado numérico.
+const kleGap = 0.25
+
+var reKLETag = regexp.MustCompile(`<[^>]*>`)
+
+// kleModifiers son las teclas que no tienen caracteres y no se informan como no asignadas.
+var kleModifiers = map[string]bool{
+	"esc": true, "escape": true, "tab": true, "caps": true, "caps lock": true, "capslock": true, "shift": true,
+	"enter": true, "return": true, "backspace": true, "back space": true, "ctrl": true, "control": true,
+	"alt": true, "altgr": true, "alt gr": true, "win": true, "super": true, "meta": true, "cmd": true,
+	"command": true, "option": true, "fn": true, "menu": true, "space": true, "del": true, "delete": true,
+	"⇧": true, "⇪": true, "⇥": true, "↹": true, "⌫": true, "←": true, "↵": true, "⏎": true, "⌘": true,
+	"⌥": true, "⌃": true,
+}
+
+func init() { // {{{
+	importers["kle"] = ParseKLE
+} // }}}
+
+// ParseKLE lee un archivo de keyboard-layout-editor.com, tanto el JSON descargado como el texto de la pestaña
+// `Raw data`. Las filas con caracteres son las filas de Thot; en cada tecla la leyenda superior es el carácter
+// con Shift y la inferior el normal, una tecla con una sola leyenda usa `shifted`.
+func ParseKLE(data []byte) (*kbd.Keyboard, []string, errors.E) { // {{{
+	text := kleJSON(strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff")))
+	// El texto de `Raw data` son las filas separadas por comas, sin el arreglo que las contiene
+	var doc []json.RawMessage
+	if json.Unmarshal([]byte(text), &doc) != nil || kleSingleRow(doc) {
+		if err := json.Unmarshal([]byte("["+text+"]"), &doc); err != nil {
+			return nil, nil, errors.WithMessage(err, i18n.T("El archivo no es un JSON de keyboard-layout-editor válido"))
+		}
+	}
+
+	var found [][]string
+	var unknown []string
+	for _, raw := range doc {
+		var items []json.RawMessage
+		// El primer elemento puede ser un objeto con los datos del teclado
+		if json.Unmarshal(raw, &items) != nil {
+			continue
+		}
+		keys, bad := kleRow(items)
+		if len(keys) >= kleMinKeys {
+			found = append(found, keys)
+			unknown = append(unknown, bad...)
+		}
+	}
+	if len(found) == 0 {
+		return nil, unknown, errors.New(i18n.T("El archivo no define ninguna tecla"))
+	}
+	k, err := rowsOf(found)
+	if err != nil {
+		return nil, unknown, err
+	}
+
+	return k, unknown, nil
+} // }}}
+
+// kleSingleRow indica si el documento es una sola fila de `Raw data`, sus elementos son las teclas.
+func kleSingleRow(doc []json.RawMessage) bool { // {{{
+	for _, item := range doc {
+		if len(item) > 0 && item[0] == '"' {
+			return true
+		}
+	}
+
+	return false
+} // }}}
+
+// kleRow devuelve las teclas de una fila hasta la primera separación después de un carácter, y las leyendas
+// que no se pudieron interpretar.
+func kleRow(items []json.RawMessage) ([]string, []string) { // {{{
+	var keys, unknown []string
+	for _, item := range items {
+		var props struct {
+			X float64 `json:"x"`
+		}
+		if json.Unmarshal(item, &props) == nil {
+			if props.X >= kleGap && len(keys) > 0 {
+				break
+			}
+			continue
+		}
+		var legend string
+		if json.Unmarshal(item, &legend) != nil {
+			continue
+		}
+		key, ok := kleKey(legend)
+		switch {
+		case ok:
+			keys = append(keys, key)
+		case key != "" && !kleModifiers[strings.ToLower(key)]:
+			unknown = append(unknown, fmt.Sprintf("%q", key))
+		}
+	}
+
+	return keys, unknown
+} // }}}
+
+// kleKey devuelve la tecla de una leyenda de KLE, las posiciones de la leyenda están separadas por `\n`. Si
+// no es un carácter devuelve `ok` falso y el texto de la leyenda.
+func kleKey(legend string) (string, bool) { // {{{
+	var labels []string
+	for _, l := range strings.Split(legend, "\n") {
+		l = strings.TrimSpace(html.UnescapeString(reKLETag.ReplaceAllString(l, "")))
+		if l != "" {
+			labels = append(labels, l)
+		}
+	}
+	if len(labels) == 0 {
+		return "", false
+	}
+	first := []rune(labels[0])
+	if len(first) != 1 || kleModifiers[labels[0]] {
+		return strings.Join(labels, " "), false
+	}
+	if len(labels) == 1 {
+		return shifted(unicode.ToLower(first[0])), true
+	}
+	second := []rune(labels[1])
+	if len(second) != 1 {
+		return strings.Join(labels, " "), false
+	}
+
+	return string([]rune{second[0], first[0]}), true
+} // }}}
+
+// kleJSON convierte el texto de la pestaña `Raw data` de KLE, que no pone comillas en los nombres de las
+// propiedades como `{w:1.5}`, en JSON válido.
+func kleJSON(text string) string { // {{{
+	sb := strings.Builder{}
+	inString := false
+	expectName := false
+	r := []rune(text)
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+		switch {
+		case inString:
+			sb.WriteRune(c)
+			if c == '\\' && i+1 < len(r) {
+				i++
+				sb.WriteRune(r[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		case c == '"':
+			inString = true
+			expectName = false
+		case c == '{' || c == ',':
+			expectName = true
+		case expectName && (unicode.IsLetter(c) || c == '_'):
+			j := i
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_') {
+				j++
+			}
+			sb.WriteString(`"` + string(r[i:j]) + `"`)
+			i = j - 1
+			expectName = false
+			continue
+		case !unicode.IsSpace(c):
+			expectName = false
+		}
+		sb.WriteRune(c)
+	}
+
+	return sb.String()
+} // }}}
@@ -0,0 +1,186 @@
+package keymap
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"gitlab.com/tozd/go/errors"
+)
+
+// xkbCodes son los nombres de tecla de XKB de cada fila, en ISO la tecla junto a Enter es `BKSL` y la que
+// está junto a Shift izquierdo es `LSGT`.
+var xkbCodes = map[string]map[string][]string{
+	"ansi": {
+		kbd.Row1: {"TLDE", "AE01", "AE02", "AE03", "AE04", "AE05", "AE06", "AE07", "AE08", "AE09", "AE10", "AE11", "AE12"},
+		kbd.Row2: {"AD01", "AD02", "AD03", "AD04", "AD05", "AD06", "AD07", "AD08", "AD09", "AD10", "AD11", "AD12", "BKSL"},
+		kbd.Row3: {"AC01", "AC02", "AC03", "AC04", "AC05", "AC06", "AC07", "AC08", "AC09", "AC10", "AC11"},
+		kbd.Row4: {"AB01", "AB02", "AB03", "AB04", "AB05", "AB06", "AB07", "AB08", "AB09", "AB10"},
+	},
+	"iso": {
+		kbd.Row1: {"TLDE", "AE01", "AE02", "AE03", "AE04", "AE05", "AE06", "AE07", "AE08", "AE09", "AE10", "AE11", "AE12"},
+		kbd.Row2: {"AD01", "AD02", "AD03", "AD04", "AD05", "AD06", "AD07", "AD08", "AD09", "AD10", "AD11", "AD12"},
+		kbd.Row3: {"AC01", "AC02", "AC03", "AC04", "AC05", "AC06", "AC07", "AC08", "AC09", "AC10", "AC11", "BKSL"},
+		kbd.Row4: {"LSGT", "AB01", "AB02", "AB03", "AB04", "AB05", "AB06", "AB07", "AB08", "AB09", "AB10"},
+	},
+}
+
+// xkbIgnored son las teclas que aparecen en los archivos XKB pero no forman parte de las filas de Thot.
+var xkbIgnored = map[string]bool{"SPCE": true}
+
+var (
+	reXKBComment = regexp.MustCompile(`(?m)//.*$`)
+	reXKBKey     = regexp.MustCompile(`key\s*<(\w+)>\s*\{([^}]*)\}`)
+	// Los símbolos de una tecla son la lista `symbols[GroupN] = [ ... ]` o la primera lista sin nombre, los
+	// corchetes de `type[Group1]` o `actions[Group1]` no son símbolos
+	reXKBSymbols = regexp.MustCompile(`symbols\[Group\d\]\s*=\s*\[([^\]]*)\]`)
+	reXKBList    = regexp.MustCompile(`(?:^|,)\s*\[([^\]]*)\]`)
+)
+
+func init() { // {{{
+	register(Format{
+		Name:    "xkb",
+		File:    func(name string) string { return "thot_" + name },
+		Export:  XKB,
+		Parse:   ParseXKB,
+		Install: xkbInstall,
+	})
+} // }}}
+
+// XKB genera el archivo `symbols` de XKB del layout `name`, las teclas que no están en el layout se toman
+// de `us(basic)`.
+func XKB(name string, k *kbd.Keyboard, _ Options) ([]byte, []string, errors.E) { // {{{
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("// Layout %q generado por Thot\n", name))
+	sb.WriteString("default partial alphanumeric_keys\n")
+	sb.WriteString("xkb_symbols \"basic\" {\n")
+	sb.WriteString("    include \"us(basic)\"\n")
+	sb.WriteString(fmt.Sprintf("    name[Group1] = \"Thot (%s)\";\n", name))
+
+	table := codes(xkbCodes, k.Type)
+	for _, row := range rows {
+		sb.WriteString("\n")
+		keys := k.Keys[row]
+		if len(keys) > len(table[row]) {
+			return nil, nil, errors.WithDetails(
+				errors.New(i18n.T("La fila tiene más teclas que el teclado")),
+				"row", row,
+				"keys", len(keys),
+				"max", len(table[row]),
+			)
+		}
+		for col, key := range keys {
+			lower, upper, ok := levels(key)
+			if !ok {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf(
+				"    key <%s> { [ %s, %s ] };\n",
+				table[row][col],
+				keysym(lower),
+				keysym(upper),
+			))
+		}
+	}
+	sb.WriteString("};\n")
+
+	return []byte(sb.String()), nil, nil
+} // }}}
+
+// ParseXKB lee las teclas alfanuméricas del primer bloque `xkb_symbols` de un archivo XKB, devuelve además
+// las teclas y símbolos que no se pudieron asignar. Si el archivo define `LSGT` el teclado es ISO.
+func ParseXKB(data []byte) (*kbd.Keyboard, []string, errors.E) { // {{{
+	text := reXKBComment.ReplaceAllString(string(data), "")
+	start := strings.Index(text, "xkb_symbols")
+	if start < 0 {
+		return nil, nil, errors.New(i18n.T("El archivo no tiene un bloque `xkb_symbols`"))
+	}
+	text = xkbBlock(text[start:])
+
+	var unknown []string
+	found := make(map[string]string)
+	for _, m := range reXKBKey.FindAllStringSubmatch(text, -1) {
+		code := m[1]
+		list := reXKBSymbols.FindStringSubmatch(m[2])
+		if list == nil {
+			list = reXKBList.FindStringSubmatch(m[2])
+		}
+		if list == nil {
+			continue
+		}
+		var syms []string
+		for _, s := range strings.Split(list[1], ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				syms = append(syms, s)
+			}
+		}
+		if len(syms) == 0 {
+			continue
+		}
+		lower, ok := parseKeysym(syms[0])
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("<%s> %s", code, syms[0]))
+			continue
+		}
+		// Con un solo símbolo XKB usa la mayúscula de la letra en el segundo nivel
+		upper := unicode.ToUpper(lower)
+		if len(syms) > 1 {
+			if upper, ok = parseKeysym(syms[1]); !ok {
+				unknown = append(unknown, fmt.Sprintf("<%s> %s", code, syms[1]))
+				continue
+			}
+		}
+		found[code] = string([]rune{lower, upper})
+	}
+	if len(found) == 0 {
+		return nil, unknown, errors.New(i18n.T("El archivo no define ninguna tecla"))
+	}
+
+	k := &kbd.Keyboard{Type: "ansi", Keys: make(map[string][]string)}
+	if _, ok := found["LSGT"]; ok {
+		k.Type = "iso"
+	}
+	for _, code := range assign(k, codes(xkbCodes, k.Type), found, xkbIgnored) {
+		unknown = append(unknown, fmt.Sprintf("<%s>", code))
+	}
+	sort.Strings(unknown)
+
+	return k, unknown, nil
+} // }}}
+
+// xkbBlock devuelve el bloque `xkb_symbols` al inicio de `text` hasta la llave que lo cierra, las teclas
+// pueden ocupar varias líneas y terminar también en `};`.
+func xkbBlock(text string) string { // {{{
+	depth := 0
+	for i, r := range text {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return text[:i]
+			}
+		}
+	}
+
+	return text
+} // }}}
+
+func xkbInstall(name, path string) string { // {{{
+	file := "thot_" + name
+	return strings.Join([]string{
+		i18n.T("Wayland (libxkbcommon), solo para el usuario actual:"),
+		fmt.Sprintf("  mkdir -p ~/.config/xkb/symbols && cp %s ~/.config/xkb/symbols/%s", path, file),
+		i18n.T("  y usar `%s` como layout en la configuración del compositor, p.e. `xkb_layout %s` en sway", file, file),
+		"",
+		i18n.T("X11, para todo el sistema:"),
+		fmt.Sprintf("  sudo cp %s /usr/share/X11/xkb/symbols/%s", path, file),
+		fmt.Sprintf("  setxkbmap %s", file),
+		"",
+		i18n.T("Para volver al layout anterior: `setxkbmap us` o el layout que se usaba"),
+	}, "\n")
+} // }}}
@@ -0,0 +1,60 @@
+package keymap
+
+import (
+	"os"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/wrodriguez/thot/internal/kbd"
+)
+
+func TestXKBRoundTrip(t *testing.T) { // {{{
+	names := kbd.ListLayouts()
+	sort.Strings(names)
+	for _, name := range names {
+		k := kbd.FindLayout(name)
+		data, _, err := XKB(name, k, Options{})
+		if err != nil {
+			t.Errorf("XKB(%s): %s", name, err)
+			continue
+		}
+		parsed, unknown, err := ParseXKB(data)
+		if err != nil {
+			t.Errorf("ParseXKB(XKB(%s)): %s", name, err)
+			continue
+		}
+		if len(unknown) > 0 {
+			t.Errorf("ParseXKB(XKB(%s)) no pudo leer %v", name, unknown)
+		}
+		sameRows(t, "ParseXKB(XKB("+name+"))", k, parsed)
+	}
+} // }}}
+
+func TestParseXKBGroup1(t *testing.T) { // {{{
+	data, err := os.ReadFile("testdata/symbols_group1.xkb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, unknown, e := ParseXKB(data)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(unknown) > 0 {
+		t.Errorf("ParseXKB no pudo leer %v", unknown)
+	}
+	want := map[string][]string{
+		kbd.Row1: {},
+		kbd.Row2: {"qQ", "wW", "eE"},
+		kbd.Row3: {"aA", "sS", "", "", "", "", "", "", "", "ñÑ"},
+		kbd.Row4: {"zZ"},
+	}
+	for _, row := range rows {
+		if !slices.Equal(k.Keys[row], want[row]) {
+			t.Errorf("fila %s = %q, se esperaba %q", row, k.Keys[row], want[row])
+		}
+	}
+	if k.Type != "ansi" {
+		t.Errorf("el tipo es %q, se esperaba ansi", k.Type)
+	}
+} // }}}
@@ -0,0 +1,228 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// DefaultProfile es el perfil que se usa cuando no se indica ninguno, sus archivos están en el directorio base.
+const DefaultProfile = "default"
+
+const profilesDir = "profiles"
+
+var reProfile = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+var profile = DefaultProfile
+
+// Kind es el tipo de archivos de un directorio, cada tipo se puede ubicar en un lugar distinto siguiendo la
+// especificación XDG Base Directory.
+type Kind int
+
+const (
+	// Config contiene la configuración (`XDG_CONFIG_HOME`).
+	Config Kind = iota
+	// Data contiene los diccionarios: la base de datos de palabras y las listas del usuario (`XDG_DATA_HOME`).
+	Data
+	// State contiene el historial y el progreso (`XDG_STATE_HOME`).
+	State
+)
+
+// HomeEnv es la variable de entorno que fuerza un único directorio para todos los archivos de Thot.
+const HomeEnv = "THOT_HOME"
+
+var xdgEnv = map[Kind]string{
+	Config: "XDG_CONFIG_HOME",
+	Data:   "XDG_DATA_HOME",
+	State:  "XDG_STATE_HOME",
+}
+
+// defaultDirs son los directorios de cada tipo dentro de `$HOME` cuando no hay variables XDG, los que indica
+// la especificación XDG Base Directory.
+var defaultDirs = map[Kind][]string{
+	Config: {".config", "thot"},
+	Data:   {".local", "share", "thot"},
+	State:  {".local", "state", "thot"},
+}
+
+// legacyFiles son los archivos de cada tipo que las versiones anteriores guardaban en `$HOME/.config/thot`.
+// La lista no cambia con los archivos nuevos, esos nunca estuvieron en la ubicación anterior.
+var legacyFiles = map[Kind][]string{
+	Data:  {"words.db", "words"},
+	State: {"history.jsonl", "achievements.json", "sessions"},
+}
+
+var dataDir string
+
+// SetDataDir cambia el directorio de los diccionarios (`--data-dir`), tiene prioridad sobre `THOT_HOME` y
+// `XDG_DATA_HOME`.
+func SetDataDir(dir string) { // {{{
+	dataDir = dir
+} // }}}
+
+// Dir devuelve el directorio base de un tipo de archivos, se resuelve en este orden:
+//
+//  1. `--data-dir`, solo para `Data`.
+//  2. `$THOT_HOME`.
+//  3. `$XDG_CONFIG_HOME/thot`, `$XDG_DATA_HOME/thot` o `$XDG_STATE_HOME/thot` según el tipo.
+//  4. `$HOME/.config/thot`, la ubicación de las versiones anteriores, solo si ya tiene archivos del tipo para
+//     no perderlos (ver `legacyFiles`).
+//  5. `$HOME/.config/thot`, `$HOME/.local/share/thot` o `$HOME/.local/state/thot` según el tipo.
+//
+// Los archivos compartidos por todos los perfiles, como la base de datos de palabras, se guardan aquí.
+func Dir(kind Kind) (string, errors.E) { // {{{
+	if kind == Data && dataDir != "" {
+		dir, err := filepath.Abs(dataDir)
+		if err != nil {
+			return "", errors.WithDetails(err, "path", dataDir)
+		}
+		return dir, nil
+	}
+	if home := os.Getenv(HomeEnv); home != "" {
+		return home, nil
+	}
+	// La especificación XDG indica que se ignoren las rutas relativas
+	if xdg := os.Getenv(xdgEnv[kind]); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "thot"), nil
+	}
+
+	h, err := home()
+	if err != nil {
+		return "", err
+	}
+	if legacy := filepath.Join(h, ".config", "thot"); hasLegacy(legacy, kind) {
+		return legacy, nil
+	}
+
+	return filepath.Join(append([]string{h}, defaultDirs[kind]...)...), nil
+} // }}}
+
+// home devuelve el directorio HOME del usuario.
+func home() (string, errors.E) { // {{{
+	h, err := os.UserHomeDir()
+	if err != nil {
+		h = os.Getenv("HOME")
+		if h == "" {
+			return "", errors.WithMessage(err, i18n.T("No se pudo obtener el directorio HOME"))
+		}
+	}
+
+	return h, nil
+} // }}}
+
+// hasLegacy indica si el directorio de las versiones anteriores `dir` tiene archivos del tipo `kind`, en el
+// directorio base o en el de algún perfil.
+func hasLegacy(dir string, kind Kind) bool { // {{{
+	for _, name := range legacyFiles[kind] {
+		for _, pattern := range []string{filepath.Join(dir, name), filepath.Join(dir, profilesDir, "*", name)} {
+			if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+} // }}}
+
+// ValidProfile indica si `name` es un nombre de perfil válido.
+func ValidProfile(name string) bool { // {{{
+	return reProfile.MatchString(name)
+} // }}}
+
+// SetProfile selecciona el perfil activo.
+func SetProfile(name string) errors.E { // {{{
+	if !ValidProfile(name) {
+		return errors.New(i18n.T("El nombre de perfil %q no es valido, solo se aceptan letras, números, `-` y `_`", name))
+	}
+	profile = name
+
+	return nil
+} // }}}
+
+// CurrentProfile devuelve el nombre del perfil activo.
+func CurrentProfile() string { // {{{
+	return profile
+} // }}}
+
+// ProfileDir devuelve el directorio de un perfil para un tipo de archivos, el perfil por defecto usa el
+// directorio base.
+func ProfileDir(kind Kind, name string) (string, errors.E) { // {{{
+	base, err := Dir(kind)
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return base, nil
+	}
+
+	return filepath.Join(base, profilesDir, name), nil
+} // }}}
+
+// ProfileDirs devuelve los directorios de un perfil sin repetidos, varios tipos pueden compartir el mismo
+// directorio (p.e. con `THOT_HOME` o en la ubicación de las versiones anteriores).
+func ProfileDirs(name string) ([]string, errors.E) { // {{{
+	var dirs []string
+	for _, kind := range []Kind{Config, Data, State} {
+		dir, err := ProfileDir(kind, name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs, nil
+} // }}}
+
+// Profile devuelve el directorio del perfil activo para un tipo de archivos.
+func Profile(kind Kind) (string, errors.E) { // {{{
+	return ProfileDir(kind, profile)
+} // }}}
+
+// Profiles devuelve la lista ordenada de perfiles existentes, el perfil por defecto siempre está incluido.
+func Profiles() ([]string, errors.E) { // {{{
+	names := []string{DefaultProfile}
+	for _, kind := range []Kind{Config, Data, State} {
+		base, err := Dir(kind)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, e := os.ReadDir(filepath.Join(base, profilesDir))
+		if e != nil && !os.IsNotExist(e) {
+			return nil, errors.WithMessage(e, i18n.T("No se pudo leer la lista de perfiles"))
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && ValidProfile(entry.Name()) && !slices.Contains(names, entry.Name()) {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names[1:])
+
+	return names, nil
+} // }}}
+
+// ProfileExists indica si existe el perfil `name`, basta con que exista alguno de sus directorios.
+func ProfileExists(name string) bool { // {{{
+	if name == DefaultProfile {
+		return true
+	}
+	dirs, err := ProfileDirs(name)
+	if err != nil {
+		return false
+	}
+	for _, dir := range dirs {
+		if info, e := os.Stat(dir); e == nil && info.IsDir() {
+			return true
+		}
+	}
+
+	return false
+} // }}}
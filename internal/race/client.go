@@ -0,0 +1,73 @@
+package race
+
+import (
+	"net"
+	"time"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// dialTimeout es el tiempo máximo para conectarse al anfitrión.
+const dialTimeout = 5 * time.Second
+
+// Client es la conexión de un participante con el anfitrión.
+type Client struct {
+	conn *Conn
+	// Messages recibe los mensajes del anfitrión después de `Listen`, se cierra al desconectarse
+	Messages chan Message
+}
+
+// Join se conecta al anfitrión en `addr` con el nombre `name`.
+func Join(addr, name string) (*Client, errors.E) { // {{{
+	c, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, errors.WithDetails(errors.WithMessage(err, i18n.T("No se pudo conectar con la carrera")), "addr", addr)
+	}
+	client := &Client{conn: newConn(c), Messages: make(chan Message, 16)}
+	if err := client.conn.Send(Message{Type: Hello, Name: name}); err != nil {
+		c.Close()
+		return nil, errors.WithMessage(err, i18n.T("No se pudo conectar con la carrera"))
+	}
+
+	return client, nil
+} // }}}
+
+// WaitStart espera a que el anfitrión comience la carrera y devuelve el mensaje con las palabras y el nombre
+// asignado al participante.
+func (c *Client) WaitStart() (Message, errors.E) { // {{{
+	for {
+		msg, err := c.conn.Receive()
+		if err != nil {
+			return msg, errors.WithMessage(err, i18n.T("Se perdió la conexión con la carrera"))
+		}
+		switch msg.Type {
+		case Start:
+			return msg, nil
+		case Reject:
+			return msg, errors.New(i18n.T("La carrera ya comenzó"))
+		}
+	}
+} // }}}
+
+// Listen recibe los mensajes del anfitrión en `Messages` hasta que se cierra la conexión.
+func (c *Client) Listen() { // {{{
+	defer close(c.Messages)
+	for {
+		msg, err := c.conn.Receive()
+		if err != nil {
+			return
+		}
+		c.Messages <- msg
+	}
+} // }}}
+
+// Send envía el avance del participante.
+func (c *Client) Send(p Player) errors.E { // {{{
+	return c.conn.Send(Message{Type: Progress, Player: &p})
+} // }}}
+
+// Close cierra la conexión con el anfitrión.
+func (c *Client) Close() error { // {{{
+	return c.conn.Close()
+} // }}}
@@ -0,0 +1,100 @@
+package race
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"gitlab.com/tozd/go/errors"
+)
+
+// DefaultAddr es la dirección donde escucha el anfitrión si no se indica otra.
+const DefaultAddr = ":7878"
+
+// writeTimeout es el tiempo máximo para enviar un mensaje, un participante que no lee no puede detener la
+// carrera de los demás.
+var writeTimeout = 5 * time.Second
+
+// Tipos de mensaje del protocolo. Cada mensaje es un objeto JSON en una línea:
+//
+//   - hello: participante → anfitrión, con el nombre del participante.
+//   - reject: anfitrión → participante, la carrera ya comenzó.
+//   - start: anfitrión → todos, con las palabras de la carrera, su código de desafío y el nombre asignado al
+//     participante (con un número si otro ya usaba el suyo).
+//   - progress: participante → anfitrión, con el avance y al terminar las estadísticas.
+//   - state: anfitrión → todos, el avance de cada participante.
+//   - ranking: anfitrión → todos, la clasificación final cuando todos terminaron.
+const (
+	Hello    = "hello"
+	Reject   = "reject"
+	Start    = "start"
+	Progress = "progress"
+	State    = "state"
+	Ranking  = "ranking"
+)
+
+// Player es el estado de un participante de la carrera.
+type Player struct {
+	Name     string  `json:"name"`
+	Done     int     `json:"done"`
+	Total    int     `json:"total"`
+	Finished bool    `json:"finished,omitempty"`
+	WPM      float64 `json:"wpm,omitempty"`
+	Accuracy float64 `json:"accuracy,omitempty"`
+	// Place es la posición de llegada, 0 mientras no termine
+	Place int `json:"place,omitempty"`
+}
+
+// Message es un mensaje del protocolo, solo se usan los campos de su tipo.
+type Message struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name,omitempty"`
+	Words   []string `json:"words,omitempty"`
+	Code    string   `json:"code,omitempty"`
+	Players []Player `json:"players,omitempty"`
+	Player  *Player  `json:"player,omitempty"`
+}
+
+// Conn envía y recibe mensajes sobre una conexión TCP, se puede enviar desde varias goroutines.
+type Conn struct {
+	conn net.Conn
+	dec  *json.Decoder
+	mu   sync.Mutex
+}
+
+func newConn(c net.Conn) *Conn { // {{{
+	return &Conn{conn: c, dec: json.NewDecoder(bufio.NewReader(c))}
+} // }}}
+
+// Send envía un mensaje, falla si no se puede escribir en `writeTimeout`.
+func (c *Conn) Send(msg Message) errors.E { // {{{
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+} // }}}
+
+// Receive espera el siguiente mensaje.
+func (c *Conn) Receive() (Message, errors.E) { // {{{
+	var msg Message
+	if err := c.dec.Decode(&msg); err != nil {
+		return msg, errors.WithStack(err)
+	}
+
+	return msg, nil
+} // }}}
+
+// Close cierra la conexión.
+func (c *Conn) Close() error { // {{{
+	return c.conn.Close()
+} // }}}
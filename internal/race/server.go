@@ -0,0 +1,231 @@
+package race
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Server es el anfitrión de una carrera, reparte el texto y retransmite el avance de cada participante.
+type Server struct {
+	ln      net.Listener
+	mu      sync.Mutex
+	players []*peer
+	started bool
+	arrived int
+	// Joined recibe el nombre de cada participante que se une antes de comenzar, se cierra con `Start`
+	Joined chan string
+}
+
+// outboxSize es la cantidad de mensajes pendientes de un participante, si no los lee a tiempo se desconecta.
+const outboxSize = 64
+
+type peer struct {
+	Player
+	conn *Conn
+	// out son los mensajes pendientes de enviar, los envía `write` para no bloquear al anfitrión
+	out  chan Message
+	gone bool
+}
+
+// Listen comienza a escuchar en `addr`, las conexiones se aceptan con `Serve`.
+func Listen(addr string) (*Server, errors.E) { // {{{
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.WithDetails(errors.WithMessage(err, i18n.T("No se pudo iniciar la carrera")), "addr", addr)
+	}
+
+	return &Server{ln: ln, Joined: make(chan string, 16)}, nil
+} // }}}
+
+// Addr devuelve la dirección local donde escucha el anfitrión.
+func (s *Server) Addr() net.Addr { // {{{
+	return s.ln.Addr()
+} // }}}
+
+// Serve acepta conexiones hasta que se cierra el anfitrión.
+func (s *Server) Serve() { // {{{
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(newConn(c))
+	}
+} // }}}
+
+// Start comienza la carrera enviando las palabras a todos los participantes, no se aceptan más. Cada uno
+// recibe también el nombre con el que aparece en el estado, ver `uniqueName`.
+func (s *Server) Start(words []string, code string) { // {{{
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = true
+	close(s.Joined)
+	for _, p := range s.players {
+		s.send(p, Message{Type: Start, Name: p.Name, Words: words, Code: code})
+	}
+} // }}}
+
+// Close deja de aceptar conexiones y desconecta a todos los participantes.
+func (s *Server) Close() { // {{{
+	s.ln.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.players {
+		p.conn.Close()
+	}
+} // }}}
+
+func (s *Server) handle(conn *Conn) { // {{{
+	hello, err := conn.Receive()
+	if err != nil || hello.Type != Hello {
+		conn.Close()
+		return
+	}
+
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		_ = conn.Send(Message{Type: Reject})
+		conn.Close()
+		return
+	}
+	p := &peer{Player: Player{Name: s.uniqueName(hello.Name)}, conn: conn, out: make(chan Message, outboxSize)}
+	s.players = append(s.players, p)
+	// Con el mutex tomado `Start` no puede cerrar `Joined` antes de avisar
+	select {
+	case s.Joined <- p.Name:
+	default:
+	}
+	s.mu.Unlock()
+	go s.write(p)
+
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			break
+		}
+		if msg.Type != Progress || msg.Player == nil {
+			continue
+		}
+		s.mu.Lock()
+		s.update(p, *msg.Player)
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	s.drop(p)
+	if s.started {
+		s.broadcast()
+	}
+	s.mu.Unlock()
+} // }}}
+
+// write envía los mensajes pendientes de un participante, si falla el envío lo desconecta.
+func (s *Server) write(p *peer) { // {{{
+	for msg := range p.out {
+		if err := p.conn.Send(msg); err != nil {
+			s.mu.Lock()
+			s.drop(p)
+			s.mu.Unlock()
+			return
+		}
+	}
+} // }}}
+
+// send agrega un mensaje a los pendientes de un participante sin esperar, si ya tiene `outboxSize`
+// pendientes lo desconecta. Se debe llamar con el mutex tomado.
+func (s *Server) send(p *peer, msg Message) { // {{{
+	if p.gone {
+		return
+	}
+	select {
+	case p.out <- msg:
+	default:
+		s.drop(p)
+	}
+} // }}}
+
+// drop desconecta a un participante, su conexión termina en `handle` que avisa a los demás. Se debe llamar
+// con el mutex tomado.
+func (s *Server) drop(p *peer) { // {{{
+	if p.gone {
+		return
+	}
+	p.gone = true
+	close(p.out)
+	p.conn.Close()
+} // }}}
+
+// uniqueName agrega un número al nombre si otro participante ya lo usa.
+func (s *Server) uniqueName(name string) string { // {{{
+	if name == "" {
+		name = i18n.T("anónimo")
+	}
+	taken := func(n string) bool {
+		return slices.ContainsFunc(s.players, func(p *peer) bool { return p.Name == n })
+	}
+	unique := name
+	for i := 2; taken(unique); i++ {
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+
+	return unique
+} // }}}
+
+// update guarda el avance de un participante y lo retransmite, se debe llamar con el mutex tomado.
+func (s *Server) update(p *peer, progress Player) { // {{{
+	if p.Finished {
+		return
+	}
+	p.Done, p.Total = progress.Done, progress.Total
+	if progress.Finished {
+		s.arrived++
+		p.Finished, p.Place = true, s.arrived
+		p.WPM, p.Accuracy = progress.WPM, progress.Accuracy
+	}
+	s.broadcast()
+} // }}}
+
+// broadcast envía el estado a todos, si ya no queda nadie corriendo envía la clasificación final.
+func (s *Server) broadcast() { // {{{
+	players := make([]Player, 0, len(s.players))
+	running := 0
+	for _, p := range s.players {
+		players = append(players, p.Player)
+		if !p.Finished && !p.gone {
+			running++
+		}
+	}
+
+	msg := Message{Type: State, Players: players}
+	if running == 0 {
+		msg = Message{Type: Ranking, Players: Rank(players)}
+	}
+	for _, p := range s.players {
+		s.send(p, msg)
+	}
+} // }}}
+
+// Rank ordena a los participantes, primero los que terminaron por orden de llegada y después los demás por
+// su avance.
+func Rank(players []Player) []Player { // {{{
+	ranked := slices.Clone(players)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Finished != b.Finished {
+			return a.Finished
+		}
+		if a.Finished {
+			return a.Place < b.Place
+		}
+		return a.Done*max(b.Total, 1) > b.Done*max(a.Total, 1)
+	})
+
+	return ranked
+} // }}}
@@ -0,0 +1,196 @@
+package race
+
+import (
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testTimeout es el tiempo máximo para esperar un mensaje del anfitrión.
+const testTimeout = 5 * time.Second
+
+// testServer inicia un anfitrión en un puerto libre de la interfaz local.
+func testServer(t *testing.T) *Server { // {{{
+	t.Helper()
+	srv, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	go srv.Serve()
+
+	return srv
+} // }}}
+
+// join conecta un participante y espera a que el anfitrión lo registre, así los nombres se asignan en el
+// orden de las llamadas.
+func join(t *testing.T, srv *Server, name string) (*Client, string) { // {{{
+	t.Helper()
+	c, err := Join(srv.Addr().String(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	select {
+	case joined := <-srv.Joined:
+		return c, joined
+	case <-time.After(testTimeout):
+		t.Fatalf("el anfitrión no registró a %q", name)
+	}
+
+	return nil, ""
+} // }}}
+
+// waitFor devuelve el primer mensaje de tipo `typ` que recibe el participante.
+func waitFor(t *testing.T, c *Client, typ string) Message { // {{{
+	t.Helper()
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case msg, ok := <-c.Messages:
+			if !ok {
+				t.Fatalf("se cerró la conexión esperando un mensaje %q", typ)
+			}
+			if msg.Type == typ {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("no se recibió un mensaje %q", typ)
+		}
+	}
+} // }}}
+
+func TestRace(t *testing.T) { // {{{
+	srv := testServer(t)
+	words := []string{"hola", "casa"}
+	names := []string{"ana", "ana", "luis"}
+	want := []string{"ana", "ana (2)", "luis"}
+
+	var clients []*Client
+	for i, name := range names {
+		c, joined := join(t, srv, name)
+		if joined != want[i] {
+			t.Errorf("el participante %d se unió como %q, se esperaba %q", i+1, joined, want[i])
+		}
+		clients = append(clients, c)
+	}
+	srv.Start(words, "codigo")
+
+	for i, c := range clients {
+		start, err := c.WaitStart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start.Name != want[i] {
+			t.Errorf("el participante %d recibió el nombre %q, se esperaba %q", i+1, start.Name, want[i])
+		}
+		if !slices.Equal(start.Words, words) || start.Code != "codigo" {
+			t.Errorf("el participante %d recibió %v con el código %q", i+1, start.Words, start.Code)
+		}
+		go c.Listen()
+	}
+
+	// El avance de uno llega a todos con el nombre asignado por el anfitrión
+	if err := clients[1].Send(Player{Name: "ana", Done: 1, Total: 2}); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range clients {
+		state := waitFor(t, c, State)
+		if len(state.Players) != len(want) || state.Players[1].Name != want[1] || state.Players[1].Done != 1 {
+			t.Errorf("el participante %d recibió el estado %+v", i+1, state.Players)
+		}
+	}
+
+	// Llegan en orden inverso al de unión, cada uno espera a que el anfitrión registre su llegada para que
+	// el orden no dependa de las conexiones
+	for _, i := range []int{2, 1, 0} {
+		if err := clients[i].Send(Player{Done: 2, Total: 2, Finished: true, WPM: float64(10 * (i + 1))}); err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 {
+			for !waitFor(t, clients[i], State).Players[i].Finished {
+			}
+		}
+	}
+	for i, c := range clients {
+		ranking := waitFor(t, c, Ranking)
+		var got []string
+		for _, p := range ranking.Players {
+			got = append(got, p.Name)
+		}
+		if !slices.Equal(got, []string{"luis", "ana (2)", "ana"}) {
+			t.Errorf("el participante %d recibió la clasificación %v", i+1, got)
+		}
+	}
+} // }}}
+
+func TestRaceStalled(t *testing.T) { // {{{
+	defer func(d time.Duration) { writeTimeout = d }(writeTimeout)
+	writeTimeout = time.Second
+	srv := testServer(t)
+	c, _ := join(t, srv, "ana")
+
+	// Un participante que se une y nunca lee, el texto no cabe en los buffers de la conexión
+	stalled, err := net.Dial("tcp", srv.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalled.Close()
+	if err := newConn(stalled).Send(Message{Type: Hello, Name: "luis"}); err != nil {
+		t.Fatal(err)
+	}
+	<-srv.Joined
+	words := make([]string, 1<<19)
+	for i := range words {
+		words[i] = "palabra"
+	}
+	srv.Start(words, "")
+	if _, ok := <-srv.Joined; ok {
+		t.Error("Start no cerró Joined")
+	}
+
+	if _, err := c.WaitStart(); err != nil {
+		t.Fatal(err)
+	}
+	go c.Listen()
+	if err := c.Send(Player{Done: 1, Total: 1, Finished: true}); err != nil {
+		t.Fatal(err)
+	}
+	// Al desconectar al que no lee la carrera termina con la llegada del otro
+	ranking := waitFor(t, c, Ranking)
+	if len(ranking.Players) != 2 || ranking.Players[0].Name != "ana" || ranking.Players[1].Finished {
+		t.Errorf("la clasificación es %+v", ranking.Players)
+	}
+} // }}}
+
+func TestRaceStarted(t *testing.T) { // {{{
+	srv := testServer(t)
+	join(t, srv, "ana")
+	srv.Start([]string{"hola"}, "")
+
+	late, err := Join(srv.Addr().String(), "luis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer late.Close()
+	if _, err := late.WaitStart(); err == nil {
+		t.Error("un participante se unió después de comenzar la carrera")
+	}
+} // }}}
+
+func TestRank(t *testing.T) { // {{{
+	players := []Player{
+		{Name: "a", Done: 1, Total: 4},
+		{Name: "b", Done: 4, Total: 4, Finished: true, Place: 2},
+		{Name: "c", Done: 3, Total: 4},
+		{Name: "d", Done: 4, Total: 4, Finished: true, Place: 1},
+	}
+	var got []string
+	for _, p := range Rank(players) {
+		got = append(got, p.Name)
+	}
+	if !slices.Equal(got, []string{"d", "b", "c", "a"}) {
+		t.Errorf("Rank = %v, se esperaba [d b c a]", got)
+	}
+} // }}}
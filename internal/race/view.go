@@ -0,0 +1,177 @@
+package race
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/theme"
+	"github.com/wrodriguez/thot/internal/ui"
+)
+
+var (
+	nameStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("151")).Width(16)
+	meStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true).Width(16)
+	titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("38")).Bold(true)
+	placeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("194")).Padding(0, 1)
+	waitStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
+)
+
+func init() { // {{{
+	theme.Register("race.name", &nameStyle)
+	theme.Register("race.me", &meStyle)
+	theme.Register("race.title", &titleStyle)
+	theme.Register("race.place", &placeStyle)
+	theme.Register("race.wait", &waitStyle)
+} // }}}
+
+// netMsg es un mensaje del anfitrión, `closed` indica que se perdió la conexión.
+type netMsg struct {
+	msg    Message
+	closed bool
+}
+
+// Model es la vista de la carrera: el texto a escribir y debajo el avance de todos los participantes.
+type Model struct {
+	typer   *ui.Model
+	client  *Client
+	name    string
+	players []Player
+	ranking []Player
+	bar     progress.Model
+	lost    bool
+}
+
+// NewModel crea la vista de la carrera para el participante `name`, `Run` recibe los mensajes de `client`.
+func NewModel(client *Client, name string, words []string) *Model { // {{{
+	return &Model{
+		typer:  ui.NewModel(words),
+		client: client,
+		name:   name,
+		bar:    progress.New(progress.WithDefaultGradient(), progress.WithWidth(30)),
+	}
+} // }}}
+
+// Ranking devuelve la clasificación final, vacía si la carrera no terminó.
+func (m *Model) Ranking() []Player { // {{{
+	return m.ranking
+} // }}}
+
+func (m *Model) Init() tea.Cmd { // {{{
+	m.typer.Start()
+	go m.client.Listen()
+
+	return tea.Batch(m.typer.Init(), m.wait())
+} // }}}
+
+// wait espera el siguiente mensaje del anfitrión.
+func (m *Model) wait() tea.Cmd { // {{{
+	return func() tea.Msg {
+		msg, ok := <-m.client.Messages
+		return netMsg{msg: msg, closed: !ok}
+	}
+} // }}}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) { // {{{
+	switch msg := msg.(type) {
+	case netMsg:
+		if msg.closed {
+			m.lost = true
+			return m, tea.Quit
+		}
+		switch msg.msg.Type {
+		case State:
+			m.players = msg.msg.Players
+		case Ranking:
+			m.ranking = msg.msg.Players
+			return m, tea.Quit
+		}
+		return m, m.wait()
+	case tea.KeyMsg:
+		if k := msg.String(); k == "ctrl+c" || k == "esc" {
+			return m, tea.Quit
+		}
+		if m.typer.Finished() {
+			return m, nil
+		}
+		_, cmd := m.typer.Update(msg)
+		m.send()
+		if m.typer.Finished() {
+			// La vista de entrenamiento termina al escribir la última palabra, la carrera sigue hasta que
+			// terminen todos
+			return m, nil
+		}
+		return m, cmd
+	}
+	_, cmd := m.typer.Update(msg)
+
+	return m, cmd
+} // }}}
+
+// send envía el avance al anfitrión, al terminar incluye las estadísticas.
+func (m *Model) send() { // {{{
+	done, total := m.typer.Progress()
+	p := Player{Name: m.name, Done: done, Total: total, Finished: m.typer.Finished()}
+	if p.Finished {
+		stats := m.typer.Stats()
+		p.WPM = stats.WPM(stats.Chars(), stats.Mistakes(), stats.Seconds()/60)
+		p.Accuracy = stats.Accuracy(stats.Chars(), stats.Mistakes())
+	}
+	_ = m.client.Send(p)
+} // }}}
+
+func (m *Model) View() string { // {{{
+	if m.ranking != nil {
+		return RankingView(m.ranking, m.name)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(m.typer.View() + "\n\n")
+	for _, p := range m.players {
+		style := nameStyle
+		if p.Name == m.name {
+			style = meStyle
+		}
+		percent := 0.0
+		if p.Total > 0 {
+			percent = float64(p.Done) / float64(p.Total)
+		}
+		sb.WriteString(style.Render(p.Name) + " " + m.bar.ViewAs(percent))
+		if p.Finished {
+			sb.WriteString(placeStyle.Render(fmt.Sprintf("#%d", p.Place)))
+		}
+		sb.WriteString("\n")
+	}
+	if m.typer.Finished() {
+		sb.WriteString("\n" + waitStyle.Render(i18n.T("Esperando a que terminen los demás participantes...")) + "\n")
+	}
+	if m.lost {
+		sb.WriteString("\n" + waitStyle.Render(i18n.T("Se perdió la conexión con la carrera")) + "\n")
+	}
+
+	return sb.String()
+} // }}}
+
+// RankingView muestra la clasificación final con la velocidad y precisión de cada participante.
+func RankingView(ranking []Player, me string) string { // {{{
+	sb := strings.Builder{}
+	sb.WriteString(titleStyle.Render(i18n.T("󰔸  Clasificación")) + "\n\n")
+	for i, p := range ranking {
+		style := nameStyle
+		if p.Name == me {
+			style = meStyle
+		}
+		line := placeStyle.Render(fmt.Sprintf("%d.", i+1)) + style.Render(p.Name)
+		if p.Finished {
+			line += fmt.Sprintf(" %6.2f WPM  %6.2f%%", p.WPM, p.Accuracy)
+		} else {
+			line += waitStyle.Render(i18n.T(" no terminó (%d/%d)", p.Done, p.Total))
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	return sb.String()
+} // }}}
@@ -0,0 +1,168 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/paths"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Version es la versión del formato de los registros de pulsaciones.
+const Version = 1
+
+// sessionsDir es el directorio del perfil (archivos de estado) con un registro por sesión.
+const sessionsDir = "sessions"
+
+// Teclas especiales que se guardan con su nombre, las demás pulsaciones son el carácter escrito.
+const (
+	Enter     = "enter"
+	Backspace = "backspace"
+)
+
+// Event es una pulsación de tecla durante una sesión.
+type Event struct {
+	// At es el tiempo transcurrido desde el inicio de la sesión
+	At time.Duration
+	// Key es la tecla pulsada, un carácter o `enter` y `backspace`
+	Key string
+	// Expected es el carácter que se esperaba en la posición del cursor, vacío al final de la palabra
+	Expected string
+}
+
+// Correction indica si la pulsación borra el carácter anterior.
+func (e Event) Correction() bool { // {{{
+	return e.Key == Backspace
+} // }}}
+
+// Log es el registro completo de una sesión, contiene el texto y las pulsaciones para poder repetirla.
+type Log struct {
+	Version int           `json:"version"`
+	ID      string        `json:"id"`
+	Lines   []string      `json:"lines"`
+	Limit   time.Duration `json:"limit,omitempty"`
+	Events  []Event       `json:"-"`
+}
+
+// Path devuelve la ruta del registro de la sesión `id`. El identificador debe tener el formato
+// `history.IDFormat`, cualquier otro valor (p.e. con `..` o separadores) podría salir del directorio.
+func Path(id string) (string, errors.E) { // {{{
+	if _, e := time.Parse(history.IDFormat, id); e != nil {
+		return "", errors.WithDetails(
+			errors.WithMessage(e, i18n.T("El identificador de sesión %q no es valido", id)),
+			"id",
+			id,
+		)
+	}
+	base, err := paths.Profile(paths.State)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, sessionsDir, id+".log"), nil
+} // }}}
+
+// Save guarda el registro de una sesión. La primera línea es la cabecera en JSON y cada pulsación ocupa
+// una línea con el formato `<milisegundos>\t<tecla>\t<esperado>\t<c si es corrección o ->`, la tecla y
+// el carácter esperado van entre comillas como cadenas de Go.
+func Save(log Log) errors.E { // {{{
+	path, err := Path(log.ID)
+	if err != nil {
+		return err
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+
+	log.Version = Version
+	header, e := json.Marshal(log)
+	if e != nil {
+		return errors.WithStack(e)
+	}
+
+	sb := strings.Builder{}
+	sb.Write(header)
+	sb.WriteString("\n")
+	for _, ev := range log.Events {
+		flag := "-"
+		if ev.Correction() {
+			flag = "c"
+		}
+		fmt.Fprintf(&sb, "%d\t%s\t%s\t%s\n", ev.At.Milliseconds(), strconv.Quote(ev.Key), strconv.Quote(ev.Expected), flag)
+	}
+
+	if e := os.WriteFile(path, []byte(sb.String()), 0644); e != nil {
+		return errors.WithDetails(errors.WithMessage(e, i18n.T("No se pudo guardar el registro de la sesión")), "path", path)
+	}
+
+	return nil
+} // }}}
+
+// Load lee el registro de la sesión `id`.
+func Load(id string) (Log, errors.E) { // {{{
+	var log Log
+	path, err := Path(id)
+	if err != nil {
+		return log, err
+	}
+	f, e := os.Open(path)
+	if os.IsNotExist(e) {
+		return log, errors.WithDetails(errors.New(i18n.T("La sesión %q no tiene un registro de pulsaciones", id)), "path", path)
+	} else if e != nil {
+		return log, errors.WithDetails(errors.WithMessage(e, i18n.T("No se pudo leer el registro de la sesión")), "path", path)
+	}
+	defer f.Close()
+
+	invalid := func(line int, e error) errors.E {
+		return errors.WithDetails(
+			errors.WithMessage(e, i18n.T("El registro de la sesión no es valido")),
+			"path",
+			path,
+			"line",
+			line,
+		)
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return log, invalid(1, errors.New(i18n.T("El registro está vacío")))
+	}
+	if e := json.Unmarshal(scanner.Bytes(), &log); e != nil {
+		return log, invalid(1, e)
+	}
+	if log.Version != Version {
+		return log, invalid(1, errors.New(i18n.T("Versión %d no soportada", log.Version)))
+	}
+
+	for n := 2; scanner.Scan(); n++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 4 {
+			return log, invalid(n, errors.New(i18n.T("Se esperaban 4 campos")))
+		}
+		ms, e := strconv.ParseInt(fields[0], 10, 64)
+		if e != nil {
+			return log, invalid(n, e)
+		}
+		key, e := strconv.Unquote(fields[1])
+		if e != nil {
+			return log, invalid(n, e)
+		}
+		expected, e := strconv.Unquote(fields[2])
+		if e != nil {
+			return log, invalid(n, e)
+		}
+		log.Events = append(log.Events, Event{At: time.Duration(ms) * time.Millisecond, Key: key, Expected: expected})
+	}
+	if e := scanner.Err(); e != nil {
+		return log, errors.WithDetails(errors.WithMessage(e, i18n.T("No se pudo leer el registro de la sesión")), "path", path)
+	}
+
+	return log, nil
+} // }}}
@@ -0,0 +1,58 @@
+package replay
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// KeyStat acumula las pulsaciones de un carácter esperado.
+type KeyStat struct {
+	// Count es la cantidad de veces que se esperaba el carácter y Errors las veces que se pulsó otra tecla
+	Count  int
+	Errors int
+	// Time es la suma del tiempo transcurrido desde la pulsación anterior, mide las vacilaciones
+	Time time.Duration
+}
+
+// ErrorRate devuelve la proporción de errores entre 0 y 1.
+func (k KeyStat) ErrorRate() float64 { // {{{
+	if k.Count == 0 {
+		return 0
+	}
+
+	return float64(k.Errors) / float64(k.Count)
+} // }}}
+
+// Average devuelve el tiempo medio antes de pulsar el carácter.
+func (k KeyStat) Average() time.Duration { // {{{
+	if k.Count == 0 {
+		return 0
+	}
+
+	return k.Time / time.Duration(k.Count)
+} // }}}
+
+// KeyStats resume las pulsaciones de los registros por carácter esperado, solo se cuentan los caracteres
+// escritos, no las correcciones ni el `enter` al final de cada palabra.
+func KeyStats(logs ...Log) map[string]KeyStat { // {{{
+	stats := make(map[string]KeyStat)
+	for _, log := range logs {
+		var prev time.Duration
+		for _, ev := range log.Events {
+			wait := ev.At - prev
+			prev = ev.At
+			if ev.Expected == "" || ev.Correction() || utf8.RuneCountInString(ev.Key) != 1 {
+				continue
+			}
+			s := stats[ev.Expected]
+			s.Count++
+			if ev.Key != ev.Expected {
+				s.Errors++
+			}
+			s.Time += wait
+			stats[ev.Expected] = s
+		}
+	}
+
+	return stats
+} // }}}
@@ -0,0 +1,169 @@
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Style describe los atributos de un estilo que se pueden sobrescribir desde el archivo de configuración.
+// Los colores aceptan cualquier valor válido para `lipgloss.Color`, por ejemplo `"160"` o `"#ff8700"`.
+type Style struct {
+	Foreground string `toml:"foreground,omitempty"`
+	Background string `toml:"background,omitempty"`
+	Border     string `toml:"border,omitempty"`
+	Bold       *bool  `toml:"bold,omitempty"`
+	Italic     *bool  `toml:"italic,omitempty"`
+}
+
+// Theme relaciona el nombre de un estilo registrado (p.e. `ui.cursor`) con sus atributos.
+type Theme map[string]Style
+
+type entry struct {
+	target *lipgloss.Style
+	def    lipgloss.Style
+}
+
+var registry = make(map[string]entry)
+var hooks []func()
+
+// Register agrega un estilo al registro de estilos que pueden ser modificados por un tema.
+// Se debe llamar desde la función `init` del paquete dueño del estilo.
+func Register(name string, s *lipgloss.Style) { // {{{
+	registry[name] = entry{target: s, def: *s}
+} // }}}
+
+// OnApply registra una función que se ejecuta después de aplicar un tema, útil para los paquetes que
+// derivan datos (p.e. secuencias de escape) a partir de los estilos registrados.
+func OnApply(fn func()) { // {{{
+	hooks = append(hooks, fn)
+} // }}}
+
+// Names devuelve la lista ordenada de los nombres de estilos registrados.
+func Names() []string { // {{{
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+} // }}}
+
+// Exists indica si existe un estilo registrado con el nombre `name`.
+func Exists(name string) bool { // {{{
+	_, ok := registry[name]
+	return ok
+} // }}}
+
+// Apply restablece todos los estilos registrados a sus valores por defecto y sobrescribe los atributos
+// definidos en el tema.
+func Apply(t Theme) errors.E { // {{{
+	for name, e := range registry {
+		*e.target = e.def
+		if s, ok := t[name]; ok {
+			*e.target = s.apply(e.def)
+		}
+	}
+	for _, fn := range hooks {
+		fn()
+	}
+
+	var unknown []string
+	for name := range t {
+		if !Exists(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.WithDetails(
+			errors.New(i18n.T("El tema contiene estilos desconocidos")),
+			"estilos",
+			strings.Join(unknown, ", "),
+		)
+	}
+
+	return nil
+} // }}}
+
+// Color convierte un color de lipgloss (ANSI 256 o hexadecimal) en la secuencia de escape para el color de
+// primer plano, se usa en las plantillas que no se renderizan con lipgloss.
+func Color(c lipgloss.TerminalColor) string { // {{{
+	color, ok := c.(lipgloss.Color)
+	if !ok || color == "" {
+		return ""
+	}
+	s := string(color)
+	if strings.HasPrefix(s, "#") && len(s) == 7 {
+		if rgb, err := strconv.ParseUint(s[1:], 16, 32); err == nil {
+			return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", rgb>>16, (rgb>>8)&0xff, rgb&0xff)
+		}
+	}
+
+	return "\x1b[38;5;" + s + "m"
+} // }}}
+
+func (s Style) apply(st lipgloss.Style) lipgloss.Style { // {{{
+	if s.Foreground != "" {
+		st = st.Foreground(lipgloss.Color(s.Foreground))
+	}
+	if s.Background != "" {
+		st = st.Background(lipgloss.Color(s.Background))
+	}
+	if s.Border != "" {
+		st = st.BorderForeground(lipgloss.Color(s.Border))
+	}
+	if s.Bold != nil {
+		st = st.Bold(*s.Bold)
+	}
+	if s.Italic != nil {
+		st = st.Italic(*s.Italic)
+	}
+
+	return st
+} // }}}
+
+// ansiBase son los 16 colores básicos de la paleta de xterm, el resto de la paleta de 256 colores se calcula.
+var ansiBase = [16]string{
+	"#000000", "#800000", "#008000", "#808000", "#000080", "#800080", "#008080", "#c0c0c0",
+	"#808080", "#ff0000", "#00ff00", "#ffff00", "#0000ff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// Hex convierte un color de lipgloss (ANSI 256 o hexadecimal) en el color hexadecimal `#rrggbb` de la paleta
+// de xterm, se usa en los diagramas que no se muestran en una terminal como SVG o HTML.
+func Hex(c lipgloss.TerminalColor) string { // {{{
+	color, ok := c.(lipgloss.Color)
+	if !ok || color == "" {
+		return ""
+	}
+	s := string(color)
+	if strings.HasPrefix(s, "#") {
+		return s
+	}
+	n, err := strconv.Atoi(s)
+	switch {
+	case err != nil || n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return ansiBase[n]
+	case n >= 232:
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+	// Cubo de 6x6x6 colores
+	n -= 16
+	level := func(i int) int {
+		if i == 0 {
+			return 0
+		}
+		return 55 + i*40
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", level(n/36), level((n/6)%6), level(n%6))
+} // }}}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wrodriguez/thot/internal/replay"
+)
+
+// ghostInterval es cada cuánto se actualiza la posición del fantasma en la pantalla.
+const ghostInterval = 100 * time.Millisecond
+
+type ghostMsg time.Time
+
+// Ghost es un cursor fantasma que avanza por el texto para competir contra él.
+type Ghost interface {
+	// Position devuelve la palabra y el carácter donde está el fantasma en el momento `elapsed` de la
+	// sesión, cuando terminó el texto la palabra es igual a la cantidad de palabras.
+	Position(elapsed time.Duration) (line, col int)
+}
+
+// paceGhost avanza a una velocidad constante, cada palabra cuenta sus caracteres más el `enter` final.
+type paceGhost struct {
+	lines []string
+	// cps son los caracteres por segundo
+	cps float64
+}
+
+// PaceGhost crea un fantasma que escribe `lines` a `wpm` palabras por minuto (5 caracteres por palabra,
+// igual que `Stats.WPM`).
+func PaceGhost(lines []string, wpm float64) Ghost { // {{{
+	return paceGhost{lines: lines, cps: wpm * 5 / 60}
+} // }}}
+
+func (g paceGhost) Position(elapsed time.Duration) (int, int) { // {{{
+	pos := int(elapsed.Seconds() * g.cps)
+	for i, line := range g.lines {
+		n := utf8.RuneCountInString(line)
+		if pos <= n {
+			return i, pos
+		}
+		pos -= n + 1
+	}
+
+	return len(g.lines), 0
+} // }}}
+
+// step es la posición del cursor después de una pulsación.
+type step struct {
+	at   time.Duration
+	line int
+	col  int
+}
+
+// runGhost repite la posición del cursor de una sesión grabada.
+type runGhost struct {
+	steps []step
+}
+
+// RunGhost crea un fantasma que sigue el cursor de la sesión grabada en `log`, las pulsaciones se aplican
+// con las mismas reglas que `Model.press`.
+func RunGhost(log replay.Log) Ghost { // {{{
+	g := runGhost{}
+	line, col := 0, 0
+	for _, ev := range log.Events {
+		if line >= len(log.Lines) {
+			break
+		}
+		n := utf8.RuneCountInString(log.Lines[line])
+		switch ev.Key {
+		case replay.Enter:
+			if col >= n {
+				line, col = line+1, 0
+			}
+		case replay.Backspace:
+			if col > 0 && col < n {
+				col--
+			}
+		default:
+			if utf8.RuneCountInString(ev.Key) == 1 && col < n {
+				col++
+			}
+		}
+		g.steps = append(g.steps, step{at: ev.At, line: line, col: col})
+	}
+
+	return g
+} // }}}
+
+func (g runGhost) Position(elapsed time.Duration) (int, int) { // {{{
+	line, col := 0, 0
+	for _, s := range g.steps {
+		if s.at > elapsed {
+			break
+		}
+		line, col = s.line, s.col
+	}
+
+	return line, col
+} // }}}
+
+// Ghost agrega un cursor fantasma a la sesión.
+func (m *Model) Ghost(g Ghost) { // {{{
+	m.ghost = g
+} // }}}
+
+func ghostTick() tea.Cmd { // {{{
+	return tea.Tick(ghostInterval, func(t time.Time) tea.Msg {
+		return ghostMsg(t)
+	})
+} // }}}
+
+// ghostAhead indica si el fantasma va por delante de la posición actual del usuario, si ambos terminaron
+// el texto gana el fantasma solo si terminó antes.
+func (m *Model) ghostAhead() bool { // {{{
+	line, col := m.ghost.Position(m.elapsed())
+	if line >= len(m.lines) {
+		return true
+	}
+
+	return line > m.line || (line == m.line && col > m.cursor)
+} // }}}
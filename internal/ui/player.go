@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wrodriguez/thot/internal/replay"
+)
+
+// replayMsg indica que es el momento de reproducir la siguiente pulsación.
+type replayMsg struct{}
+
+// player reproduce las pulsaciones de un registro en el mismo orden y con los mismos intervalos.
+type player struct {
+	events []replay.Event
+	pos    int
+	speed  float64
+}
+
+// NewReplay crea una vista que repite la sesión `log`, `speed` acelera (> 1) o frena (< 1) la repetición.
+// Las estadísticas finales se calculan con el tiempo de la sesión original.
+func NewReplay(log replay.Log, speed float64) *Model { // {{{
+	m := NewModel(log.Lines)
+	m.Limit(log.Limit)
+	m.speed = speed
+	m.player = &player{events: log.Events, speed: speed}
+
+	return m
+} // }}}
+
+// next programa la siguiente pulsación según el tiempo transcurrido de la sesión.
+func (p *player) next(elapsed time.Duration) tea.Cmd { // {{{
+	if p.pos >= len(p.events) {
+		return nil
+	}
+	wait := time.Duration(float64(p.events[p.pos].At-elapsed) / p.speed)
+
+	return tea.Tick(max(wait, 0), func(time.Time) tea.Msg {
+		return replayMsg{}
+	})
+} // }}}
+
+// replayNext reproduce la pulsación actual y programa la siguiente, al terminar el registro la sesión se
+// detiene aunque no se hayan escrito todas las palabras (p.e. en el modo `time`).
+func (m *Model) replayNext() tea.Cmd { // {{{
+	if m.end || m.player.pos >= len(m.player.events) {
+		return nil
+	}
+	ev := m.player.events[m.player.pos]
+	m.player.pos++
+	if cmd := m.press(ev.Key); cmd != nil {
+		return cmd
+	}
+	if m.player.pos >= len(m.player.events) {
+		m.Stop()
+		return tea.Quit
+	}
+
+	return m.player.next(m.elapsed())
+} // }}}
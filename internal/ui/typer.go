@@ -10,6 +10,9 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/replay"
+	"github.com/wrodriguez/thot/internal/theme"
 	"github.com/wrodriguez/thot/internal/util"
 )
 
@@ -39,11 +42,18 @@ var (
 	mistakeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("197"))
 	wpmStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("73"))
 	precStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
-	boxStyle     = lipgloss.NewStyle().Padding(1).
+	noteStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
+	ghostStyle   = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("97"))
+	hintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("180")).Italic(true)
+	boxStyle  = lipgloss.NewStyle().Padding(1).
 			Border(lipgloss.RoundedBorder(), true).
 			BorderForeground(lipgloss.Color("241"))
 )
 
+type tickMsg time.Time
+
 type KeyMap struct {
 	Salir key.Binding
 }
@@ -58,6 +68,7 @@ type Character struct {
 	char   string
 	style  lipgloss.Style
 	active bool
+	ghost  bool
 	status Status
 }
 
@@ -78,14 +89,48 @@ type Model struct {
 	end     bool
 	stats   Stats
 	first   bool
+	limit   time.Duration
+	keys    KeyMap
+	onStop  func(Stats) []string
+	notes   []string
+	// events guarda cada pulsación de la sesión para poder repetirla
+	events []replay.Event
+	// speed multiplica el paso del tiempo, solo es distinto de 1 al repetir una sesión
+	speed  float64
+	player *player
+	ghost  Ghost
+	// hint devuelve las teclas que escriben un carácter, `hintText` es la ayuda del último error
+	hint     func(rune) (string, bool)
+	hintText string
 }
 
-var keyMap = KeyMap{
-	Salir: key.NewBinding(
-		key.WithKeys("esc"),
-		key.WithHelp("esc", "Salir"),
-	),
-}
+// DefaultKeyMap devuelve las combinaciones de teclas de la vista de entrenamiento en el idioma actual.
+func DefaultKeyMap() KeyMap { // {{{
+	return KeyMap{
+		Salir: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", i18n.T("Salir")),
+		),
+	}
+} // }}}
+
+func init() { // {{{
+	theme.Register("ui.cursor", &cursorStyle)
+	theme.Register("ui.ok", &okStyle)
+	theme.Register("ui.err", &errStyle)
+	theme.Register("ui.default", &defaultStyle)
+	theme.Register("ui.item", &itemStyle)
+	theme.Register("ui.info", &infoStyle)
+	theme.Register("ui.word", &wordStyle)
+	theme.Register("ui.time", &timeStyle)
+	theme.Register("ui.mistake", &mistakeStyle)
+	theme.Register("ui.wpm", &wpmStyle)
+	theme.Register("ui.prec", &precStyle)
+	theme.Register("ui.box", &boxStyle)
+	theme.Register("ui.note", &noteStyle)
+	theme.Register("ui.ghost", &ghostStyle)
+	theme.Register("ui.hint", &hintStyle)
+} // }}}
 
 func NewCharacter(char string) Character { // {{{
 	return Character{
@@ -119,6 +164,8 @@ func NewModel(lines []string) *Model { // {{{
 		cerr:    0,
 		end:     false,
 		first:   true,
+		keys:    DefaultKeyMap(),
+		speed:   1,
 	}
 
 	return m
@@ -128,26 +175,49 @@ func (s Stats) String() string { // {{{
 	sb := strings.Builder{}
 
 	sb.WriteString(boxStyle.Render(
-		wordStyle.Render(fmt.Sprintf(" 󰀬 Total caracteres: %d", s.tchar)) + "\n " +
-			timeStyle.Render(fmt.Sprintf(" Tiempo: %.2fs(%.2fm)", s.tempo, s.tempo/60)) + "\n " +
-			mistakeStyle.Render(fmt.Sprintf("󰚌 errores: %d", s.cerr)) + "\n " +
+		wordStyle.Render(i18n.T(" 󰀬 Total caracteres: %d", s.tchar)) + "\n " +
+			timeStyle.Render(i18n.T(" Tiempo: %.2fs(%.2fm)", s.tempo, s.tempo/60)) + "\n " +
+			mistakeStyle.Render(i18n.T("󰚌 errores: %d", s.cerr)) + "\n " +
 			wpmStyle.Render(
-				fmt.Sprintf("󰌓 WPM: %.2f", s.WPM(s.tchar, s.cerr, s.tempo/60)),
+				i18n.T("󰌓 WPM: %.2f", s.WPM(s.tchar, s.cerr, s.tempo/60)),
 			) + "\n " +
-			precStyle.Render(fmt.Sprintf("󰓾 Precisión: %.2f%%", s.Accuracy(s.tchar, s.cerr))),
+			precStyle.Render(i18n.T("󰓾 Precisión: %.2f%%", s.Accuracy(s.tchar, s.cerr))),
 	))
 
 	return sb.String()
 } // }}}
 
+// Chars devuelve la cantidad de caracteres escritos en la sesión.
+func (s Stats) Chars() int { // {{{
+	return s.tchar
+} // }}}
+
+// Mistakes devuelve la cantidad de errores cometidos en la sesión.
+func (s Stats) Mistakes() int { // {{{
+	return s.cerr
+} // }}}
+
+// Seconds devuelve la duración de la sesión en segundos.
+func (s Stats) Seconds() float64 { // {{{
+	return s.tempo
+} // }}}
+
 // WPM calcula los caracteres por minuto, basada en la ecuación:
 // https://www.speedtypingonline.com/typing-equations
 func (s Stats) WPM(all, uncorrect int, minutes float64) float64 { // {{{
+	if minutes <= 0 {
+		return 0
+	}
+
 	return (float64(all/5) - float64(uncorrect)) / minutes
 } // }}}
 
-// Accuracy calcula el porcentaje de caracteres correctos
+// Accuracy calcula el porcentaje de caracteres correctos, es cero si no se escribió ningún carácter.
 func (s Stats) Accuracy(all, uncorrect int) float64 { // {{{
+	if all == 0 {
+		return 0
+	}
+
 	return (float64(all-uncorrect) / float64(all)) * 100
 } // }}}
 
@@ -172,7 +242,7 @@ func (c Character) Char() string { // {{{
 } // }}}
 
 func (c Character) String() string { // {{{
-	return util.IF(c.active, cursorStyle, c.style).Render(c.char)
+	return util.IF(c.active, cursorStyle, util.IF(c.ghost, ghostStyle, c.style)).Render(c.char)
 } // }}}
 
 func (c *Character) Style(s lipgloss.Style) { // {{{
@@ -198,79 +268,228 @@ func (c *Character) Err() { // {{{
 } // }}}
 
 func (m Model) Init() tea.Cmd { // {{{
-	// Simplemente devuelve `nil`, que significa "nada de E/S ahora mismo, por favor".
-	return nil
+	var cmds []tea.Cmd
+	if m.limit > 0 {
+		cmds = append(cmds, tick())
+	}
+	if m.player != nil {
+		cmds = append(cmds, m.player.next(m.elapsed()))
+	}
+	if m.ghost != nil {
+		cmds = append(cmds, ghostTick())
+	}
+	// Sin comandos devuelve `nil`, que significa "nada de E/S ahora mismo, por favor".
+	return tea.Batch(cmds...)
+} // }}}
+
+func tick() tea.Cmd { // {{{
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+} // }}}
+
+// Limit establece la duración máxima de la sesión (modo `time`), al cumplirse la sesión termina aunque
+// queden palabras por escribir.
+func (m *Model) Limit(d time.Duration) { // {{{
+	m.limit = d
+} // }}}
+
+// OnStop registra una función que se ejecuta al terminar la sesión, las líneas que devuelve se muestran en
+// la pantalla de resultados debajo de las estadísticas.
+func (m *Model) OnStop(fn func(Stats) []string) { // {{{
+	m.onStop = fn
+} // }}}
+
+// Hint registra la función que devuelve la combinación de teclas de un carácter del layout, al fallar un
+// carácter que no se escribe con una sola tecla se muestra cómo escribirlo.
+func (m *Model) Hint(fn func(rune) (string, bool)) { // {{{
+	m.hint = fn
 } // }}}
 
 func (m *Model) Start() { // {{{
 	m.start = time.Now()
 } // }}}
 
+// elapsed devuelve el tiempo de la sesión, al repetir una sesión acelerada avanza más rápido que el reloj.
+func (m *Model) elapsed() time.Duration { // {{{
+	return time.Duration(float64(time.Since(m.start)) * m.speed)
+} // }}}
+
 func (m *Model) Stop() { // {{{
 	m.end = true
-	seg := time.Since(m.start).Seconds()
+	seg := m.elapsed().Seconds()
 	txtlen := utf8.RuneCountInString(strings.Join(m.lines, " "))
+	if m.limit > 0 && m.line < len(m.lines) {
+		// En el modo `time` solo se cuentan los caracteres escritos hasta el momento
+		txtlen = utf8.RuneCountInString(strings.Join(m.lines[:m.line], " ")) + m.cursor
+		m.cerr += countMistakes(m.current[:min(m.cursor, len(m.current))])
+	}
 
 	m.stats = Stats{
 		tchar: txtlen,
 		cerr:  m.cerr,
 		tempo: seg,
 	}
+	if m.onStop != nil {
+		m.notes = m.onStop(m.stats)
+	}
+	if m.ghost != nil {
+		m.notes = append(m.notes, util.IF(m.ghostAhead(), i18n.T("󰊠  El fantasma te ganó"), i18n.T("󰊠  Le ganaste al fantasma")))
+	}
+} // }}}
+
+// Finished indica si la sesión terminó escribiendo todas las palabras o agotando el tiempo, una sesión
+// cancelada con `esc` no se considera terminada.
+func (m *Model) Finished() bool { // {{{
+	return m.end
+} // }}}
+
+// Stats devuelve las estadísticas de la sesión, solo son válidas cuando la sesión terminó.
+func (m *Model) Stats() Stats { // {{{
+	return m.stats
+} // }}}
+
+// StartTime devuelve el momento en que comenzó la sesión.
+func (m *Model) StartTime() time.Time { // {{{
+	return m.start
+} // }}}
+
+// Events devuelve las pulsaciones de la sesión en el orden en que ocurrieron.
+func (m *Model) Events() []replay.Event { // {{{
+	return m.events
+} // }}}
+
+// Progress devuelve los caracteres avanzados y el total del texto, cada palabra cuenta sus caracteres más
+// el `enter` final.
+func (m *Model) Progress() (done, total int) { // {{{
+	for i, line := range m.lines {
+		n := utf8.RuneCountInString(line) + 1
+		total += n
+		if i < m.line {
+			done += n
+		}
+	}
+	if m.line < len(m.lines) {
+		done += m.cursor
+	}
+
+	return done, total
+} // }}}
+
+// Lines devuelve las palabras de la sesión.
+func (m *Model) Lines() []string { // {{{
+	return m.lines
 } // }}}
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) { // {{{
 	switch msg := msg.(type) {
+	case tickMsg:
+		if m.end {
+			return m, nil
+		}
+		if m.elapsed() >= m.limit {
+			m.Stop()
+			return m, tea.Quit
+		}
+		return m, tick()
+	case replayMsg:
+		return m, m.replayNext()
+	case ghostMsg:
+		// Solo se vuelve a programar para que la vista muestre la nueva posición del fantasma
+		if m.end {
+			return m, nil
+		}
+		return m, ghostTick()
 	case tea.WindowSizeMsg:
 		m.wsize.Width = msg.Width
 		m.wsize.Height = msg.Height
 		return m, nil
 	case tea.KeyMsg:
-		lmsg := len([]rune(msg.String()))
 		ms := msg.String()
-		_ = lmsg
-		switch ms {
-		case "ctrl+c", "esc":
+		switch {
+		case ms == "ctrl+c" || ms == "esc":
 			return m, tea.Quit
-		case "enter":
-			if m.cursor >= len(m.current) {
-				m.cerr += countMistakes(m.current)
-				m.line++
-				if m.line < len(m.lines) {
-					m.current = m.ToChars()
-					m.cursor = 0
-				}
-			}
-			if m.line >= len(m.lines) {
-				m.Stop()
-				return m, tea.Quit
+		case m.player != nil:
+			// Al repetir una sesión solo se aceptan las teclas para salir
+			return m, nil
+		}
+		return m, m.press(ms)
+	}
+
+	return m, nil
+} // }}}
+
+// press procesa una pulsación de tecla, devuelve `tea.Quit` si la sesión terminó.
+func (m *Model) press(ms string) tea.Cmd { // {{{
+	if len(m.current) == 0 && m.cursor == 0 && m.line < len(m.lines) {
+		m.current = m.ToChars()
+	}
+	lmsg := len([]rune(ms))
+	if ms == replay.Enter || ms == replay.Backspace || lmsg == 1 {
+		expected := ""
+		if m.cursor < len(m.current) {
+			expected = m.current[m.cursor].Char()
+		}
+		m.events = append(m.events, replay.Event{At: m.elapsed(), Key: ms, Expected: expected})
+	}
+
+	switch ms {
+	case replay.Enter:
+		if m.cursor >= len(m.current) {
+			m.cerr += countMistakes(m.current)
+			m.line++
+			if m.line < len(m.lines) {
+				m.current = m.ToChars()
+				m.cursor = 0
 			}
-		case "backspace":
-			if m.cursor > 0 && m.cursor < len(m.current) {
+		}
+		if m.line >= len(m.lines) {
+			m.Stop()
+			return tea.Quit
+		}
+	case replay.Backspace:
+		if m.cursor > 0 && m.cursor < len(m.current) {
+			m.current[m.cursor].Inactive()
+			m.current[m.cursor].Style(defaultStyle)
+			m.cursor -= 1
+			m.current[m.cursor].Active()
+		}
+	default:
+		if lmsg == 1 {
+			if m.cursor < len(m.current) {
+				if m.current[m.cursor].Char() == ms {
+					m.current[m.cursor].Ok()
+					m.hintText = ""
+				} else {
+					m.current[m.cursor].Err()
+					m.hintText = m.hintFor(m.current[m.cursor].Char())
+				}
+
 				m.current[m.cursor].Inactive()
-				m.current[m.cursor].Style(defaultStyle)
-				m.cursor -= 1
-				m.current[m.cursor].Active()
-			}
-		default:
-			if lmsg == 1 {
+				m.cursor += util.IF(m.cursor < len(m.current), 1, 0)
 				if m.cursor < len(m.current) {
-					if m.current[m.cursor].Char() == ms {
-						m.current[m.cursor].Ok()
-					} else {
-						m.current[m.cursor].Err()
-					}
-
-					m.current[m.cursor].Inactive()
-					m.cursor += util.IF(m.cursor < len(m.current), 1, 0)
-					if m.cursor < len(m.current) {
-						m.current[m.cursor].Active()
-					}
+					m.current[m.cursor].Active()
 				}
 			}
 		}
 	}
 
-	return m, nil
+	return nil
+} // }}}
+
+// hintFor devuelve la ayuda para escribir el carácter `char`, vacía si no hay función de ayuda o si el
+// carácter se escribe con su propia tecla.
+func (m *Model) hintFor(char string) string { // {{{
+	r := []rune(char)
+	if m.hint == nil || len(r) != 1 {
+		return ""
+	}
+	combo, ok := m.hint(r[0])
+	if !ok || combo == char {
+		return ""
+	}
+
+	return i18n.T("Para escribir %s: %s", char, combo)
 } // }}}
 
 func (m *Model) View() string { // {{{
@@ -278,16 +497,42 @@ func (m *Model) View() string { // {{{
 		m.current = m.ToChars()
 	}
 	sb := strings.Builder{}
-	sb.WriteString(infoStyle.Render(" Inicio: "+m.start.Format("03:04:05 PM")) + "\n\n")
+	sb.WriteString(infoStyle.Render(i18n.T(" Inicio: ") + m.start.Format("03:04:05 PM")))
+	if m.player != nil {
+		sb.WriteString(infoStyle.Render(i18n.T("  󰑖 Repetición x%g", m.speed)))
+	}
+	if m.limit > 0 && !m.end {
+		left := max(m.limit-m.elapsed(), 0).Round(time.Second)
+		sb.WriteString(infoStyle.Render(i18n.T("  󱎫 Restante: %s", left)))
+	}
+	sb.WriteString("\n\n")
 	if !m.end {
 		sb.WriteString(itemStyle.Render("  "))
-		for _, char := range m.current {
-			sb.WriteString(char.String())
+		gline, gcol := -1, -1
+		if m.ghost != nil {
+			gline, gcol = m.ghost.Position(m.elapsed())
+		}
+		for i := range m.current {
+			m.current[i].ghost = gline == m.line && gcol == i
+			sb.WriteString(m.current[i].String())
+		}
+		switch {
+		case gline == m.line && gcol >= len(m.current):
+			// El fantasma terminó la palabra y está por pulsar `enter`
+			sb.WriteString(ghostStyle.Render(" "))
+		case m.ghost != nil && gline != m.line:
+			sb.WriteString(ghostStyle.Render(fmt.Sprintf(" 󰊠 %+d ", gline-m.line)))
 		}
 
-		sb.WriteString("\n\n\n" + m.help.View(keyMap))
+		if m.hintText != "" {
+			sb.WriteString("\n\n  " + hintStyle.Render(m.hintText))
+		}
+		sb.WriteString("\n\n\n" + m.help.View(m.keys))
 	} else {
 		sb.WriteString(m.stats.String() + "\n\n")
+		for _, note := range m.notes {
+			sb.WriteString(noteStyle.Render(note) + "\n")
+		}
 	}
 
 	return sb.String()
@@ -2,13 +2,14 @@ package util
 
 import (
 	"fmt"
+	"github.com/wrodriguez/thot/internal/i18n"
 	"os"
 	"strings"
 )
 
 func Pause(show bool) { // {{{
 	if show {
-		fmt.Print("Presione Enter para continuar...")
+		fmt.Print(i18n.T("Presione Enter para continuar..."))
 	}
 	_, _ = os.Stdin.Read(make([]byte, 1))
 } // }}}
@@ -0,0 +1,131 @@
+// Panel de progreso de Thot, usa solo la API local y dibuja las gráficas en SVG sin dependencias externas.
+
+const labels = {
+  es: {
+    layout: 'Layout', wpm: 'WPM', accuracy: 'Precisión', heatmap: 'Mapa de calor por tecla',
+    errors: 'Errores', time: 'Tiempo medio', empty: 'Sin sesiones', all: 'Todos',
+  },
+  en: {
+    layout: 'Layout', wpm: 'WPM', accuracy: 'Accuracy', heatmap: 'Per-key heatmap',
+    errors: 'Errors', time: 'Average time', empty: 'No sessions', all: 'All',
+  },
+};
+let t = labels.es;
+
+const svgNS = 'http://www.w3.org/2000/svg';
+
+function el(name, attrs, text) {
+  const node = document.createElementNS(svgNS, name);
+  for (const [k, v] of Object.entries(attrs)) node.setAttribute(k, v);
+  if (text !== undefined) node.textContent = text;
+  return node;
+}
+
+async function get(path) {
+  const res = await fetch(path);
+  if (!res.ok) throw new Error(await res.text());
+  return res.json();
+}
+
+// lineChart dibuja los valores de `field` de las sesiones en orden cronológico.
+function lineChart(svg, sessions, field, unit) {
+  svg.replaceChildren();
+  const w = 800, h = 240, pad = 40;
+  if (sessions.length === 0) {
+    svg.append(el('text', { x: w / 2, y: h / 2, 'text-anchor': 'middle' }, t.empty));
+    return;
+  }
+  const values = sessions.map((s) => s[field]);
+  const top = Math.max(...values, 1);
+  const x = (i) => pad + (sessions.length === 1 ? (w - 2 * pad) / 2 : (i * (w - 2 * pad)) / (sessions.length - 1));
+  const y = (v) => h - pad - (Math.max(v, 0) / top) * (h - 2 * pad);
+
+  svg.append(el('line', { class: 'axis', x1: pad, y1: h - pad, x2: w - pad, y2: h - pad }));
+  svg.append(el('line', { class: 'axis', x1: pad, y1: pad, x2: pad, y2: h - pad }));
+  svg.append(el('text', { x: 4, y: pad }, top.toFixed(0) + unit));
+  svg.append(el('text', { x: 4, y: h - pad }, '0'));
+  svg.append(el('text', { x: pad, y: h - 10 }, sessions[0].start.slice(0, 10)));
+  svg.append(el('text', { x: w - pad, y: h - 10, 'text-anchor': 'end' }, sessions[sessions.length - 1].start.slice(0, 10)));
+
+  svg.append(el('polyline', { points: values.map((v, i) => `${x(i)},${y(v)}`).join(' ') }));
+  values.forEach((v, i) => {
+    const dot = el('circle', { cx: x(i), cy: y(v), r: 3 });
+    dot.append(el('title', {}, `${sessions[i].id} (${sessions[i].layout}): ${v.toFixed(2)}${unit}`));
+    svg.append(dot);
+  });
+}
+
+// heat devuelve un color de verde (0) a rojo (1).
+function heat(ratio) {
+  const hue = 120 - 120 * Math.min(Math.max(ratio, 0), 1);
+  return `hsl(${hue}, 70%, 55%)`;
+}
+
+// Las teclas del layout se dibujan en su posición del teclado físico, en unidades de tecla.
+function keyboard(svg, layout, stats, metric) {
+  svg.replaceChildren();
+  const unit = 50;
+  const values = Object.values(stats).map((s) => s[metric]);
+  const top = Math.max(...values, metric === 'error_rate' ? 0.01 : 1);
+  svg.setAttribute('viewBox', `0 0 800 ${Math.ceil(layout.height * unit) + 20}`);
+
+  layout.caps
+    .filter((cap) => cap.row)
+    .forEach((cap) => {
+      const key = (layout.keys[cap.row] || [])[cap.col] || '';
+      // Cada tecla tiene el carácter normal y el de mayúsculas, se suman ambos
+      const chars = [...key];
+      const s = chars.reduce(
+        (acc, c) => {
+          const k = stats[c];
+          if (k) {
+            acc.count += k.count;
+            acc.errors += k.errors;
+            acc.time += k.avg_ms * k.count;
+          }
+          return acc;
+        },
+        { count: 0, errors: 0, time: 0 },
+      );
+      const value = s.count === 0 ? null : metric === 'error_rate' ? s.errors / s.count : s.time / s.count;
+      const x = cap.x * unit + 5;
+      const y = cap.y * unit + 10;
+      const rect = el('rect', { x, y, width: cap.w * unit - 4, height: cap.h * unit - 4, fill: value === null ? '#4e4e4e' : heat(value / top) });
+      rect.append(el('title', {}, `${key}: ${s.count} / ${s.errors} ${t.errors.toLowerCase()} / ${(s.count ? s.time / s.count : 0).toFixed(0)} ms`));
+      svg.append(rect);
+      svg.append(el('text', { x: x + (cap.w * unit - 4) / 2, y: y + unit / 2 + 2 }, chars[0] || ''));
+    });
+}
+
+async function render() {
+  const name = document.getElementById('layout').value;
+  const query = name ? `?layout=${encodeURIComponent(name)}` : '';
+  const sessions = await get(`/api/sessions${query}`);
+  lineChart(document.getElementById('wpm'), sessions, 'wpm', '');
+  lineChart(document.getElementById('accuracy'), sessions, 'accuracy', '%');
+
+  const svg = document.getElementById('keyboard');
+  const layoutName = name || (sessions.length ? sessions[sessions.length - 1].layout : 'qwerty');
+  const [layout, stats] = await Promise.all([get(`/api/layouts/${encodeURIComponent(layoutName)}`), get(`/api/keys?layout=${encodeURIComponent(layoutName)}`)]);
+  keyboard(svg, layout, stats, document.getElementById('metric').value);
+}
+
+async function main() {
+  const info = await get('/api/info');
+  t = labels[info.lang] || labels.es;
+  document.documentElement.lang = info.lang;
+  document.querySelectorAll('[data-i18n]').forEach((node) => {
+    node.textContent = t[node.dataset.i18n];
+  });
+  document.getElementById('profile').textContent = info.profile;
+
+  const sessions = await get('/api/sessions');
+  const select = document.getElementById('layout');
+  select.append(new Option(t.all, ''));
+  [...new Set(sessions.map((s) => s.layout))].sort().forEach((name) => select.append(new Option(name, name)));
+  select.addEventListener('change', render);
+  document.getElementById('metric').addEventListener('change', render);
+  await render();
+}
+
+main();
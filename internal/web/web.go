@@ -0,0 +1,142 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/wrodriguez/thot/internal/history"
+	"github.com/wrodriguez/thot/internal/i18n"
+	"github.com/wrodriguez/thot/internal/kbd"
+	"github.com/wrodriguez/thot/internal/paths"
+	"github.com/wrodriguez/thot/internal/replay"
+	"gitlab.com/tozd/go/errors"
+)
+
+// DefaultAddr es la dirección del panel si no se indica otra, solo accesible desde el mismo equipo.
+const DefaultAddr = "127.0.0.1:8080"
+
+//go:embed static
+var static embed.FS
+
+// keyStat es la versión JSON de `replay.KeyStat`.
+type keyStat struct {
+	Count     int     `json:"count"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	AverageMS float64 `json:"avg_ms"`
+}
+
+// Handler devuelve el manejador HTTP del panel: la API JSON en `/api/` y los archivos estáticos incluidos
+// en el binario en la raíz.
+func Handler() http.Handler { // {{{
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/info", info)
+	mux.HandleFunc("GET /api/sessions", sessions)
+	mux.HandleFunc("GET /api/layouts/{name}", layout)
+	mux.HandleFunc("GET /api/keys", keys)
+
+	root, _ := fs.Sub(static, "static")
+	mux.Handle("GET /", http.FileServer(http.FS(root)))
+
+	return mux
+} // }}}
+
+// Serve atiende el panel en `addr` hasta que el proceso termina.
+func Serve(addr string) errors.E { // {{{
+	srv := &http.Server{Addr: addr, Handler: Handler(), ReadHeaderTimeout: 10 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		return errors.WithDetails(errors.WithMessage(err, i18n.T("No se pudo iniciar el panel web")), "addr", addr)
+	}
+
+	return nil
+} // }}}
+
+// info devuelve el idioma de la interfaz y el perfil activo.
+func info(w http.ResponseWriter, r *http.Request) { // {{{
+	writeJSON(w, map[string]string{
+		"lang":    string(i18n.Current()),
+		"profile": paths.CurrentProfile(),
+	})
+} // }}}
+
+// sessions devuelve el historial de sesiones, `?layout=` filtra por layout.
+func sessions(w http.ResponseWriter, r *http.Request) { // {{{
+	list, err := history.Load()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	layoutName := r.URL.Query().Get("layout")
+	out := make([]history.Session, 0, len(list))
+	for _, s := range list {
+		if layoutName == "" || s.Layout == layoutName {
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start)
+	})
+
+	writeJSON(w, out)
+} // }}}
+
+// layout devuelve las teclas de un layout y su posición en el teclado físico para dibujarlo.
+func layout(w http.ResponseWriter, r *http.Request) { // {{{
+	k := kbd.FindLayout(r.PathValue("name"))
+	if k == nil {
+		http.Error(w, i18n.T("Layout %q no encontrado", r.PathValue("name")), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, struct {
+		*kbd.Keyboard
+		Caps   []kbd.Cap `json:"caps"`
+		Height float64   `json:"height"`
+	}{k, k.Caps(), k.Height()})
+} // }}}
+
+// keys devuelve las estadísticas por carácter de las sesiones con registro de pulsaciones, `?layout=`
+// filtra por layout.
+func keys(w http.ResponseWriter, r *http.Request) { // {{{
+	list, err := history.Load()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	layoutName := r.URL.Query().Get("layout")
+	var logs []replay.Log
+	for _, s := range list {
+		if layoutName != "" && s.Layout != layoutName {
+			continue
+		}
+		// Las sesiones anteriores a los registros de pulsaciones no tienen archivo
+		if log, err := replay.Load(s.ID); err == nil {
+			logs = append(logs, log)
+		}
+	}
+
+	out := make(map[string]keyStat)
+	for char, s := range replay.KeyStats(logs...) {
+		out[char] = keyStat{
+			Count:     s.Count,
+			Errors:    s.Errors,
+			ErrorRate: s.ErrorRate(),
+			AverageMS: float64(s.Average()) / float64(time.Millisecond),
+		}
+	}
+
+	writeJSON(w, out)
+} // }}}
+
+func writeJSON(w http.ResponseWriter, v any) { // {{{
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+} // }}}
+
+func writeError(w http.ResponseWriter, err error) { // {{{
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+} // }}}
@@ -0,0 +1,74 @@
+package words
+
+import (
+	"slices"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+// KeySet es el conjunto de caracteres con los que se pueden formar las palabras de una sesión. Al ser un
+// conjunto y no una cadena, cualquier carácter (`]`, `^`, `-`, `\`, comillas, ...) se trata de forma literal.
+type KeySet map[rune]bool
+
+// NewKeySet crea un conjunto con los caracteres de `keys`.
+func NewKeySet(keys ...rune) KeySet { // {{{
+	set := make(KeySet, len(keys))
+	for _, r := range keys {
+		set[r] = true
+	}
+
+	return set
+} // }}}
+
+// Allows indica si `word` no está vacía y se puede escribir solo con los caracteres del conjunto.
+func (k KeySet) Allows(word string) bool { // {{{
+	for _, r := range word {
+		if !k[r] {
+			return false
+		}
+	}
+
+	return word != ""
+} // }}}
+
+// Mask devuelve la máscara de los caracteres del conjunto (ver `Mask`), incluye el bit `Other` si alguno
+// de los caracteres no pertenece a `Alphabet`.
+func (k KeySet) Mask() uint64 { // {{{
+	var mask uint64
+	for r := range k {
+		mask |= Mask(string(r))
+	}
+
+	return mask
+} // }}}
+
+// Rejected devuelve la máscara de los caracteres que no pertenecen al conjunto. Una palabra puede ser
+// válida solo si `Mask(palabra) & Rejected() == 0`, la comprobación final se hace con `Allows` ya que la
+// máscara no distingue mayúsculas ni los caracteres fuera de `Alphabet`.
+func (k KeySet) Rejected() uint64 { // {{{
+	return ^k.Mask()
+} // }}}
+
+// String devuelve los caracteres del conjunto ordenados.
+func (k KeySet) String() string { // {{{
+	runes := make([]rune, 0, len(k))
+	for r := range k {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+
+	return string(runes)
+} // }}}
+
+// NoWords es el error que devuelve un origen cuando ninguna palabra se puede escribir con las teclas
+// seleccionadas.
+func NoWords(lang string, keys KeySet) errors.E { // {{{
+	return errors.WithDetails(
+		errors.New(i18n.T("Ninguna palabra se puede escribir solo con las teclas seleccionadas")),
+		"lang",
+		lang,
+		"keys",
+		keys.String(),
+	)
+} // }}}
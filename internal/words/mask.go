@@ -0,0 +1,33 @@
+package words
+
+import "strings"
+
+// Alphabet contiene las letras que tienen un bit propio en la máscara de una palabra, las mayúsculas se
+// convierten a minúsculas antes de calcular la máscara.
+const Alphabet = "abcdefghijklmnopqrstuvwxyzñáéíóúü"
+
+// Other es el bit que se enciende cuando una palabra contiene un carácter fuera de `Alphabet`, esas
+// palabras se deben comprobar carácter por carácter.
+const Other uint64 = 1 << 63
+
+var bits = func() map[rune]uint64 {
+	m := make(map[rune]uint64)
+	for i, r := range []rune(Alphabet) {
+		m[r] = 1 << i
+	}
+	return m
+}()
+
+// Mask calcula el conjunto de letras de una palabra como un entero de 64 bits.
+func Mask(word string) uint64 { // {{{
+	var mask uint64
+	for _, r := range strings.ToLower(word) {
+		if b, ok := bits[r]; ok {
+			mask |= b
+		} else {
+			mask |= Other
+		}
+	}
+
+	return mask
+} // }}}
@@ -0,0 +1,138 @@
+package words
+
+import (
+	"math/rand"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// bit devuelve el bit de la letra `r` en la máscara, ver `Alphabet`.
+func bit(r rune) uint64 { // {{{
+	return 1 << slices.Index([]rune(Alphabet), r)
+} // }}}
+
+func TestMask(t *testing.T) { // {{{
+	tests := []struct {
+		word string
+		want uint64
+	}{
+		{"", 0},
+		{"a", bit('a')},
+		{"Aa", bit('a')},
+		{"casa", bit('c') | bit('a') | bit('s')},
+		{"año", bit('a') | bit('ñ') | bit('o')},
+		{"AÑO", bit('a') | bit('ñ') | bit('o')},
+		{"canción", bit('c') | bit('a') | bit('n') | bit('i') | bit('ó')},
+		{"Ángel", bit('á') | bit('n') | bit('g') | bit('e') | bit('l')},
+		{"pingüino", bit('p') | bit('i') | bit('n') | bit('g') | bit('ü') | bit('o')},
+		{"ça", Other | bit('a')},
+		{"a-b", Other | bit('a') | bit('b')},
+		{"z9", Other | bit('z')},
+		{"à", Other},
+	}
+	for _, tt := range tests {
+		if got := Mask(tt.word); got != tt.want {
+			t.Errorf("Mask(%q) = %064b, se esperaba %064b", tt.word, got, tt.want)
+		}
+	}
+	if Other != 1<<63 {
+		t.Errorf("Other = %064b, debe ser el bit 63", Other)
+	}
+} // }}}
+
+func TestKeySetAllows(t *testing.T) { // {{{
+	tests := []struct {
+		keys string
+		word string
+		want bool
+	}{
+		{"abc", "cab", true},
+		{"abc", "", false},
+		{"abc", "abd", false},
+		{"abc", "Abc", false},
+		{"aáe", "áe", true},
+		{"ae", "áe", false},
+		{"ñao", "año", true},
+		{"nao", "año", false},
+		{"pingüo", "pingüino", true},
+		{"pinguo", "pingüino", false},
+		{"aç", "ça", true},
+		{"a", "ça", false},
+		{"a-b", "a-b", true},
+	}
+	for _, tt := range tests {
+		keys := NewKeySet([]rune(tt.keys)...)
+		if got := keys.Allows(tt.word); got != tt.want {
+			t.Errorf("NewKeySet(%q).Allows(%q) = %v, se esperaba %v", tt.keys, tt.word, got, tt.want)
+		}
+		// La máscara solo descarta palabras, nunca una que el conjunto permite
+		if tt.want && Mask(tt.word)&keys.Rejected() != 0 {
+			t.Errorf("la máscara de %q descarta la palabra con las teclas %q", tt.word, tt.keys)
+		}
+	}
+} // }}}
+
+// filterKeys son conjuntos de teclas como los de las filas de los layouts, con letras acentuadas y
+// caracteres fuera de `Alphabet`.
+var filterKeys = []string{
+	"asdfghjklñ",
+	"asdfghjklñqwertyuiop",
+	"qwertyuiopasdfghjklñzxcvbnm",
+	"aeiouáéíóúnrstlcdm",
+	"arstdhneio",
+	"abcdefghijklmnopqrstuvwxyzñáéíóúü'-",
+}
+
+// regexpWords es el filtro anterior a las máscaras: una expresión regular con las teclas evaluada en cada
+// palabra.
+func regexpWords(list []string, keys string) []string { // {{{
+	re := regexp.MustCompile("^[" + regexp.QuoteMeta(keys) + "]+$")
+	var found []string
+	for _, word := range list {
+		if re.MatchString(word) {
+			found = append(found, word)
+		}
+	}
+
+	return found
+} // }}}
+
+func TestWordsMatchesRegexp(t *testing.T) { // {{{
+	l := Embedded()
+	for _, lang := range []string{"spa", "eng"} {
+		all := l.All(lang)
+		for _, keys := range filterKeys {
+			want := regexpWords(all, keys)
+			got, err := l.Words(len(all), lang, NewKeySet([]rune(keys)...), rand.New(rand.NewSource(1)))
+			if err != nil && len(want) > 0 {
+				t.Fatalf("Words(%s, %q): %s", lang, keys, err)
+			}
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("Words(%s, %q) devolvió %d palabras, la expresión regular %d", lang, keys, len(got), len(want))
+			}
+		}
+	}
+} // }}}
+
+func BenchmarkWords(b *testing.B) { // {{{
+	l := Embedded()
+	all := append(l.All("spa"), l.All("eng")...)
+	keys := filterKeys[1]
+
+	b.Run("regexp", func(b *testing.B) {
+		for range b.N {
+			regexpWords(all, keys)
+		}
+	})
+	b.Run("mask", func(b *testing.B) {
+		set := NewKeySet([]rune(keys)...)
+		rng := rand.New(rand.NewSource(1))
+		for range b.N {
+			_, _ = l.Words(len(all), "spa", set, rng)
+			_, _ = l.Words(len(all), "eng", set, rng)
+		}
+	})
+} // }}}
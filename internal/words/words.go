@@ -0,0 +1,209 @@
+package words
+
+import (
+	"bufio"
+	"compress/gzip"
+	"embed"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wrodriguez/thot/internal/i18n"
+	"gitlab.com/tozd/go/errors"
+)
+
+//go:embed data/*.txt
+var fs embed.FS
+
+// Source es un origen de palabras para las sesiones de entrenamiento.
+type Source interface {
+	// Words devuelve hasta `limit` palabras al azar del idioma `lang` (`spa` o `eng`) formadas únicamente
+	// por caracteres de `keys`, si ninguna palabra cumple la condición devuelve el error de `NoWords`. Las
+	// palabras se eligen con `rng`, con la misma semilla y el mismo origen siempre se obtiene la misma lista.
+	Words(limit int, lang string, keys KeySet, rng *rand.Rand) ([]string, errors.E)
+}
+
+// List es un origen de palabras en memoria agrupadas por idioma, las palabras de `any` se usan para los
+// idiomas que no tienen una lista propia.
+type List struct {
+	langs map[string][]entry
+	any   []entry
+}
+
+// entry guarda una palabra junto con su máscara precalculada para filtrar sin recorrer sus caracteres.
+type entry struct {
+	word string
+	mask uint64
+}
+
+// NewList crea un origen de palabras a partir de listas ya cargadas en memoria.
+func NewList(langs map[string][]string) *List { // {{{
+	l := &List{langs: make(map[string][]entry)}
+	for lang, list := range langs {
+		l.langs[lang] = entries(list)
+	}
+
+	return l
+} // }}}
+
+// Embedded devuelve la lista de palabras incluida en el binario, no necesita ningún archivo externo.
+func Embedded() *List { // {{{
+	langs := make(map[string][]string)
+	for _, lang := range []string{"spa", "eng"} {
+		f, err := fs.Open("data/" + lang + ".txt")
+		if err != nil {
+			continue
+		}
+		langs[lang], _ = read(f)
+		f.Close()
+	}
+
+	return NewList(langs)
+} // }}}
+
+// NewFile crea un origen de palabras a partir de un archivo de texto con una palabra por línea, si el
+// nombre termina en `.gz` se descomprime. Las palabras se usan para cualquier idioma.
+func NewFile(path string) (*List, errors.E) { // {{{
+	list, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &List{langs: map[string][]entry{}, any: entries(list)}, nil
+} // }}}
+
+// NewDir crea un origen de palabras a partir de un directorio con un archivo por idioma: `spa.txt`,
+// `eng.txt` o sus versiones comprimidas `spa.txt.gz` y `eng.txt.gz`.
+func NewDir(dir string) (*List, errors.E) { // {{{
+	langs := make(map[string][]string)
+	for _, lang := range []string{"spa", "eng"} {
+		for _, name := range []string{lang + ".txt", lang + ".txt.gz"} {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			list, err := readFile(path)
+			if err != nil {
+				return nil, err
+			}
+			langs[lang] = append(langs[lang], list...)
+		}
+	}
+	if len(langs) == 0 {
+		return nil, errors.WithDetails(
+			errors.New(i18n.T("El directorio no contiene listas de palabras (`spa.txt` o `eng.txt`)")),
+			"path",
+			dir,
+		)
+	}
+
+	return NewList(langs), nil
+} // }}}
+
+func (l *List) Words(limit int, lang string, keys KeySet, rng *rand.Rand) ([]string, errors.E) { // {{{
+	candidates, ok := l.langs[lang]
+	if !ok {
+		candidates = l.any
+	}
+
+	rejected := keys.Rejected()
+	var words []string = make([]string, 0)
+	for _, e := range candidates {
+		// La máscara descarta la mayoría de las palabras sin recorrer sus caracteres
+		if e.mask&rejected == 0 && keys.Allows(e.word) {
+			words = append(words, e.word)
+		}
+	}
+	if len(words) == 0 {
+		return nil, NoWords(lang, keys)
+	}
+
+	return Pick(words, limit, rng), nil
+} // }}}
+
+// All devuelve todas las palabras del idioma `lang` en el orden de la lista, sirve para analizar un layout.
+func (l *List) All(lang string) []string { // {{{
+	candidates, ok := l.langs[lang]
+	if !ok {
+		candidates = l.any
+	}
+	list := make([]string, 0, len(candidates))
+	for _, e := range candidates {
+		list = append(list, e.word)
+	}
+
+	return list
+} // }}}
+
+// Pick desordena `words` con `rng` y devuelve las primeras `limit`, el resultado solo depende del orden de
+// `words` y del estado de `rng`.
+func Pick(words []string, limit int, rng *rand.Rand) []string { // {{{
+	rng.Shuffle(len(words), func(i, j int) {
+		words[i], words[j] = words[j], words[i]
+	})
+
+	return words[:min(limit, len(words))]
+} // }}}
+
+func entries(list []string) []entry { // {{{
+	out := make([]entry, 0, len(list))
+	for _, word := range list {
+		out = append(out, entry{word: word, mask: Mask(word)})
+	}
+
+	return out
+} // }}}
+
+func readFile(path string) ([]string, errors.E) { // {{{
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithDetails(
+			errors.WithMessage(err, i18n.T("No se pudo abrir la lista de palabras")),
+			"path",
+			path,
+		)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, errors.WithDetails(
+				errors.WithMessage(err, i18n.T("No se pudo descomprimir la lista de palabras")),
+				"path",
+				path,
+			)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	list, err := read(r)
+	if err != nil {
+		return nil, errors.WithDetails(
+			errors.WithMessage(err, i18n.T("No se pudo leer la lista de palabras")),
+			"path",
+			path,
+		)
+	}
+
+	return list, nil
+} // }}}
+
+// read lee una palabra por línea ignorando las líneas vacías y los comentarios que comienzan con `#`.
+func read(r io.Reader) ([]string, error) { // {{{
+	var list []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		list = append(list, word)
+	}
+
+	return list, scanner.Err()
+} // }}}